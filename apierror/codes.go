@@ -0,0 +1,337 @@
+package apierror
+
+// Code стабильный машиночитаемый код ошибки. Клиенты должны опираться на него,
+// а не на текст сообщения: коды не меняются, сообщения могут уточняться.
+type Code string
+
+// Общие ошибки
+const (
+	CodeInternal           Code = "internal_error"
+	CodeValidationFailed   Code = "validation_failed"
+	CodeInvalidJSON        Code = "invalid_json"
+	CodeInvalidQueryParam  Code = "invalid_query_param"
+	CodeRouteNotFound      Code = "route_not_found"
+	CodeRateLimited        Code = "rate_limited"
+	CodeForbidden          Code = "forbidden"
+	CodePermissionRequired Code = "permission_required"
+)
+
+// Аутентификация и сессии
+const (
+	CodeUnauthenticated            Code = "unauthenticated"
+	CodeMissingCredentials         Code = "missing_credentials"
+	CodeInvalidAuthorizationHeader Code = "invalid_authorization_header"
+	CodeInvalidToken               Code = "invalid_token"
+	CodeSessionRevoked             Code = "session_revoked"
+	CodeInvalidCredentials         Code = "invalid_credentials"
+	CodeAccountDisabled            Code = "account_disabled"
+	CodeAccountLocked              Code = "account_locked"
+	CodeLoginThrottled             Code = "login_throttled"
+	CodePasswordChangeRequired     Code = "password_change_required"
+	CodeInvalidPassword            Code = "invalid_password"
+	CodeInvalidCurrentPassword     Code = "invalid_current_password"
+	CodePasswordUnchanged          Code = "password_unchanged"
+	CodeInvalidLink                Code = "invalid_link"
+	CodeEmailTaken                 Code = "email_taken"
+	CodeUsernameTaken              Code = "username_taken"
+	CodeEmailAlreadyVerified       Code = "email_already_verified"
+)
+
+// Двухфакторная аутентификация
+const (
+	CodeInvalidTwoFactorCode       Code = "invalid_2fa_code"
+	CodeTwoFactorAlreadyEnabled    Code = "2fa_already_enabled"
+	CodeTwoFactorNotEnabled        Code = "2fa_not_enabled"
+	CodeTwoFactorSetupRequired     Code = "2fa_setup_required"
+	CodeTwoFactorChallengeExpired  Code = "2fa_challenge_expired"
+	CodeTwoFactorRequiredForAdmins Code = "2fa_required_for_admin"
+)
+
+// API ключи
+const (
+	CodeInvalidAPIKey           Code = "invalid_api_key"
+	CodeAPIKeyOwnerDisabled     Code = "api_key_owner_disabled"
+	CodeAPIKeyNotAllowed        Code = "api_key_not_allowed"
+	CodeAPIKeyNotFound          Code = "api_key_not_found"
+	CodeAPIKeyOwnerNotFound     Code = "api_key_owner_not_found"
+	CodeAPIKeyPermissionNotHeld Code = "api_key_permission_not_held"
+	CodeInvalidAPIKeyID         Code = "invalid_api_key_id"
+)
+
+// Вход через OpenID Connect
+const (
+	CodeOIDCProviderNotFound    Code = "oidc_provider_not_found"
+	CodeOIDCProviderUnavailable Code = "oidc_provider_unavailable"
+	CodeOIDCProviderDenied      Code = "oidc_provider_denied"
+	CodeOIDCMissingCode         Code = "oidc_missing_code"
+	CodeOIDCInvalidState        Code = "oidc_invalid_state"
+	CodeOIDCExchangeFailed      Code = "oidc_exchange_failed"
+	CodeOIDCEmailNotVerified    Code = "oidc_email_not_verified"
+	CodeOIDCEmailTaken          Code = "oidc_email_taken"
+)
+
+// Пользователи, роли и разрешения
+const (
+	CodeInvalidUserID         Code = "invalid_user_id"
+	CodeUserNotFound          Code = "user_not_found"
+	CodeCannotDeactivateSelf  Code = "cannot_deactivate_self"
+	CodeCannotChangeOwnRole   Code = "cannot_change_own_role"
+	CodeCannotManageSelf      Code = "cannot_manage_self"
+	CodeUserPermissionNotHeld Code = "user_permission_not_held"
+	CodeRoleNotFound          Code = "role_not_found"
+	CodeRoleExists            Code = "role_exists"
+	CodeRoleInUse             Code = "role_in_use"
+	CodeSystemRoleProtected   Code = "system_role_protected"
+	CodeAdminRoleProtected    Code = "admin_role_protected"
+	CodeUnknownPermission     Code = "unknown_permission"
+	CodeRolePermissionNotHeld Code = "role_permission_not_held"
+	CodePermissionNotHeld     Code = "permission_not_held"
+	CodeCannotEditOwnRole     Code = "cannot_edit_own_role"
+)
+
+// Продукты, корзина и заказы
+const (
+	CodeInvalidProductID       Code = "invalid_product_id"
+	CodeProductNotFound        Code = "product_not_found"
+	CodeProductUnavailable     Code = "product_unavailable"
+	CodeProductNotDeleted      Code = "product_not_deleted"
+	CodeInsufficientStock      Code = "insufficient_stock"
+	CodeInvalidCartItemID      Code = "invalid_cart_item_id"
+	CodeCartItemNotFound       Code = "cart_item_not_found"
+	CodeInvalidOrderID         Code = "invalid_order_id"
+	CodeOrderNotFound          Code = "order_not_found"
+	CodeOrderNotCancellable    Code = "order_not_cancellable"
+	CodeOrderProductNotFound   Code = "order_product_not_found"
+	CodeOrderInsufficientStock Code = "order_insufficient_stock"
+	CodeOrderVariantRequired   Code = "order_variant_required"
+	CodeOrderVariantNotFound   Code = "order_variant_not_found"
+)
+
+// Варианты продуктов
+const (
+	CodeInvalidVariantID   Code = "invalid_variant_id"
+	CodeVariantNotFound    Code = "variant_not_found"
+	CodeVariantRequired    Code = "variant_required"
+	CodeVariantUnavailable Code = "variant_unavailable"
+	CodeVariantConflict    Code = "variant_conflict"
+)
+
+// Характеристики продуктов
+const (
+	CodeInvalidAttributeID     Code = "invalid_attribute_id"
+	CodeAttributeNotFound      Code = "attribute_not_found"
+	CodeAttributeConflict      Code = "attribute_conflict"
+	CodeInvalidAttributeCode   Code = "invalid_attribute_code"
+	CodeUnknownAttribute       Code = "unknown_attribute"
+	CodeInvalidAttributeValue  Code = "invalid_attribute_value"
+	CodeAttributeNotInCategory Code = "attribute_not_in_category"
+	CodeInvalidCategoryID      Code = "invalid_category_id"
+	CodeCategoryNotFound       Code = "category_not_found"
+)
+
+// Изображения продуктов
+const (
+	CodeInvalidImageID      Code = "invalid_image_id"
+	CodeImageNotFound       Code = "image_not_found"
+	CodeImageRequired       Code = "image_required"
+	CodeImageTooLarge       Code = "image_too_large"
+	CodeImageTypeNotAllowed Code = "image_type_not_allowed"
+	CodeInvalidImage        Code = "invalid_image"
+	CodeImageTooManyPixels  Code = "image_too_many_pixels"
+	CodeInvalidImageOrder   Code = "invalid_image_order"
+)
+
+// История продуктов
+const (
+	CodeRevisionNotFound        Code = "revision_not_found"
+	CodeRevisionSKUTaken        Code = "revision_sku_taken"
+	CodeRevisionCategoryMissing Code = "revision_category_missing"
+	CodeRevisionCurrencyMissing Code = "revision_currency_missing"
+)
+
+// Запланированные цены и акции
+const (
+	CodeInvalidSalePriceID Code = "invalid_sale_price_id"
+	CodeSalePriceNotFound  Code = "sale_price_not_found"
+	CodeSalePriceTooHigh   Code = "sale_price_too_high"
+	CodeInvalidSalePeriod  Code = "invalid_sale_period"
+	CodeInvalidCampaignID  Code = "invalid_campaign_id"
+	CodeCampaignNotFound   Code = "campaign_not_found"
+)
+
+// Поиск
+const (
+	CodeSearchQueryNotBlocked Code = "search_query_not_blocked"
+)
+
+// Валюты
+const (
+	CodeUnknownCurrency      Code = "unknown_currency"
+	CodeInvalidCurrency      Code = "invalid_currency"
+	CodeBaseCurrencyRate     Code = "base_currency_rate"
+	CodeCurrencyInUse        Code = "currency_in_use"
+	CodeExchangeRateNotFound Code = "exchange_rate_not_found"
+)
+
+// Импорт и выгрузка продуктов
+const (
+	CodeInvalidFileFormat     Code = "invalid_file_format"
+	CodeImportFileRequired    Code = "import_file_required"
+	CodeImportFileTooLarge    Code = "import_file_too_large"
+	CodeImportTooManyRows     Code = "import_too_many_rows"
+	CodeImportEmpty           Code = "import_empty"
+	CodeImportUnknownColumn   Code = "import_unknown_column"
+	CodeImportMissingColumn   Code = "import_missing_column"
+	CodeImportDuplicateColumn Code = "import_duplicate_column"
+	CodeImportInvalidHeader   Code = "import_invalid_header"
+	CodeInvalidImportJobID    Code = "invalid_import_job_id"
+	CodeImportJobNotFound     Code = "import_job_not_found"
+)
+
+// localized сообщение на поддерживаемых языках
+type localized struct {
+	ru string
+	en string
+}
+
+// messages сообщения кодов; %-параметры заполняются аргументами Respond
+var messages = map[Code]localized{
+	CodeInternal:           {"Внутренняя ошибка сервера. Повторите попытку позже", "Internal server error. Please try again later"},
+	CodeValidationFailed:   {"Данные запроса не прошли проверку", "Request data failed validation"},
+	CodeInvalidJSON:        {"Тело запроса должно быть корректным JSON", "Request body must be valid JSON"},
+	CodeInvalidQueryParam:  {"Неверное значение параметра %s", "Invalid value of query parameter %s"},
+	CodeRouteNotFound:      {"Маршрут не найден", "Route not found"},
+	CodeRateLimited:        {"Слишком много запросов. Повторите позже", "Too many requests. Please try again later"},
+	CodeForbidden:          {"Доступ запрещен", "Access denied"},
+	CodePermissionRequired: {"Доступ запрещен. Требуется разрешение %s", "Access denied. Permission %s is required"},
+
+	CodeUnauthenticated:            {"Пользователь не аутентифицирован", "Authentication required"},
+	CodeMissingCredentials:         {"Заголовок Authorization или X-API-Key отсутствует", "Authorization or X-API-Key header is missing"},
+	CodeInvalidAuthorizationHeader: {"Неверный формат токена. Используйте: Bearer <token>", "Invalid token format. Use: Bearer <token>"},
+	CodeInvalidToken:               {"Недействительный или просроченный токен", "Invalid or expired token"},
+	CodeSessionRevoked:             {"Сессия отозвана. Войдите заново", "Session has been revoked. Please sign in again"},
+	CodeInvalidCredentials:         {"Неверный email или пароль", "Invalid email or password"},
+	CodeAccountDisabled:            {"Учетная запись отключена", "Account is disabled"},
+	CodeAccountLocked:              {"Учетная запись временно заблокирована из-за неудачных попыток входа. Повторите через %d мин.", "Account is temporarily locked due to failed sign-in attempts. Try again in %d min."},
+	CodeLoginThrottled:             {"Слишком много неудачных попыток входа. Повторите через %d сек.", "Too many failed sign-in attempts. Try again in %d sec."},
+	CodePasswordChangeRequired:     {"Необходимо сменить пароль: POST /api/v1/me/change-password", "Password change required: POST /api/v1/me/change-password"},
+	CodeInvalidPassword:            {"Неверный пароль", "Invalid password"},
+	CodeInvalidCurrentPassword:     {"Неверный текущий пароль", "Current password is incorrect"},
+	CodePasswordUnchanged:          {"Новый пароль должен отличаться от текущего", "New password must differ from the current one"},
+	CodeInvalidLink:                {"Ссылка недействительна или устарела", "Link is invalid or has expired"},
+	CodeEmailTaken:                 {"Пользователь с таким email уже существует", "A user with this email already exists"},
+	CodeUsernameTaken:              {"Имя пользователя уже занято", "This username is already taken"},
+	CodeEmailAlreadyVerified:       {"Email уже подтвержден", "Email is already verified"},
+
+	CodeInvalidTwoFactorCode:       {"Неверный код", "Invalid code"},
+	CodeTwoFactorAlreadyEnabled:    {"Двухфакторная аутентификация уже включена", "Two-factor authentication is already enabled"},
+	CodeTwoFactorNotEnabled:        {"Неверный код или 2FA не включена", "Invalid code or two-factor authentication is not enabled"},
+	CodeTwoFactorSetupRequired:     {"Сначала выполните настройку: POST /api/v1/me/2fa/setup", "Complete setup first: POST /api/v1/me/2fa/setup"},
+	CodeTwoFactorChallengeExpired:  {"Время на ввод кода истекло. Войдите заново", "Code entry time has expired. Please sign in again"},
+	CodeTwoFactorRequiredForAdmins: {"Для пользователей с административными разрешениями требуется двухфакторная аутентификация: POST /api/v1/me/2fa/setup", "Users with administrative permissions must enable two-factor authentication: POST /api/v1/me/2fa/setup"},
+
+	CodeInvalidAPIKey:           {"Недействительный API ключ", "Invalid API key"},
+	CodeAPIKeyOwnerDisabled:     {"Учетная запись владельца ключа отключена", "API key owner account is disabled"},
+	CodeAPIKeyNotAllowed:        {"Операция недоступна по API ключу", "This operation is not available with an API key"},
+	CodeAPIKeyNotFound:          {"API ключ не найден или уже отозван", "API key not found or already revoked"},
+	CodeAPIKeyOwnerNotFound:     {"Владелец ключа не найден", "API key owner not found"},
+	CodeAPIKeyPermissionNotHeld: {"Нельзя выдать разрешение, которого нет у вас: %s", "You cannot grant a permission you do not hold: %s"},
+	CodeInvalidAPIKeyID:         {"Неверный ID ключа", "Invalid API key ID"},
+
+	CodeOIDCProviderNotFound:    {"Провайдер не найден", "Provider not found"},
+	CodeOIDCProviderUnavailable: {"Провайдер недоступен", "Provider is unavailable"},
+	CodeOIDCProviderDenied:      {"Провайдер отклонил вход: %s", "Provider denied sign-in: %s"},
+	CodeOIDCMissingCode:         {"Не указаны code и state", "code and state are required"},
+	CodeOIDCInvalidState:        {"Попытка входа не найдена или истекла. Начните вход заново", "Sign-in attempt not found or expired. Please start again"},
+	CodeOIDCExchangeFailed:      {"Не удалось подтвердить вход у провайдера", "Could not confirm sign-in with the provider"},
+	CodeOIDCEmailNotVerified:    {"Провайдер не подтвердил email. Войдите по паролю", "Provider did not verify the email. Sign in with your password"},
+	CodeOIDCEmailTaken:          {"Пользователь с таким email уже существует. Подтвердите email или войдите по паролю", "A user with this email already exists. Verify the email or sign in with your password"},
+
+	CodeInvalidUserID:         {"Неверный ID пользователя", "Invalid user ID"},
+	CodeUserNotFound:          {"Пользователь не найден", "User not found"},
+	CodeCannotDeactivateSelf:  {"Нельзя отключить собственную учетную запись", "You cannot deactivate your own account"},
+	CodeCannotChangeOwnRole:   {"Нельзя изменить собственную роль", "You cannot change your own role"},
+	CodeCannotManageSelf:      {"Это действие нельзя выполнить со своей учетной записью", "You cannot perform this action on your own account"},
+	CodeUserPermissionNotHeld: {"У пользователя есть разрешение, которого нет у вас: %s", "The user holds a permission you do not hold: %s"},
+	CodeRoleNotFound:          {"Роль не найдена", "Role not found"},
+	CodeRoleExists:            {"Роль с таким названием уже существует", "A role with this name already exists"},
+	CodeRoleInUse:             {"Роль назначена пользователям: %d", "Role is assigned to users: %d"},
+	CodeSystemRoleProtected:   {"Системную роль удалить нельзя", "System roles cannot be deleted"},
+	CodeAdminRoleProtected:    {"Разрешения роли admin изменить нельзя", "Permissions of the admin role cannot be changed"},
+	CodeUnknownPermission:     {"Указано неизвестное разрешение. Список: GET /api/v1/admin/permissions", "Unknown permission. See GET /api/v1/admin/permissions"},
+	CodeRolePermissionNotHeld: {"Нельзя назначить роль с разрешением, которого нет у вас: %s", "You cannot assign a role with a permission you do not hold: %s"},
+	CodePermissionNotHeld:     {"Нельзя выдать разрешение, которого нет у вас: %s", "You cannot grant a permission you do not hold: %s"},
+	CodeCannotEditOwnRole:     {"Нельзя изменить разрешения собственной роли", "You cannot edit your own role"},
+
+	CodeInvalidProductID:       {"Неверный ID продукта", "Invalid product ID"},
+	CodeProductNotFound:        {"Продукт не найден", "Product not found"},
+	CodeProductUnavailable:     {"Продукт не найден или неактивен", "Product not found or inactive"},
+	CodeProductNotDeleted:      {"Продукт не находится в корзине удаленных", "Product is not in the trash"},
+	CodeInsufficientStock:      {"Недостаточно товара на складе", "Insufficient stock"},
+	CodeInvalidCartItemID:      {"Неверный ID товара", "Invalid cart item ID"},
+	CodeCartItemNotFound:       {"Товар в корзине не найден", "Cart item not found"},
+	CodeInvalidOrderID:         {"Неверный ID заказа", "Invalid order ID"},
+	CodeOrderNotFound:          {"Заказ не найден", "Order not found"},
+	CodeOrderNotCancellable:    {"Заказ нельзя отменить в текущем статусе", "Order cannot be cancelled in its current status"},
+	CodeOrderProductNotFound:   {"Продукт с ID %d не найден", "Product with ID %d not found"},
+	CodeOrderInsufficientStock: {"Недостаточно товара для продукта с ID %d", "Insufficient stock for product with ID %d"},
+	CodeOrderVariantRequired:   {"Для продукта с ID %d укажите variant_id", "Specify variant_id for product with ID %d"},
+	CodeOrderVariantNotFound:   {"Вариант с ID %d не найден или неактивен", "Variant with ID %d not found or inactive"},
+
+	CodeInvalidVariantID:   {"Неверный ID варианта", "Invalid variant ID"},
+	CodeVariantNotFound:    {"Вариант не найден", "Variant not found"},
+	CodeVariantRequired:    {"У продукта есть варианты: укажите variant_id", "The product has variants: specify variant_id"},
+	CodeVariantUnavailable: {"Вариант не найден или неактивен", "Variant not found or inactive"},
+	CodeVariantConflict:    {"Вариант с таким SKU или набором атрибутов уже существует", "A variant with this SKU or attribute set already exists"},
+
+	CodeInvalidAttributeID:     {"Неверный ID характеристики", "Invalid attribute ID"},
+	CodeAttributeNotFound:      {"Характеристика не найдена", "Attribute not found"},
+	CodeAttributeConflict:      {"Характеристика с таким кодом уже существует", "An attribute with this code already exists"},
+	CodeInvalidAttributeCode:   {"Код характеристики может содержать только строчные латинские буквы, цифры и _ и должен начинаться с буквы", "Attribute code may contain only lowercase Latin letters, digits and _ and must start with a letter"},
+	CodeUnknownAttribute:       {"Неизвестная характеристика: %s", "Unknown attribute: %s"},
+	CodeInvalidAttributeValue:  {"Неверное значение характеристики %s: ожидается %s", "Invalid value of attribute %s: %s expected"},
+	CodeAttributeNotInCategory: {"Характеристика %s не относится к категории продукта", "Attribute %s does not belong to the product category"},
+	CodeInvalidCategoryID:      {"Неверный ID категории", "Invalid category ID"},
+	CodeCategoryNotFound:       {"Категория не найдена", "Category not found"},
+
+	CodeInvalidImageID:      {"Неверный ID изображения", "Invalid image ID"},
+	CodeImageNotFound:       {"Изображение не найдено", "Image not found"},
+	CodeImageRequired:       {"Передайте изображение в поле file формы multipart/form-data", "Send the image in the file field of a multipart/form-data form"},
+	CodeImageTooLarge:       {"Размер изображения не должен превышать %d МБ", "Image size must not exceed %d MB"},
+	CodeImageTypeNotAllowed: {"Поддерживаются изображения JPEG, PNG и GIF", "Only JPEG, PNG and GIF images are supported"},
+	CodeInvalidImage:        {"Файл поврежден или не является изображением", "The file is corrupted or is not an image"},
+	CodeImageTooManyPixels:  {"Разрешение изображения не должно превышать %d мегапикселей", "Image resolution must not exceed %d megapixels"},
+	CodeInvalidImageOrder:   {"Передайте ID всех изображений продукта ровно по одному разу", "Pass the IDs of all product images exactly once"},
+
+	CodeRevisionNotFound:        {"Ревизия продукта не найдена", "Product revision not found"},
+	CodeRevisionSKUTaken:        {"Ревизию нельзя восстановить: ее SKU занят другим продуктом", "The revision cannot be restored: its SKU is used by another product"},
+	CodeRevisionCategoryMissing: {"Ревизию нельзя восстановить: ее категория удалена", "The revision cannot be restored: its category has been deleted"},
+	CodeRevisionCurrencyMissing: {"Ревизию нельзя восстановить: курс ее валюты удален", "The revision cannot be restored: its currency has been removed"},
+
+	CodeInvalidSalePriceID: {"Неверный ID запланированной цены", "Invalid scheduled price ID"},
+	CodeSalePriceNotFound:  {"Запланированная цена не найдена", "Scheduled price not found"},
+	CodeSalePriceTooHigh:   {"Цена по акции должна быть ниже цены продукта", "Sale price must be lower than the product price"},
+	CodeInvalidSalePeriod:  {"Окончание периода должно быть позже начала", "The period must end after it starts"},
+	CodeInvalidCampaignID:  {"Неверный ID акции", "Invalid campaign ID"},
+	CodeCampaignNotFound:   {"Акция не найдена", "Campaign not found"},
+
+	CodeUnknownCurrency:      {"Неизвестная валюта %s: для нее не задан курс", "Unknown currency %s: no exchange rate is set"},
+	CodeInvalidCurrency:      {"Неверный код валюты %s: ожидается код ISO 4217, например USD", "Invalid currency code %s: an ISO 4217 code such as USD is expected"},
+	CodeBaseCurrencyRate:     {"Курс базовой валюты %s всегда равен 1", "The exchange rate of the base currency %s is always 1"},
+	CodeCurrencyInUse:        {"Валюта используется в ценах продуктов", "The currency is used in product prices"},
+	CodeExchangeRateNotFound: {"Курс валюты не найден", "Exchange rate not found"},
+
+	CodeSearchQueryNotBlocked: {"Запрос не заблокирован", "Search query is not blocked"},
+
+	CodeInvalidFileFormat:     {"Укажите формат файла: csv или jsonl", "Specify the file format: csv or jsonl"},
+	CodeImportFileRequired:    {"Передайте файл в поле file формы multipart/form-data или в теле запроса", "Send the file in the file field of a multipart/form-data form or as the request body"},
+	CodeImportFileTooLarge:    {"Размер файла импорта не должен превышать %d МБ", "Import file size must not exceed %d MB"},
+	CodeImportTooManyRows:     {"Файл импорта не должен содержать больше %d строк", "Import file must not contain more than %d rows"},
+	CodeImportEmpty:           {"Файл импорта не содержит ни одного продукта", "The import file contains no products"},
+	CodeImportUnknownColumn:   {"Неизвестная колонка: %s", "Unknown column: %s"},
+	CodeImportMissingColumn:   {"В файле нет обязательной колонки %s", "The file has no required column %s"},
+	CodeImportDuplicateColumn: {"Колонка %s указана дважды", "Column %s is specified twice"},
+	CodeImportInvalidHeader:   {"Не удалось разобрать первую строку CSV с названиями колонок", "Could not parse the first CSV row with column names"},
+	CodeInvalidImportJobID:    {"Неверный ID задачи импорта", "Invalid import job ID"},
+	CodeImportJobNotFound:     {"Задача импорта не найдена", "Import job not found"},
+}
@@ -0,0 +1,36 @@
+package apierror
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// Поддерживаемые языки сообщений
+const (
+	LangRussian = "ru"
+	LangEnglish = "en"
+)
+
+// langContextKey ключ контекста, в котором запоминается выбранный язык
+const langContextKey = "lang"
+
+// matcher выбирает язык из Accept-Language; первый язык используется по умолчанию
+var matcher = language.NewMatcher([]language.Tag{language.Russian, language.English})
+
+// Language возвращает язык сообщений для запроса по заголовку Accept-Language
+func Language(c *gin.Context) string {
+	if lang := c.GetString(langContextKey); lang != "" {
+		return lang
+	}
+
+	lang := LangRussian
+	tags, _, err := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if err == nil && len(tags) > 0 {
+		if _, index, confidence := matcher.Match(tags...); confidence != language.No && index == 1 {
+			lang = LangEnglish
+		}
+	}
+
+	c.Set(langContextKey, lang)
+	return lang
+}
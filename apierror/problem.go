@@ -0,0 +1,113 @@
+// Package apierror формирует ответы об ошибках в формате RFC 7807 (application/problem+json)
+// со стабильными кодами и сообщениями на языке из Accept-Language (ru, en)
+package apierror
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType тип содержимого ответа об ошибке
+const ContentType = "application/problem+json"
+
+// Problem описание ошибки по RFC 7807
+type Problem struct {
+	Type     string       `json:"type" example:"/problems/user_not_found"`
+	Title    string       `json:"title" example:"Не найдено"`
+	Status   int          `json:"status" example:"404"`
+	Detail   string       `json:"detail" example:"Пользователь не найден"`
+	Instance string       `json:"instance,omitempty" example:"/api/v1/admin/users/42"`
+	Code     Code         `json:"code" example:"user_not_found"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError ошибка проверки поля запроса
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"Обязательное поле"`
+}
+
+// Respond отправляет ошибку с кодом code и прерывает обработку запроса.
+// args подставляются в сообщение кода.
+func Respond(c *gin.Context, status int, code Code, args ...interface{}) {
+	write(c, newProblem(c, status, code, args...))
+}
+
+// Internal записывает err в лог и отправляет общую ошибку 500:
+// текст внутренних ошибок клиенту не передается
+func Internal(c *gin.Context, err error) {
+	log.Printf("Внутренняя ошибка %s %s (request_id %s): %v", c.Request.Method, c.Request.URL.Path, c.GetString("request_id"), err)
+	Respond(c, http.StatusInternalServerError, CodeInternal)
+}
+
+// newProblem создает описание ошибки на языке запроса
+func newProblem(c *gin.Context, status int, code Code, args ...interface{}) *Problem {
+	lang := Language(c)
+	return &Problem{
+		Type:     "/problems/" + string(code),
+		Title:    statusTitle(lang, status),
+		Status:   status,
+		Detail:   message(lang, code, args...),
+		Instance: c.Request.URL.Path,
+		Code:     code,
+	}
+}
+
+// write отправляет описание ошибки и прерывает обработку запроса
+func write(c *gin.Context, p *Problem) {
+	c.Header("Content-Type", ContentType)
+	c.Header("Content-Language", Language(c))
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// message возвращает сообщение кода на языке lang
+func message(lang string, code Code, args ...interface{}) string {
+	m, ok := messages[code]
+	if !ok {
+		m = messages[CodeInternal]
+	}
+	return localize(lang, m, args...)
+}
+
+// localize выбирает вариант сообщения на языке lang и подставляет args
+func localize(lang string, m localized, args ...interface{}) string {
+	format := m.ru
+	if lang == LangEnglish {
+		format = m.en
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// statusTitle возвращает краткое название HTTP статуса на языке lang
+func statusTitle(lang string, status int) string {
+	if lang == LangRussian {
+		if title, ok := statusTitlesRu[status]; ok {
+			return title
+		}
+	}
+	return http.StatusText(status)
+}
+
+// statusTitlesRu названия HTTP статусов на русском
+var statusTitlesRu = map[int]string{
+	http.StatusBadRequest:            "Неверный запрос",
+	http.StatusUnauthorized:          "Требуется аутентификация",
+	http.StatusForbidden:             "Доступ запрещен",
+	http.StatusNotFound:              "Не найдено",
+	http.StatusConflict:              "Конфликт",
+	http.StatusRequestEntityTooLarge: "Слишком большой запрос",
+	http.StatusUnsupportedMediaType:  "Неподдерживаемый тип данных",
+	http.StatusUnprocessableEntity:   "Ошибка проверки данных",
+	http.StatusLocked:                "Заблокировано",
+	http.StatusTooManyRequests:       "Слишком много запросов",
+	http.StatusInternalServerError:   "Внутренняя ошибка сервера",
+	http.StatusBadGateway:            "Ошибка внешнего сервиса",
+	http.StatusServiceUnavailable:    "Сервис недоступен",
+}
@@ -0,0 +1,204 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterTagNames настраивает валидатор gin так, чтобы в ошибках поля назывались
+// как в JSON (или form для параметров запроса), а не как в Go структуре
+func RegisterTagNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
+
+// RespondValidation отправляет ошибку привязки запроса (ShouldBindJSON, ShouldBindQuery):
+// ошибки валидатора - с перечнем полей, синтаксические ошибки JSON - без текста парсера
+func RespondValidation(c *gin.Context, err error) {
+	if fieldErrors := FieldErrors(Language(c), err); fieldErrors != nil {
+		p := newProblem(c, http.StatusBadRequest, CodeValidationFailed)
+		p.Errors = fieldErrors
+		write(c, p)
+		return
+	}
+
+	Respond(c, http.StatusBadRequest, CodeInvalidJSON)
+}
+
+// FieldErrors возвращает ошибки полей из ошибки привязки или валидации на языке lang,
+// например для отчета об импорте. Возвращает nil, если ошибка не относится к полям.
+func FieldErrors(lang string, err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fieldErrors := make([]FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			fieldErrors = append(fieldErrors, FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Message: fieldMessage(lang, fe),
+			})
+		}
+		return fieldErrors
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		return []FieldError{NewFieldError(lang, typeError.Field, "type", jsonType(typeError.Type.Kind()))}
+	}
+
+	return nil
+}
+
+// NewFieldError создает ошибку поля, найденную вне валидатора (например, при разборе файла).
+// rule: required, type (param - ожидаемый тип), unknown, not_found, deleted, duplicate (param - где встречалось),
+// syntax (param - формат файла); остальные - invalid.
+func NewFieldError(lang, field, rule, param string) FieldError {
+	var message string
+	switch rule {
+	case "required":
+		message = localize(lang, fieldMessages[codeFieldRequired])
+	case "type":
+		message = localize(lang, fieldMessages[codeFieldType], param)
+	case "unknown":
+		message = localize(lang, fieldMessages[codeFieldUnknown])
+	case "not_found":
+		message = localize(lang, fieldMessages[codeFieldNotFound])
+	case "duplicate":
+		message = localize(lang, fieldMessages[codeFieldDuplicate], param)
+	case "deleted":
+		message = localize(lang, fieldMessages[codeFieldDeleted])
+	case "syntax":
+		message = localize(lang, fieldMessages[codeFieldSyntax], param)
+	default:
+		message = localize(lang, fieldMessages[codeFieldInvalid])
+	}
+	return FieldError{Field: field, Rule: rule, Message: message}
+}
+
+// jsonType название типа JSON, соответствующего типу Go
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
+
+// fieldPath путь к полю без имени корневой структуры: items[0].quantity
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+// Ключи сообщений правил валидации. Это не коды ответа: они попадают только в errors[].message.
+const (
+	codeFieldRequired  Code = "field.required"
+	codeFieldEmail     Code = "field.email"
+	codeFieldOneOf     Code = "field.oneof"
+	codeFieldMinString Code = "field.min.string"
+	codeFieldMaxString Code = "field.max.string"
+	codeFieldMinItems  Code = "field.min.items"
+	codeFieldMaxItems  Code = "field.max.items"
+	codeFieldMin       Code = "field.min"
+	codeFieldMax       Code = "field.max"
+	codeFieldGt        Code = "field.gt"
+	codeFieldLt        Code = "field.lt"
+	codeFieldType      Code = "field.type"
+	codeFieldInvalid   Code = "field.invalid"
+	codeFieldUnknown   Code = "field.unknown"
+	codeFieldNotFound  Code = "field.not_found"
+	codeFieldDuplicate Code = "field.duplicate"
+	codeFieldSyntax    Code = "field.syntax"
+	codeFieldDeleted   Code = "field.deleted"
+)
+
+// fieldMessages сообщения правил валидации
+var fieldMessages = map[Code]localized{
+	codeFieldRequired:  {"Обязательное поле", "This field is required"},
+	codeFieldEmail:     {"Некорректный email", "Must be a valid email address"},
+	codeFieldOneOf:     {"Допустимые значения: %s", "Allowed values: %s"},
+	codeFieldMinString: {"Минимальная длина: %s", "Must be at least %s characters long"},
+	codeFieldMaxString: {"Максимальная длина: %s", "Must be at most %s characters long"},
+	codeFieldMinItems:  {"Минимум элементов: %s", "Must contain at least %s items"},
+	codeFieldMaxItems:  {"Максимум элементов: %s", "Must contain at most %s items"},
+	codeFieldMin:       {"Значение не меньше %s", "Must be at least %s"},
+	codeFieldMax:       {"Значение не больше %s", "Must be at most %s"},
+	codeFieldGt:        {"Значение больше %s", "Must be greater than %s"},
+	codeFieldLt:        {"Значение меньше %s", "Must be less than %s"},
+	codeFieldType:      {"Ожидается значение типа %s", "Expected a value of type %s"},
+	codeFieldInvalid:   {"Недопустимое значение", "Invalid value"},
+	codeFieldUnknown:   {"Неизвестное поле", "Unknown field"},
+	codeFieldNotFound:  {"Объект с таким значением не найден", "No object with this value exists"},
+	codeFieldDuplicate: {"Значение уже встречалось в строке %s", "The value already occurred in row %s"},
+	codeFieldSyntax:    {"Строку не удалось разобрать как %s", "The row could not be parsed as %s"},
+	codeFieldDeleted:   {"Объект с таким значением удален", "The object with this value has been deleted"},
+}
+
+// fieldMessage сообщение о нарушенном правиле валидации поля
+func fieldMessage(lang string, fe validator.FieldError) string {
+	param := fe.Param()
+	sized := fe.Kind() == reflect.String || fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map
+
+	switch fe.Tag() {
+	case "required":
+		return localize(lang, fieldMessages[codeFieldRequired])
+	case "email":
+		return localize(lang, fieldMessages[codeFieldEmail])
+	case "oneof":
+		return localize(lang, fieldMessages[codeFieldOneOf], strings.ReplaceAll(param, " ", ", "))
+	case "min", "gte":
+		switch {
+		case fe.Kind() == reflect.String:
+			return localize(lang, fieldMessages[codeFieldMinString], param)
+		case sized:
+			return localize(lang, fieldMessages[codeFieldMinItems], param)
+		}
+		return localize(lang, fieldMessages[codeFieldMin], param)
+	case "max", "lte":
+		switch {
+		case fe.Kind() == reflect.String:
+			return localize(lang, fieldMessages[codeFieldMaxString], param)
+		case sized:
+			return localize(lang, fieldMessages[codeFieldMaxItems], param)
+		}
+		return localize(lang, fieldMessages[codeFieldMax], param)
+	case "gt":
+		return localize(lang, fieldMessages[codeFieldGt], param)
+	case "lt":
+		return localize(lang, fieldMessages[codeFieldLt], param)
+	}
+	return localize(lang, fieldMessages[codeFieldInvalid])
+}
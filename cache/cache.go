@@ -116,12 +116,13 @@ func (c *ProductCache) InvalidateProductCache(ctx context.Context, productID int
 	return nil
 }
 
-// InvalidateAllProductCache инвалидирует весь кэш продуктов
+// InvalidateAllProductCache инвалидирует весь кэш продуктов: списки и отдельные продукты
 func (c *ProductCache) InvalidateAllProductCache(ctx context.Context) error {
-	err := c.redis.DeletePattern(ctx, "products:*")
-	if err != nil {
-		log.Printf("Ошибка инвалидации всего кэша продуктов: %v", err)
-		return err
+	for _, pattern := range []string{"products:*", "product:*"} {
+		if err := c.redis.DeletePattern(ctx, pattern); err != nil {
+			log.Printf("Ошибка инвалидации всего кэша продуктов: %v", err)
+			return err
+		}
 	}
 
 	log.Println("Весь кэш продуктов инвалидирован")
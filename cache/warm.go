@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"api-go/models"
+)
+
+// WarmProducts загружает все активные продукты из базы данных в кэш.
+// Возвращает количество закэшированных продуктов.
+func (c *ProductCache) WarmProducts(ctx context.Context, db *sql.DB) (int, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT p.id, p.name, p.description, p.price, COALESCE(p.category_id, 0), p.stock, COALESCE(p.stock_type, 'piece'), COALESCE(p.image_url, ''), COALESCE(p.sku, ''), COALESCE(p.color, ''), COALESCE(p.size, ''), p.is_active, p.is_featured, p.sort_order, p.created_at, p.updated_at, p.sale_price, p.sale_ends_at, p.currency, c.slug
+		FROM products p
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE p.is_active = true AND p.deleted_at IS NULL
+		ORDER BY p.id ASC
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запроса продуктов: %w", err)
+	}
+	defer rows.Close()
+
+	var products []models.ProductResponse
+	for rows.Next() {
+		var product models.Product
+		var categorySlug sql.NullString
+		err := rows.Scan(
+			&product.ID, &product.Name, &product.Description, &product.Price,
+			&product.CategoryID, &product.Stock, &product.StockType, &product.ImageURL, &product.SKU,
+			&product.Color, &product.Size, &product.IsActive, &product.IsFeatured,
+			&product.SortOrder, &product.CreatedAt, &product.UpdatedAt, &product.SalePrice, &product.SaleEndsAt, &product.Currency, &categorySlug,
+		)
+		if err != nil {
+			log.Printf("Предупреждение: ошибка сканирования продукта: %v", err)
+			continue
+		}
+
+		response := models.ProductResponse{
+			ID:             product.ID,
+			Name:           product.Name,
+			Description:    product.Description,
+			Price:          product.Price,
+			EffectivePrice: product.EffectivePrice(),
+			SaleEndsAt:     product.SaleEndsAt,
+			Currency:       product.Currency,
+			CategoryID:     product.CategoryID,
+			Stock:          product.Stock,
+			StockType:      product.StockType,
+			ImageURL:       product.ImageURL,
+			SKU:            product.SKU,
+			Color:          product.Color,
+			Size:           product.Size,
+			IsActive:       product.IsActive,
+			IsFeatured:     product.IsFeatured,
+			SortOrder:      product.SortOrder,
+			CreatedAt:      product.CreatedAt,
+			UpdatedAt:      product.UpdatedAt,
+		}
+		if categorySlug.Valid {
+			response.CategorySlug = categorySlug.String
+		}
+		products = append(products, response)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("ошибка чтения продуктов: %w", err)
+	}
+
+	if len(products) == 0 {
+		log.Println("Предупреждение: нет продуктов для кэширования")
+		return 0, nil
+	}
+
+	if err := c.SetProducts(ctx, products); err != nil {
+		return 0, err
+	}
+
+	return len(products), nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"api-go/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	adminEmail    string
+	adminUsername string
+	adminPassword string
+)
+
+var createAdminCmd = &cobra.Command{
+	Use:   "create-admin",
+	Short: "Создать администратора или назначить роль admin существующему пользователю",
+	Long: "Создает пользователя с ролью admin. Если пользователь с таким email уже существует,\n" +
+		"ему назначается роль admin (и новый пароль, если он указан).\n" +
+		"Пароль можно передать флагом --password или переменной ADMIN_PASSWORD;\n" +
+		"если пароль не указан, он будет сгенерирован и выведен один раз.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if adminEmail == "" {
+			return errors.New("укажите --email")
+		}
+		if adminUsername == "" {
+			adminUsername = adminEmail
+		}
+
+		password := adminPassword
+		if password == "" {
+			password = os.Getenv("ADMIN_PASSWORD")
+		}
+		if password != "" && len(password) < 6 {
+			return errors.New("пароль должен содержать не менее 6 символов")
+		}
+
+		_, db, err := openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		var userID int
+		err = db.QueryRow("SELECT id FROM users WHERE email = $1", adminEmail).Scan(&userID)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("ошибка поиска пользователя: %w", err)
+		}
+
+		// Пользователь существует: назначаем роль и, если указан, новый пароль
+		if err == nil {
+			if password == "" {
+				_, err = db.Exec("UPDATE users SET role = 'admin', is_active = true, updated_at = CURRENT_TIMESTAMP WHERE id = $1", userID)
+			} else {
+				hashedPassword, hashErr := utils.HashPassword(password)
+				if hashErr != nil {
+					return fmt.Errorf("ошибка хеширования пароля: %w", hashErr)
+				}
+				_, err = db.Exec("UPDATE users SET role = 'admin', is_active = true, password = $1, must_change_password = false, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", hashedPassword, userID)
+			}
+			if err != nil {
+				return fmt.Errorf("ошибка назначения роли admin: %w", err)
+			}
+			log.Printf("Пользователю id=%d (%s) назначена роль admin", userID, adminEmail)
+			return nil
+		}
+
+		// Новый пользователь
+		generated := password == ""
+		if generated {
+			if password, err = utils.GenerateRandomPassword(); err != nil {
+				return fmt.Errorf("ошибка генерации пароля: %w", err)
+			}
+		}
+
+		hashedPassword, err := utils.HashPassword(password)
+		if err != nil {
+			return fmt.Errorf("ошибка хеширования пароля: %w", err)
+		}
+
+		err = db.QueryRow(`
+			INSERT INTO users (username, email, password, role)
+			VALUES ($1, $2, $3, 'admin')
+			RETURNING id`,
+			adminUsername, adminEmail, hashedPassword,
+		).Scan(&userID)
+		if err != nil {
+			return fmt.Errorf("ошибка создания администратора: %w", err)
+		}
+		log.Printf("Администратор создан: id=%d, email=%s", userID, adminEmail)
+
+		if generated {
+			fmt.Printf("Сгенерированный пароль: %s\n", password)
+		}
+		return nil
+	},
+}
+
+func init() {
+	createAdminCmd.Flags().StringVar(&adminEmail, "email", "", "email администратора")
+	createAdminCmd.Flags().StringVar(&adminUsername, "username", "", "имя пользователя (по умолчанию email)")
+	createAdminCmd.Flags().StringVar(&adminPassword, "password", "", "пароль (по умолчанию ADMIN_PASSWORD или сгенерированный)")
+	rootCmd.AddCommand(createAdminCmd)
+}
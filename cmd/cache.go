@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"log"
+
+	"api-go/cache"
+	"api-go/database"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Управление кэшем Redis",
+}
+
+var cacheWarmCmd = &cobra.Command{
+	Use:   "warm",
+	Short: "Загрузить активные продукты в кэш",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, db, err := openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		redisClient := database.NewRedisClient(cfg.Redis)
+		if err := redisClient.Connect(); err != nil {
+			return err
+		}
+		defer redisClient.Close()
+
+		count, err := cache.NewProductCache(redisClient).WarmProducts(cmd.Context(), db)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Кэшировано %d продуктов", count)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheWarmCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
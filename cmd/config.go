@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"api-go/config"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Работа с конфигурацией",
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Показать эффективную конфигурацию (секреты скрыты)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Read()
+		if err != nil {
+			return err
+		}
+
+		out, err := cfg.Redacted().YAML()
+		if err != nil {
+			return fmt.Errorf("ошибка сериализации конфигурации: %w", err)
+		}
+		fmt.Print(string(out))
+
+		return cfg.Validate()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configPrintCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"api-go/docsgen"
+
+	"github.com/spf13/cobra"
+)
+
+var genDocsOutput string
+
+var genDocsCmd = &cobra.Command{
+	Use:   "gen-docs",
+	Short: "Сгенерировать Swagger документацию (при сборке - go run ./tools/gendocs)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return docsgen.Generate(genDocsOutput)
+	},
+}
+
+func init() {
+	genDocsCmd.Flags().StringVarP(&genDocsOutput, "output", "o", "./docs", "каталог для сгенерированных файлов")
+	rootCmd.AddCommand(genDocsCmd)
+}
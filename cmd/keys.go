@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"api-go/config"
+	"api-go/jwtkeys"
+
+	"github.com/spf13/cobra"
+)
+
+var keysAlgorithm string
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Управление ключами подписи JWT (RS256, EdDSA)",
+}
+
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Создать новый ключ подписи",
+	Long: fmt.Sprintf(`Создает новый ключ в каталоге jwt.keys_dir. Ключ сразу публикуется
+в /.well-known/jwks.json, а подписывать токены начинает через %s,
+когда его получат другие сервисы. Старые ключи продолжают проверять
+выданные ими токены до удаления командой keys prune.`, jwtkeys.ActivationDelay),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		algorithm := keysAlgorithm
+		if algorithm == "" {
+			algorithm = cfg.JWT.Algorithm
+		}
+		if algorithm == config.JWTAlgorithmHS256 {
+			return fmt.Errorf("для HS256 ключи не используются: укажите --algorithm %s или %s",
+				config.JWTAlgorithmRS256, config.JWTAlgorithmEdDSA)
+		}
+
+		key, err := jwtkeys.Generate(cfg.JWT.KeysDir, algorithm)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Создан ключ %s (%s), подпись начнется после %s",
+			key.ID, key.Algorithm, key.CreatedAt.Add(jwtkeys.ActivationDelay).Local().Format(time.DateTime))
+		return nil
+	},
+}
+
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Показать ключи подписи",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		keys, err := jwtkeys.List(cfg.JWT.KeysDir)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			fmt.Printf("Ключей в каталоге %s нет\n", cfg.JWT.KeysDir)
+			return nil
+		}
+
+		for _, key := range keys {
+			fmt.Printf("%s  %-6s  создан %s\n", key.ID, key.Algorithm, key.CreatedAt.Local().Format(time.DateTime))
+		}
+		return nil
+	},
+}
+
+var keysPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Удалить ключи, все токены которых истекли",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		maxTokenAge := time.Duration(cfg.JWT.ExpiryHours) * time.Hour
+		removed, err := jwtkeys.Prune(cfg.JWT.KeysDir, maxTokenAge)
+		for _, id := range removed {
+			log.Printf("Удален ключ %s", id)
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Удалено ключей: %d", len(removed))
+		return nil
+	},
+}
+
+func init() {
+	keysRotateCmd.Flags().StringVar(&keysAlgorithm, "algorithm", "", "алгоритм ключа (RS256, EdDSA); по умолчанию jwt.algorithm")
+	keysCmd.AddCommand(keysRotateCmd, keysListCmd, keysPruneCmd)
+	rootCmd.AddCommand(keysCmd)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"api-go/database"
+	"api-go/migrations"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Применить все новые миграции базы данных",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, db, err := openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		applied, err := database.Migrate(db, migrations.FS)
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			log.Println("Новых миграций нет")
+		} else {
+			log.Printf("Применено миграций: %d", len(applied))
+		}
+		return nil
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Показать состояние миграций",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, db, err := openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		states, err := database.MigrationStatus(db, migrations.FS)
+		if err != nil {
+			return err
+		}
+
+		for _, state := range states {
+			if state.Applied {
+				fmt.Printf("[x] %s (%s)\n", state.Name, state.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("[ ] %s\n", state.Name)
+			}
+		}
+		return nil
+	},
+}
+
+var migrateBaselineCmd = &cobra.Command{
+	Use:   "baseline <version>",
+	Short: "Отметить миграции до версии включительно как уже примененные",
+	Long: "Отмечает миграции как примененные без их выполнения.\n" +
+		"Используется для баз данных, где миграции применялись вручную скриптами.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, db, err := openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		marked, err := database.BaselineMigrations(db, migrations.FS, args[0])
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Отмечено миграций: %d", len(marked))
+		return nil
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateBaselineCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"api-go/config"
+	"api-go/oidcmock"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	oidcMockAddr   string
+	oidcMockIssuer string
+)
+
+var oidcMockCmd = &cobra.Command{
+	Use:   "oidc-mock",
+	Short: "Запустить локальный провайдер OpenID Connect для разработки и тестов",
+	Long: `Запускает провайдер OpenID Connect, который выдает ID токен для любого
+введенного email. Подключение к API:
+
+  OIDC_PROVIDERS=mock
+  OIDC_MOCK_ISSUER=http://localhost:9000
+  OIDC_MOCK_CLIENT_ID=api-go
+
+Вход без формы: /api/v1/auth/oidc/mock/login, затем на странице провайдера
+можно передать login_hint=user@example.com в адресе.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Read()
+		if err != nil {
+			return err
+		}
+		if cfg.IsProduction() {
+			return errors.New("тестовый провайдер OIDC нельзя запускать в production")
+		}
+
+		server, err := oidcmock.New(oidcMockIssuer)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Тестовый провайдер OIDC: %s (слушает %s)", oidcMockIssuer, oidcMockAddr)
+		return http.ListenAndServe(oidcMockAddr, server)
+	},
+}
+
+func init() {
+	oidcMockCmd.Flags().StringVar(&oidcMockAddr, "addr", ":9000", "адрес для входящих соединений")
+	oidcMockCmd.Flags().StringVar(&oidcMockIssuer, "issuer", "http://localhost:9000", "адрес провайдера (issuer)")
+	rootCmd.AddCommand(oidcMockCmd)
+}
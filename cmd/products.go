@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"api-go/handlers"
+	"api-go/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	purgeOlderThan time.Duration
+	purgeDryRun    bool
+)
+
+var productsCmd = &cobra.Command{
+	Use:   "products",
+	Short: "Обслуживание каталога продуктов",
+}
+
+var productsPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Окончательно удалить продукты из корзины удаленных",
+	Long: `Удаляет из базы продукты, которые лежат в корзине удаленных дольше --older-than
+и на которые не ссылаются заказы, отзывы, корзины покупателей и избранное,
+вместе с вариантами, характеристиками и файлами изображений.
+Продукты из заказов и отзывов остаются в корзине. Команду можно запускать по расписанию (cron).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if purgeOlderThan < 0 {
+			return errors.New("--older-than не может быть отрицательным")
+		}
+
+		cfg, db, err := openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		store, err := storage.New(cfg)
+		if err != nil {
+			return err
+		}
+
+		count, err := handlers.PurgeDeletedProducts(cmd.Context(), db, store, time.Now().Add(-purgeOlderThan), purgeDryRun)
+		if purgeDryRun {
+			log.Printf("Будет удалено продуктов: %d", count)
+		} else {
+			log.Printf("Удалено продуктов: %d", count)
+		}
+		return err
+	},
+}
+
+func init() {
+	productsPurgeCmd.Flags().DurationVar(&purgeOlderThan, "older-than", 30*24*time.Hour, "сколько продукт должен пролежать в корзине (например, 720h; 0 - все)")
+	productsPurgeCmd.Flags().BoolVar(&purgeDryRun, "dry-run", false, "только посчитать продукты, ничего не удаляя")
+	productsCmd.AddCommand(productsPurgeCmd)
+	rootCmd.AddCommand(productsCmd)
+}
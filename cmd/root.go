@@ -0,0 +1,71 @@
+// Package cmd содержит команды командной строки приложения
+package cmd
+
+import (
+	"database/sql"
+	"log"
+	"os"
+
+	"api-go/config"
+	"api-go/database"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envFile    string
+	configFile string
+)
+
+// rootCmd — корневая команда. Без подкоманды запускает HTTP сервер.
+var rootCmd = &cobra.Command{
+	Use:           "api-go",
+	Short:         "Products API — REST API интернет-магазина",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Загружаем переменные окружения
+		if err := godotenv.Load(envFile); err != nil {
+			log.Printf("Файл %s не найден, используем системные переменные", envFile)
+		}
+		if configFile != "" {
+			os.Setenv("CONFIG_FILE", configFile)
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServe()
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&envFile, "env-file", "config.env", "файл с переменными окружения")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "YAML файл конфигурации (переопределяет CONFIG_FILE)")
+}
+
+// Execute запускает обработку командной строки
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadConfig загружает и проверяет конфигурацию
+func loadConfig() (*config.Config, error) {
+	return config.Load()
+}
+
+// openDB загружает конфигурацию и подключается к базе данных
+func openDB() (*config.Config, *sql.DB, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, db, nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"api-go/config"
+	"api-go/s3mock"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	s3MockAddr      string
+	s3MockDir       string
+	s3MockAccessKey string
+)
+
+var s3MockCmd = &cobra.Command{
+	Use:   "s3-mock",
+	Short: "Запустить локальное S3-совместимое хранилище для разработки и тестов",
+	Long: `Запускает хранилище, которое сохраняет объекты в каталог и отдает их
+на чтение без авторизации. Подключение к API:
+
+  STORAGE_DRIVER=s3
+  S3_ENDPOINT=http://localhost:9100
+  S3_BUCKET=products
+  S3_ACCESS_KEY=api-go
+  S3_SECRET_KEY=любое-значение
+  S3_PATH_STYLE=true`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Read()
+		if err != nil {
+			return err
+		}
+		if cfg.IsProduction() {
+			return errors.New("тестовое хранилище S3 нельзя запускать в production")
+		}
+
+		log.Printf("Тестовое хранилище S3: каталог %s (слушает %s)", s3MockDir, s3MockAddr)
+		return http.ListenAndServe(s3MockAddr, s3mock.New(s3MockDir, s3MockAccessKey))
+	},
+}
+
+func init() {
+	s3MockCmd.Flags().StringVar(&s3MockAddr, "addr", ":9100", "адрес для входящих соединений")
+	s3MockCmd.Flags().StringVar(&s3MockDir, "dir", "s3-mock", "каталог для объектов")
+	s3MockCmd.Flags().StringVar(&s3MockAccessKey, "access-key", "api-go", "ключ доступа, с которым принимаются запись и удаление")
+	rootCmd.AddCommand(s3MockCmd)
+}
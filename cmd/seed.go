@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"log"
+
+	"api-go/database"
+
+	"github.com/spf13/cobra"
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Заполнить базу данных тестовыми категориями и продуктами",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, db, err := openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if err := database.Seed(db); err != nil {
+			return err
+		}
+
+		log.Println("Тестовые данные загружены")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+}
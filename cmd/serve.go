@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"api-go/cache"
+	"api-go/config"
+	"api-go/database"
+	"api-go/jwtkeys"
+	"api-go/mailer"
+	"api-go/pricing"
+	"api-go/ratelimit"
+	"api-go/routes"
+	"api-go/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Запустить HTTP сервер",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServe()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+// runServe запускает HTTP сервер. Не порождает внешних процессов:
+// документация генерируется при сборке, Redis должен быть запущен заранее.
+func runServe() error {
+	cfg, db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Подключаемся к Redis; без него приложение работает без кэширования
+	redisClient := database.NewRedisClient(cfg.Redis)
+	redisAvailable := true
+	if err := redisClient.Connect(); err != nil {
+		redisAvailable = false
+		log.Printf("Предупреждение: Redis недоступен, кэширование отключено: %v", err)
+	} else {
+		log.Println("Redis подключен, кэширование активно")
+	}
+	defer redisClient.Close()
+
+	// Инициализируем таблицы
+	if err := database.InitTables(db); err != nil {
+		return err
+	}
+
+	// Применяем запланированные цены и акции до заполнения кэша, дальше их переключает планировщик
+	if _, err := pricing.Refresh(context.Background(), db); err != nil {
+		log.Printf("Предупреждение: %v", err)
+	}
+	go pricing.NewScheduler(db, cache.NewProductCache(redisClient)).Run(context.Background())
+
+	// Инициализируем кэш Redis продуктами
+	if redisAvailable {
+		count, err := cache.NewProductCache(redisClient).WarmProducts(context.Background(), db)
+		if err != nil {
+			log.Printf("Предупреждение: Не удалось инициализировать кэш продуктов: %v", err)
+		} else {
+			log.Printf("Кэш продуктов инициализирован: %d продуктов", count)
+		}
+	}
+
+	// Ключи подписи JWT
+	keys, err := loadJWTKeys(cfg)
+	if err != nil {
+		return err
+	}
+	if keys.Asymmetric() {
+		go reloadJWTKeys(keys)
+	}
+
+	// Отправка писем
+	m, err := mailer.New(cfg.Mail)
+	if err != nil {
+		return err
+	}
+	log.Printf("Отправка писем: %s", cfg.Mail.Driver)
+
+	// Хранилище загружаемых изображений
+	store, err := storage.New(cfg)
+	if err != nil {
+		return err
+	}
+	log.Printf("Хранилище файлов: %s", cfg.Storage.Driver)
+
+	// Лимиты запросов хранятся в Redis, при его недоступности - в памяти процесса
+	var limiter ratelimit.Store = ratelimit.NewMemoryStore()
+	if redisAvailable {
+		limiter = ratelimit.NewFallbackStore(ratelimit.NewRedisStore(redisClient.GetClient()), limiter)
+	}
+
+	// Настраиваем маршруты
+	router := routes.SetupRoutes(cfg, db, redisClient, m, store, limiter, keys)
+
+	log.Printf("Сервер запущен на порту %s", cfg.Server.Port)
+	return router.Run(":" + cfg.Server.Port)
+}
+
+// loadJWTKeys загружает ключи подписи JWT. Вне production при отсутствии
+// асимметричного ключа создает его, в production требует выполнить keys rotate.
+func loadJWTKeys(cfg *config.Config) (*jwtkeys.KeySet, error) {
+	keys, err := jwtkeys.Load(cfg.JWT)
+	if err == nil || !errors.Is(err, jwtkeys.ErrNoSigningKey) || cfg.IsProduction() {
+		return keys, err
+	}
+
+	key, err := jwtkeys.Generate(cfg.JWT.KeysDir, cfg.JWT.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Создан ключ подписи JWT %s (%s) в каталоге %s", key.ID, key.Algorithm, cfg.JWT.KeysDir)
+
+	return jwtkeys.Load(cfg.JWT)
+}
+
+// reloadJWTKeys периодически перечитывает каталог ключей,
+// чтобы ротация применялась без перезапуска сервера
+func reloadJWTKeys(keys *jwtkeys.KeySet) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := keys.Reload(); err != nil {
+			log.Printf("Предупреждение: не удалось перечитать ключи JWT: %v", err)
+		}
+	}
+}
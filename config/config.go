@@ -1,89 +1,688 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Значения по умолчанию, которые нельзя использовать в production
+const (
+	DefaultJWTSecret  = "default-secret-key"
+	DefaultDBPassword = "password"
+
+	// EnvProduction обозначает production окружение
+	EnvProduction = "production"
+
+	// redactedValue подставляется вместо секретов при выводе конфигурации
+	redactedValue = "******"
 )
 
 // Config содержит всю конфигурацию приложения
 type Config struct {
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Server   ServerConfig
+	Environment string          `yaml:"environment"`
+	Database    DatabaseConfig  `yaml:"database"`
+	Redis       RedisConfig     `yaml:"redis"`
+	JWT         JWTConfig       `yaml:"jwt"`
+	Server      ServerConfig    `yaml:"server"`
+	Mail        MailConfig      `yaml:"mail"`
+	Storage     StorageConfig   `yaml:"storage"`
+	RateLimit   RateLimitConfig `yaml:"rate_limit"`
+	Lockout     LockoutConfig   `yaml:"lockout"`
+	TwoFactor   TwoFactorConfig `yaml:"two_factor"`
+	OIDC        OIDCConfig      `yaml:"oidc"`
+	CORS        CORSConfig      `yaml:"cors"`
 }
 
 // DatabaseConfig содержит настройки базы данных
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Name     string
-	SSLMode  string
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"ssl_mode"`
 }
 
 // RedisConfig содержит настройки Redis
 type RedisConfig struct {
-	Host     string
-	Port     int
-	Password string
-	DB       int
-	TTL      int // Время жизни кэша в секундах
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+	TTL      int    `yaml:"ttl"` // Время жизни кэша в секундах
 }
 
+// Алгоритмы подписи JWT
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmEdDSA = "EdDSA"
+)
+
 // JWTConfig содержит настройки JWT
 type JWTConfig struct {
-	Secret            string
-	ExpiryHours       int // Время жизни токена в часах
-	RefreshExpiryDays int // Время жизни refresh токена в днях
+	Algorithm         string `yaml:"algorithm"`           // HS256 (общий секрет), RS256 или EdDSA (ключи в KeysDir)
+	KeysDir           string `yaml:"keys_dir"`            // Каталог закрытых ключей для RS256/EdDSA
+	Secret            string `yaml:"secret"`              // Используется только для HS256
+	ExpiryHours       int    `yaml:"expiry_hours"`        // Время жизни токена в часах
+	RefreshExpiryDays int    `yaml:"refresh_expiry_days"` // Время жизни refresh токена в днях
 }
 
 // ServerConfig содержит настройки сервера
 type ServerConfig struct {
-	Port string
+	Port           string   `yaml:"port"`
+	PublicURL      string   `yaml:"public_url"`      // Внешний адрес API для ссылок в письмах
+	TrustedProxies []string `yaml:"trusted_proxies"` // Прокси, которым доверяется X-Forwarded-For
 }
 
-// Load загружает конфигурацию из переменных окружения
-func Load() *Config {
-	dbPort, _ := strconv.Atoi(getEnv("DB_PORT", "5432"))
-	redisPort, _ := strconv.Atoi(getEnv("REDIS_PORT", "6379"))
-	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
-	redisTTL, _ := strconv.Atoi(getEnv("REDIS_TTL", "3600"))
-	jwtExpiryHours, _ := strconv.Atoi(getEnv("JWT_EXPIRY_HOURS", "24"))
-	jwtRefreshExpiryDays, _ := strconv.Atoi(getEnv("JWT_REFRESH_EXPIRY_DAYS", "7"))
+// Способы отправки писем
+const (
+	MailDriverLog  = "log"
+	MailDriverFile = "file"
+	MailDriverSMTP = "smtp"
+)
+
+// MailConfig содержит настройки отправки писем
+type MailConfig struct {
+	Driver       string `yaml:"driver"` // log, file или smtp
+	From         string `yaml:"from"`
+	Dir          string `yaml:"dir"` // Каталог для писем при driver=file
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+}
 
+// Хранилища загружаемых файлов
+const (
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+// StorageConfig содержит настройки хранения загружаемых файлов (изображений продуктов)
+type StorageConfig struct {
+	Driver      string `yaml:"driver"`        // local или s3
+	Dir         string `yaml:"dir"`           // Каталог файлов при driver=local, раздается по /media
+	PublicURL   string `yaml:"public_url"`    // По умолчанию {server.public_url}/media для local, {s3_endpoint}/{s3_bucket} для s3
+	MaxUploadMB int    `yaml:"max_upload_mb"` // Максимальный размер загружаемого файла
+	S3Endpoint  string `yaml:"s3_endpoint"`   // Например, https://s3.eu-central-1.amazonaws.com или http://localhost:9100
+	S3Region    string `yaml:"s3_region"`
+	S3Bucket    string `yaml:"s3_bucket"`
+	S3AccessKey string `yaml:"s3_access_key"`
+	S3SecretKey string `yaml:"s3_secret_key"`
+	S3PathStyle bool   `yaml:"s3_path_style"` // Адреса вида {endpoint}/{bucket}/{key} (MinIO, s3-mock)
+}
+
+// MaxUploadBytes возвращает максимальный размер загружаемого файла в байтах
+func (c StorageConfig) MaxUploadBytes() int64 {
+	return int64(c.MaxUploadMB) << 20
+}
+
+// RateLimitConfig содержит настройки ограничения частоты запросов
+type RateLimitConfig struct {
+	Enabled       bool            `yaml:"enabled"`
+	Login         RateLimitPolicy `yaml:"login"`          // Вход, по IP и по email
+	Register      RateLimitPolicy `yaml:"register"`       // Регистрация, по IP
+	PasswordReset RateLimitPolicy `yaml:"password_reset"` // Сброс пароля и подтверждение email, по IP и по email
+	Public        RateLimitPolicy `yaml:"public"`         // Публичные маршруты продуктов, по IP
+	API           RateLimitPolicy `yaml:"api"`            // Защищенные маршруты, по пользователю
+}
+
+// RateLimitPolicy допускает не более Requests запросов за Window секунд
+type RateLimitPolicy struct {
+	Requests int `yaml:"requests"`
+	Window   int `yaml:"window"` // Окно в секундах
+}
+
+// WindowDuration возвращает окно политики
+func (p RateLimitPolicy) WindowDuration() time.Duration {
+	return time.Duration(p.Window) * time.Second
+}
+
+// LockoutConfig содержит настройки защиты учетных записей от подбора пароля.
+// После FreeAttempts неудач каждая следующая блокирует вход на BaseDelay секунд,
+// удваивая задержку; после MaxAttempts неудач вход блокируется на LockoutMinutes.
+type LockoutConfig struct {
+	FreeAttempts   int `yaml:"free_attempts"`
+	BaseDelay      int `yaml:"base_delay"` // Секунды
+	MaxAttempts    int `yaml:"max_attempts"`
+	LockoutMinutes int `yaml:"lockout_minutes"`
+}
+
+// TwoFactorConfig содержит настройки двухфакторной аутентификации
+type TwoFactorConfig struct {
+	Issuer           string `yaml:"issuer"`            // Название сервиса в приложении-аутентификаторе
+	RequireForAdmin  bool   `yaml:"require_for_admin"` // Служебные маршруты недоступны без 2FA пользователям с разрешениями сверх роли user
+	ChallengeMinutes int    `yaml:"challenge_minutes"` // Время на ввод кода после пароля
+}
+
+// OIDCConfig содержит настройки входа через внешних провайдеров OpenID Connect
+type OIDCConfig struct {
+	Providers []OIDCProvider `yaml:"providers"`
+}
+
+// OIDCProvider настройки провайдера OpenID Connect
+type OIDCProvider struct {
+	Name         string   `yaml:"name"`          // Используется в маршрутах /auth/oidc/{name}/...
+	Issuer       string   `yaml:"issuer"`        // Адрес, по которому доступен /.well-known/openid-configuration
+	ClientID     string   `yaml:"client_id"`     // Идентификатор клиента у провайдера
+	ClientSecret string   `yaml:"client_secret"` // Пусто для публичного клиента (только PKCE)
+	Scopes       []string `yaml:"scopes"`        // Дополнительно к openid; по умолчанию email и profile
+	RedirectURL  string   `yaml:"redirect_url"`  // По умолчанию {public_url}/api/v1/auth/oidc/{name}/callback
+}
+
+// Provider возвращает настройки провайдера по имени
+func (c OIDCConfig) Provider(name string) (OIDCProvider, bool) {
+	for _, p := range c.Providers {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return OIDCProvider{}, false
+}
+
+// CORSConfig содержит политику кросс-доменных запросов
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"` // Точные адреса, шаблоны вида https://*.example.com или *
+	AllowedMethods   []string `yaml:"allowed_methods"`
+	AllowedHeaders   []string `yaml:"allowed_headers"`
+	ExposedHeaders   []string `yaml:"exposed_headers"`   // Заголовки ответа, доступные скриптам
+	AllowCredentials bool     `yaml:"allow_credentials"` // Несовместимо с * в allowed_origins
+	MaxAge           int      `yaml:"max_age"`           // Время кэширования preflight в секундах
+}
+
+// Default возвращает конфигурацию со значениями по умолчанию
+func Default() *Config {
 	return &Config{
+		Environment: "development",
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     dbPort,
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "password"),
-			Name:     getEnv("DB_NAME", "products_db"),
-			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+			Host:     "localhost",
+			Port:     5432,
+			User:     "postgres",
+			Password: DefaultDBPassword,
+			Name:     "products_db",
+			SSLMode:  "disable",
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     redisPort,
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       redisDB,
-			TTL:      redisTTL,
+			Host: "localhost",
+			Port: 6379,
+			DB:   0,
+			TTL:  3600,
 		},
 		JWT: JWTConfig{
-			Secret:            getEnv("JWT_SECRET", "default-secret-key"),
-			ExpiryHours:       jwtExpiryHours,
-			RefreshExpiryDays: jwtRefreshExpiryDays,
+			Algorithm:         JWTAlgorithmHS256,
+			KeysDir:           "keys",
+			Secret:            DefaultJWTSecret,
+			ExpiryHours:       24,
+			RefreshExpiryDays: 7,
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:      "8080",
+			PublicURL: "http://localhost:8080",
+			// Локальные и частные сети (nginx, docker)
+			TrustedProxies: []string{"127.0.0.1", "::1", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+		},
+		Mail: MailConfig{
+			Driver:   MailDriverLog,
+			From:     "no-reply@localhost",
+			Dir:      "mail",
+			SMTPPort: 587,
+		},
+		Storage: StorageConfig{
+			Driver:      StorageDriverLocal,
+			Dir:         "uploads",
+			MaxUploadMB: 10,
+			S3Region:    "us-east-1",
 		},
+		RateLimit: RateLimitConfig{
+			Enabled:       true,
+			Login:         RateLimitPolicy{Requests: 10, Window: 60},
+			Register:      RateLimitPolicy{Requests: 5, Window: 3600},
+			PasswordReset: RateLimitPolicy{Requests: 5, Window: 900},
+			Public:        RateLimitPolicy{Requests: 300, Window: 60},
+			API:           RateLimitPolicy{Requests: 600, Window: 60},
+		},
+		Lockout: LockoutConfig{
+			FreeAttempts:   3,
+			BaseDelay:      2,
+			MaxAttempts:    10,
+			LockoutMinutes: 15,
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:           "Products API",
+			ChallengeMinutes: 5,
+		},
+		CORS: CORSConfig{
+			// Аутентификация по заголовкам, а не cookie, поэтому credentials не нужны
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Authorization", "Content-Type", "Accept", "X-API-Key", "X-Requested-With", "Cache-Control", "X-Request-ID"},
+			ExposedHeaders: []string{
+				"X-Cache", "ETag", "Retry-After", "X-Request-ID",
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+			},
+			MaxAge: 600,
+		},
+	}
+}
+
+// Load загружает конфигурацию и проверяет ее.
+// Возвращает ошибку, если значения не удалось разобрать или они не прошли проверку.
+func Load() (*Config, error) {
+	cfg, err := Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Read загружает конфигурацию слоями без проверки: значения по умолчанию,
+// затем файл конфигурации (CONFIG_FILE или config.yaml), затем переменные окружения.
+func Read() (*Config, error) {
+	cfg := Default()
+
+	if err := cfg.loadFile(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadFile накладывает значения из YAML файла конфигурации
+func (c *Config) loadFile() error {
+	path, required := os.Getenv("CONFIG_FILE"), true
+	if path == "" {
+		path, required = "config.yaml", false
 	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil
+		}
+		return fmt.Errorf("ошибка чтения файла конфигурации %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("ошибка разбора файла конфигурации %s: %w", path, err)
+	}
+
+	return nil
 }
 
-// getEnv получает значение переменной окружения или возвращает значение по умолчанию
-func getEnv(key, defaultValue string) string {
+// loadEnv накладывает значения из переменных окружения
+func (c *Config) loadEnv() error {
+	var errs []error
+
+	setString("ENVIRONMENT", &c.Environment)
+
+	setString("DB_HOST", &c.Database.Host)
+	errs = append(errs, setInt("DB_PORT", &c.Database.Port))
+	setString("DB_USER", &c.Database.User)
+	setString("DB_PASSWORD", &c.Database.Password)
+	setString("DB_NAME", &c.Database.Name)
+	setString("DB_SSL_MODE", &c.Database.SSLMode)
+
+	setString("REDIS_HOST", &c.Redis.Host)
+	errs = append(errs, setInt("REDIS_PORT", &c.Redis.Port))
+	setString("REDIS_PASSWORD", &c.Redis.Password)
+	errs = append(errs, setInt("REDIS_DB", &c.Redis.DB))
+	errs = append(errs, setInt("REDIS_TTL", &c.Redis.TTL))
+
+	setString("JWT_ALGORITHM", &c.JWT.Algorithm)
+	setString("JWT_KEYS_DIR", &c.JWT.KeysDir)
+	setString("JWT_SECRET", &c.JWT.Secret)
+	errs = append(errs, setInt("JWT_EXPIRY_HOURS", &c.JWT.ExpiryHours))
+	errs = append(errs, setInt("JWT_REFRESH_EXPIRY_DAYS", &c.JWT.RefreshExpiryDays))
+
+	setString("SERVER_PORT", &c.Server.Port)
+	setString("PUBLIC_URL", &c.Server.PublicURL)
+	setList("TRUSTED_PROXIES", &c.Server.TrustedProxies)
+
+	setString("MAIL_DRIVER", &c.Mail.Driver)
+	setString("MAIL_FROM", &c.Mail.From)
+	setString("MAIL_DIR", &c.Mail.Dir)
+	setString("SMTP_HOST", &c.Mail.SMTPHost)
+	errs = append(errs, setInt("SMTP_PORT", &c.Mail.SMTPPort))
+	setString("SMTP_USERNAME", &c.Mail.SMTPUsername)
+	setString("SMTP_PASSWORD", &c.Mail.SMTPPassword)
+
+	setString("STORAGE_DRIVER", &c.Storage.Driver)
+	setString("STORAGE_DIR", &c.Storage.Dir)
+	setString("STORAGE_PUBLIC_URL", &c.Storage.PublicURL)
+	errs = append(errs, setInt("STORAGE_MAX_UPLOAD_MB", &c.Storage.MaxUploadMB))
+	setString("S3_ENDPOINT", &c.Storage.S3Endpoint)
+	setString("S3_REGION", &c.Storage.S3Region)
+	setString("S3_BUCKET", &c.Storage.S3Bucket)
+	setString("S3_ACCESS_KEY", &c.Storage.S3AccessKey)
+	setString("S3_SECRET_KEY", &c.Storage.S3SecretKey)
+	errs = append(errs, setBool("S3_PATH_STYLE", &c.Storage.S3PathStyle))
+
+	errs = append(errs, setBool("RATE_LIMIT_ENABLED", &c.RateLimit.Enabled))
+	errs = append(errs, setInt("RATE_LIMIT_LOGIN_REQUESTS", &c.RateLimit.Login.Requests))
+	errs = append(errs, setInt("RATE_LIMIT_LOGIN_WINDOW", &c.RateLimit.Login.Window))
+	errs = append(errs, setInt("RATE_LIMIT_REGISTER_REQUESTS", &c.RateLimit.Register.Requests))
+	errs = append(errs, setInt("RATE_LIMIT_REGISTER_WINDOW", &c.RateLimit.Register.Window))
+	errs = append(errs, setInt("RATE_LIMIT_PASSWORD_RESET_REQUESTS", &c.RateLimit.PasswordReset.Requests))
+	errs = append(errs, setInt("RATE_LIMIT_PASSWORD_RESET_WINDOW", &c.RateLimit.PasswordReset.Window))
+	errs = append(errs, setInt("RATE_LIMIT_PUBLIC_REQUESTS", &c.RateLimit.Public.Requests))
+	errs = append(errs, setInt("RATE_LIMIT_PUBLIC_WINDOW", &c.RateLimit.Public.Window))
+	errs = append(errs, setInt("RATE_LIMIT_API_REQUESTS", &c.RateLimit.API.Requests))
+	errs = append(errs, setInt("RATE_LIMIT_API_WINDOW", &c.RateLimit.API.Window))
+
+	errs = append(errs, setInt("LOCKOUT_FREE_ATTEMPTS", &c.Lockout.FreeAttempts))
+	errs = append(errs, setInt("LOCKOUT_BASE_DELAY", &c.Lockout.BaseDelay))
+	errs = append(errs, setInt("LOCKOUT_MAX_ATTEMPTS", &c.Lockout.MaxAttempts))
+	errs = append(errs, setInt("LOCKOUT_MINUTES", &c.Lockout.LockoutMinutes))
+
+	setString("TWO_FACTOR_ISSUER", &c.TwoFactor.Issuer)
+	errs = append(errs, setBool("TWO_FACTOR_REQUIRE_FOR_ADMIN", &c.TwoFactor.RequireForAdmin))
+	errs = append(errs, setInt("TWO_FACTOR_CHALLENGE_MINUTES", &c.TwoFactor.ChallengeMinutes))
+
+	setList("CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins)
+	setList("CORS_ALLOWED_METHODS", &c.CORS.AllowedMethods)
+	setList("CORS_ALLOWED_HEADERS", &c.CORS.AllowedHeaders)
+	setList("CORS_EXPOSED_HEADERS", &c.CORS.ExposedHeaders)
+	errs = append(errs, setBool("CORS_ALLOW_CREDENTIALS", &c.CORS.AllowCredentials))
+	errs = append(errs, setInt("CORS_MAX_AGE", &c.CORS.MaxAge))
+
+	c.loadOIDCEnv()
+
+	return errors.Join(errs...)
+}
+
+// loadOIDCEnv накладывает настройки провайдеров OIDC: OIDC_PROVIDERS задает список имен,
+// OIDC_<ИМЯ>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _SCOPES, _REDIRECT_URL - их параметры.
+// Провайдеры из файла конфигурации, не указанные в OIDC_PROVIDERS, отключаются.
+func (c *Config) loadOIDCEnv() {
+	var names []string
+	setList("OIDC_PROVIDERS", &names)
+	if names == nil {
+		return
+	}
+
+	providers := make([]OIDCProvider, 0, len(names))
+	for _, name := range names {
+		p, _ := c.OIDC.Provider(name)
+		p.Name = name
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		setString(prefix+"ISSUER", &p.Issuer)
+		setString(prefix+"CLIENT_ID", &p.ClientID)
+		setString(prefix+"CLIENT_SECRET", &p.ClientSecret)
+		setList(prefix+"SCOPES", &p.Scopes)
+		setString(prefix+"REDIRECT_URL", &p.RedirectURL)
+
+		providers = append(providers, p)
+	}
+	c.OIDC.Providers = providers
+}
+
+// Validate проверяет корректность конфигурации.
+// В production окружении запрещены секреты по умолчанию.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Database.Port <= 0 || c.Database.Port > 65535 {
+		errs = append(errs, fmt.Errorf("database.port: недопустимый порт %d", c.Database.Port))
+	}
+	if c.Redis.Port <= 0 || c.Redis.Port > 65535 {
+		errs = append(errs, fmt.Errorf("redis.port: недопустимый порт %d", c.Redis.Port))
+	}
+	if c.Redis.TTL < 0 {
+		errs = append(errs, fmt.Errorf("redis.ttl: значение не может быть отрицательным"))
+	}
+	switch c.JWT.Algorithm {
+	case JWTAlgorithmHS256:
+		if c.JWT.Secret == "" {
+			errs = append(errs, fmt.Errorf("jwt.secret: значение не задано"))
+		}
+	case JWTAlgorithmRS256, JWTAlgorithmEdDSA:
+		if c.JWT.KeysDir == "" {
+			errs = append(errs, fmt.Errorf("jwt.keys_dir: значение не задано"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("jwt.algorithm: неизвестный алгоритм %q (HS256, RS256, EdDSA)", c.JWT.Algorithm))
+	}
+	if c.JWT.ExpiryHours <= 0 {
+		errs = append(errs, fmt.Errorf("jwt.expiry_hours: значение должно быть положительным"))
+	}
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port <= 0 || port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port: недопустимый порт %q", c.Server.Port))
+	}
+
+	switch c.Mail.Driver {
+	case MailDriverLog, MailDriverFile, MailDriverSMTP:
+	default:
+		errs = append(errs, fmt.Errorf("mail.driver: неизвестный способ отправки %q (log, file, smtp)", c.Mail.Driver))
+	}
+	if c.Mail.From == "" {
+		errs = append(errs, fmt.Errorf("mail.from: значение не задано"))
+	}
+	if c.Mail.Driver == MailDriverFile && c.Mail.Dir == "" {
+		errs = append(errs, fmt.Errorf("mail.dir: значение не задано"))
+	}
+	if c.Mail.Driver == MailDriverSMTP {
+		if c.Mail.SMTPHost == "" {
+			errs = append(errs, fmt.Errorf("mail.smtp_host: значение не задано"))
+		}
+		if c.Mail.SMTPPort <= 0 || c.Mail.SMTPPort > 65535 {
+			errs = append(errs, fmt.Errorf("mail.smtp_port: недопустимый порт %d", c.Mail.SMTPPort))
+		}
+	}
+
+	switch c.Storage.Driver {
+	case StorageDriverLocal:
+		if c.Storage.Dir == "" {
+			errs = append(errs, fmt.Errorf("storage.dir: значение не задано"))
+		}
+	case StorageDriverS3:
+		if !strings.HasPrefix(c.Storage.S3Endpoint, "http://") && !strings.HasPrefix(c.Storage.S3Endpoint, "https://") {
+			errs = append(errs, fmt.Errorf("storage.s3_endpoint: адрес %q должен начинаться с http:// или https://", c.Storage.S3Endpoint))
+		}
+		if c.Storage.S3Region == "" {
+			errs = append(errs, fmt.Errorf("storage.s3_region: значение не задано"))
+		}
+		if c.Storage.S3Bucket == "" {
+			errs = append(errs, fmt.Errorf("storage.s3_bucket: значение не задано"))
+		}
+		if c.Storage.S3AccessKey == "" || c.Storage.S3SecretKey == "" {
+			errs = append(errs, fmt.Errorf("storage: s3_access_key и s3_secret_key должны быть заданы"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("storage.driver: неизвестное хранилище %q (local, s3)", c.Storage.Driver))
+	}
+	if c.Storage.MaxUploadMB <= 0 {
+		errs = append(errs, fmt.Errorf("storage.max_upload_mb: значение должно быть положительным"))
+	}
+
+	if c.RateLimit.Enabled {
+		policies := []struct {
+			name   string
+			policy RateLimitPolicy
+		}{
+			{"login", c.RateLimit.Login},
+			{"register", c.RateLimit.Register},
+			{"password_reset", c.RateLimit.PasswordReset},
+			{"public", c.RateLimit.Public},
+			{"api", c.RateLimit.API},
+		}
+		for _, p := range policies {
+			if p.policy.Requests <= 0 || p.policy.Window <= 0 {
+				errs = append(errs, fmt.Errorf("rate_limit.%s: requests и window должны быть положительными", p.name))
+			}
+		}
+	}
+
+	if c.Lockout.FreeAttempts < 0 || c.Lockout.BaseDelay <= 0 || c.Lockout.LockoutMinutes <= 0 {
+		errs = append(errs, fmt.Errorf("lockout: free_attempts не может быть отрицательным, base_delay и lockout_minutes должны быть положительными"))
+	}
+	if c.Lockout.MaxAttempts <= c.Lockout.FreeAttempts {
+		errs = append(errs, fmt.Errorf("lockout.max_attempts: должно быть больше free_attempts"))
+	}
+
+	if c.TwoFactor.Issuer == "" {
+		errs = append(errs, fmt.Errorf("two_factor.issuer: значение не задано"))
+	}
+	if c.TwoFactor.ChallengeMinutes <= 0 {
+		errs = append(errs, fmt.Errorf("two_factor.challenge_minutes: значение должно быть положительным"))
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				errs = append(errs, fmt.Errorf("cors.allowed_origins: * нельзя сочетать с allow_credentials, перечислите адреса"))
+			}
+			continue
+		}
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			errs = append(errs, fmt.Errorf("cors.allowed_origins: адрес %q должен начинаться с http:// или https://", origin))
+		}
+		if strings.HasSuffix(origin, "/") {
+			errs = append(errs, fmt.Errorf("cors.allowed_origins: адрес %q не должен заканчиваться на /", origin))
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("cors.max_age: значение не может быть отрицательным"))
+	}
+
+	seenProviders := make(map[string]bool, len(c.OIDC.Providers))
+	for i, p := range c.OIDC.Providers {
+		if p.Name == "" || strings.ContainsAny(p.Name, "/?#") {
+			errs = append(errs, fmt.Errorf("oidc.providers[%d].name: недопустимое имя %q", i, p.Name))
+		}
+		if seenProviders[p.Name] {
+			errs = append(errs, fmt.Errorf("oidc.providers[%d].name: провайдер %q указан дважды", i, p.Name))
+		}
+		seenProviders[p.Name] = true
+		if p.Issuer == "" {
+			errs = append(errs, fmt.Errorf("oidc.providers[%d].issuer: значение не задано", i))
+		}
+		if p.ClientID == "" {
+			errs = append(errs, fmt.Errorf("oidc.providers[%d].client_id: значение не задано", i))
+		}
+	}
+
+	if c.IsProduction() {
+		if c.JWT.Algorithm == JWTAlgorithmHS256 {
+			if c.JWT.Secret == DefaultJWTSecret {
+				errs = append(errs, fmt.Errorf("jwt.secret: в production нельзя использовать секрет по умолчанию"))
+			}
+			if len(c.JWT.Secret) < 32 {
+				errs = append(errs, fmt.Errorf("jwt.secret: в production длина секрета должна быть не менее 32 символов"))
+			}
+		}
+		if c.Database.Password == DefaultDBPassword {
+			errs = append(errs, fmt.Errorf("database.password: в production нельзя использовать пароль по умолчанию"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("некорректная конфигурация: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// IsProduction сообщает, запущено ли приложение в production окружении
+func (c *Config) IsProduction() bool {
+	return c.Environment == EnvProduction || c.Environment == "prod"
+}
+
+// Redacted возвращает копию конфигурации со скрытыми секретами
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Database.Password = redact(c.Database.Password)
+	redacted.Redis.Password = redact(c.Redis.Password)
+	redacted.JWT.Secret = redact(c.JWT.Secret)
+	redacted.Mail.SMTPPassword = redact(c.Mail.SMTPPassword)
+	redacted.Storage.S3SecretKey = redact(c.Storage.S3SecretKey)
+	redacted.OIDC.Providers = make([]OIDCProvider, len(c.OIDC.Providers))
+	for i, p := range c.OIDC.Providers {
+		p.ClientSecret = redact(p.ClientSecret)
+		redacted.OIDC.Providers[i] = p
+	}
+	return &redacted
+}
+
+// YAML возвращает конфигурацию в формате YAML
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// redact скрывает непустое секретное значение
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// setString перезаписывает значение, если переменная окружения задана
+func setString(key string, dst *string) {
 	if value := os.Getenv(key); value != "" {
-		return value
+		*dst = value
+	}
+}
+
+// setBool перезаписывает логическое значение, если переменная окружения задана
+func setBool(key string, dst *bool) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%s: ожидается true или false, получено %q", key, value)
+	}
+
+	*dst = parsed
+	return nil
+}
+
+// setList перезаписывает список значениями через запятую, если переменная окружения задана
+func setList(key string, dst *[]string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-	return defaultValue
+	*dst = items
+}
+
+// setInt перезаписывает целое значение, если переменная окружения задана
+func setInt(key string, dst *int) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s: ожидается целое число, получено %q", key, value)
+	}
+
+	*dst = parsed
+	return nil
 }
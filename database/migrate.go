@@ -0,0 +1,188 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Migration описывает одну SQL миграцию
+type Migration struct {
+	Version string // Номер миграции (префикс имени файла, например "007")
+	Name    string // Имя файла без расширения
+	SQL     string
+}
+
+// MigrationState описывает состояние миграции в базе данных
+type MigrationState struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// createMigrationsTable создает таблицу учета примененных миграций
+const createMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version VARCHAR(50) PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);`
+
+// LoadMigrations читает миграции из файловой системы и сортирует их по версии
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска файлов миграций: %w", err)
+	}
+	sort.Strings(files)
+
+	migrations := make([]Migration, 0, len(files))
+	seen := make(map[string]string)
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".sql")
+		version, _, _ := strings.Cut(name, "_")
+
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("дублирующаяся версия миграции %s: %s и %s", version, other, name)
+		}
+		seen[version] = name
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения миграции %s: %w", file, err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			SQL:     string(content),
+		})
+	}
+
+	return migrations, nil
+}
+
+// MigrationStatus возвращает список миграций с отметкой о применении
+func MigrationStatus(db *sql.DB, fsys fs.FS) ([]MigrationState, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Migration: m}
+		if at, ok := applied[m.Version]; ok {
+			appliedAt := at
+			state.Applied = true
+			state.AppliedAt = &appliedAt
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// Migrate применяет все еще не примененные миграции по порядку.
+// Каждая миграция выполняется в отдельной транзакции.
+func Migrate(db *sql.DB, fsys fs.FS) ([]string, error) {
+	states, err := MigrationStatus(db, fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for _, state := range states {
+		if state.Applied {
+			continue
+		}
+
+		if err := applyMigration(db, state.Migration); err != nil {
+			return done, err
+		}
+		log.Printf("Миграция %s применена", state.Name)
+		done = append(done, state.Name)
+	}
+
+	return done, nil
+}
+
+// BaselineMigrations отмечает миграции до указанной версии включительно как примененные,
+// не выполняя их. Используется для баз, где миграции применялись вручную.
+func BaselineMigrations(db *sql.DB, fsys fs.FS, version string) ([]string, error) {
+	states, err := MigrationStatus(db, fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var marked []string
+	for _, state := range states {
+		if state.Version > version {
+			break
+		}
+		if state.Applied {
+			continue
+		}
+
+		if _, err := db.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", state.Version, state.Name); err != nil {
+			return marked, fmt.Errorf("ошибка отметки миграции %s: %w", state.Name, err)
+		}
+		marked = append(marked, state.Name)
+	}
+
+	return marked, nil
+}
+
+// applyMigration выполняет миграцию и записывает ее в schema_migrations
+func applyMigration(db *sql.DB, m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return fmt.Errorf("ошибка применения миграции %s: %w", m.Name, err)
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+		return fmt.Errorf("ошибка записи миграции %s: %w", m.Name, err)
+	}
+
+	return tx.Commit()
+}
+
+// appliedMigrations возвращает версии примененных миграций
+func appliedMigrations(db *sql.DB) (map[string]time.Time, error) {
+	if _, err := db.Exec(createMigrationsTable); err != nil {
+		return nil, fmt.Errorf("ошибка создания таблицы schema_migrations: %w", err)
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]time.Time)
+	for rows.Next() {
+		var version string
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
@@ -0,0 +1,21 @@
+package database
+
+import (
+	"database/sql"
+	_ "embed"
+	"fmt"
+)
+
+// seedSQL содержит тестовые данные (категории и продукты)
+//
+//go:embed seed.sql
+var seedSQL string
+
+// Seed заполняет базу данных тестовыми данными.
+// Повторный запуск не создает дубликатов.
+func Seed(db *sql.DB) error {
+	if _, err := db.Exec(seedSQL); err != nil {
+		return fmt.Errorf("ошибка заполнения тестовыми данными: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+-- Тестовые данные для локальной разработки
+-- Применяются командой: api-go seed
+
+-- Категории
+INSERT INTO categories (name, description, slug, sort_order) VALUES 
+('Электроника', 'Электронные устройства и гаджеты', 'electronics', 1),
+('Одежда', 'Мужская и женская одежда', 'clothing', 2),
+('Книги', 'Художественная и техническая литература', 'books', 3),
+('Спорт', 'Спортивные товары и оборудование', 'sports', 4)
+ON CONFLICT (slug) DO NOTHING;
+
+-- Подкатегории
+INSERT INTO categories (name, description, slug, parent_id, sort_order) VALUES 
+('Смартфоны', 'Мобильные телефоны', 'smartphones', (SELECT id FROM categories WHERE slug = 'electronics'), 1),
+('Ноутбуки', 'Портативные компьютеры', 'laptops', (SELECT id FROM categories WHERE slug = 'electronics'), 2),
+('Мужская одежда', 'Одежда для мужчин', 'mens-clothing', (SELECT id FROM categories WHERE slug = 'clothing'), 1),
+('Женская одежда', 'Одежда для женщин', 'womens-clothing', (SELECT id FROM categories WHERE slug = 'clothing'), 2)
+ON CONFLICT (slug) DO NOTHING;
+
+-- Продукты
+INSERT INTO products (name, description, price, category_id, stock, sku, is_featured)
+SELECT v.name, v.description, v.price, c.id, v.stock, v.sku, v.is_featured
+FROM (VALUES
+    ('iPhone 15 Pro', 'Новейший смартфон Apple с мощным процессором', 99999.99, 'smartphones', 50, 'IPHONE15PRO', true),
+    ('MacBook Air M2', 'Легкий и мощный ноутбук с чипом M2', 149999.99, 'laptops', 25, 'MACBOOKAIRM2', true),
+    ('Футболка мужская', 'Хлопковая футболка для повседневной носки', 2999.99, 'mens-clothing', 100, 'TSHIRT-MENS', false),
+    ('Платье женское', 'Элегантное платье для особых случаев', 5999.99, 'womens-clothing', 75, 'DRESS-WOMENS', false),
+    ('Книга "Война и мир"', 'Классический роман Льва Толстого', 899.99, 'books', 200, 'BOOK-WAR-PEACE', false),
+    ('Футбольный мяч', 'Профессиональный футбольный мяч', 3999.99, 'sports', 30, 'BALL-FOOTBALL', false)
+) AS v(name, description, price, category_slug, stock, sku, is_featured)
+JOIN categories c ON c.slug = v.category_slug
+WHERE NOT EXISTS (SELECT 1 FROM products p WHERE p.sku = v.sku);
@@ -0,0 +1,26 @@
+// Package docsgen генерирует Swagger документацию по аннотациям обработчиков.
+// Не импортирует сгенерированный пакет docs, поэтому работает и без него (после make clean).
+package docsgen
+
+import (
+	"github.com/swaggo/swag"
+	"github.com/swaggo/swag/gen"
+)
+
+// Generate генерирует docs.go, swagger.json и swagger.yaml в каталоге output.
+// Запускается из корня модуля: аннотации API читаются из main.go.
+func Generate(output string) error {
+	return gen.New().Build(&gen.Config{
+		SearchDir:          "./",
+		MainAPIFile:        "main.go",
+		OutputDir:          output,
+		OutputTypes:        []string{"go", "json", "yaml"},
+		PropNamingStrategy: swag.CamelCase,
+		ParseDepth:         100,
+		InstanceName:       swag.Name,
+		PackageName:        "docs",
+		OverridesFile:      gen.DefaultOverridesFile,
+		LeftTemplateDelim:  "{{",
+		RightTemplateDelim: "}}",
+	})
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"api-go/apierror"
+	"api-go/models"
+	"api-go/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// apiKeyColumns список колонок для выборки API ключа вместе с именем владельца
+const apiKeyColumns = `k.id, k.name, k.prefix, k.user_id, u.username, k.permissions,
+	k.expires_at, k.last_used_at, k.last_used_ip, k.revoked_at, k.created_at`
+
+// APIKeyHandler обрабатывает запросы администратора для управления API ключами
+type APIKeyHandler struct {
+	db *sql.DB
+}
+
+// NewAPIKeyHandler создает новый экземпляр APIKeyHandler
+func NewAPIKeyHandler(db *sql.DB) *APIKeyHandler {
+	return &APIKeyHandler{
+		db: db,
+	}
+}
+
+// GetAPIKeys возвращает список API ключей
+// @Summary Список API ключей
+// @Description Возвращает API ключи без секретов (требует разрешение api_keys:manage)
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Фильтр по владельцу"
+// @Param include_revoked query bool false "Включить отозванные ключи"
+// @Success 200 {array} models.APIKey
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	whereClause := "WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.Atoi(userID)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidUserID)
+			return
+		}
+		whereClause += fmt.Sprintf(" AND k.user_id = $%d", argIndex)
+		args = append(args, id)
+		argIndex++
+	}
+
+	if includeRevoked, _ := strconv.ParseBool(c.Query("include_revoked")); !includeRevoked {
+		whereClause += " AND k.revoked_at IS NULL"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM api_keys k JOIN users u ON u.id = k.user_id
+		%s
+		ORDER BY k.id DESC`, apiKeyColumns, whereClause)
+
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey выпускает новый API ключ
+// @Summary Выпуск API ключа
+// @Description Выпускает API ключ с набором разрешений. Ключ передается в заголовке X-API-Key и показывается только в этом ответе. Выдать можно только разрешения, которые есть у вас; по ключу действуют лишь те из них, что есть и у роли владельца.
+// @Description Владельцем можно указать только пользователя, у роли которого нет разрешений сверх ваших (требует разрешение api_keys:manage)
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key body models.APIKeyCreateRequest true "Данные ключа"
+// @Success 201 {object} models.APIKeyCreateResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req models.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	ownerID, _ := c.Get("user_id")
+	if req.UserID != nil {
+		ownerID = *req.UserID
+	}
+
+	// Выдать можно только разрешения, которые есть у самого администратора
+	granted, _ := c.Get("permissions")
+	grantedPermissions, _ := granted.([]string)
+	permissions := make([]string, 0, len(req.Permissions))
+	for _, permission := range req.Permissions {
+		permission = strings.TrimSpace(permission)
+		if !containsString(grantedPermissions, permission) {
+			apierror.Respond(c, http.StatusForbidden, apierror.CodeAPIKeyPermissionNotHeld, permission)
+			return
+		}
+		if !containsString(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	var ownerExists bool
+	if err := h.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", ownerID).Scan(&ownerExists); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !ownerExists {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeAPIKeyOwnerNotFound)
+		return
+	}
+
+	// Действия по ключу записываются в журнал от имени владельца: выпустить ключ
+	// на чужую учетную запись можно, только если у нее нет разрешений сверх ваших
+	if currentUserID, _ := c.Get("user_id"); currentUserID != ownerID && !authorizeUserAction(c, h.db, ownerID.(int)) {
+		return
+	}
+
+	key, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	var expiresInDays interface{}
+	if req.ExpiresInDays != nil {
+		expiresInDays = *req.ExpiresInDays
+	}
+
+	var id int
+	err = h.db.QueryRow(`
+		INSERT INTO api_keys (name, prefix, key_hash, user_id, permissions, expires_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP + $6 * INTERVAL '1 day')
+		RETURNING id`,
+		strings.TrimSpace(req.Name), prefix, hash, ownerID, pq.Array(permissions), expiresInDays,
+	).Scan(&id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	apiKey, err := h.getAPIKey(id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIKeyCreateResponse{APIKey: *apiKey, Key: key})
+}
+
+// RevokeAPIKey отзывает API ключ
+// @Summary Отзыв API ключа
+// @Description Отзывает API ключ; запросы с ним сразу перестают приниматься (требует разрешение api_keys:manage)
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID ключа"
+// @Success 200 {object} models.APIKey
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidAPIKeyID)
+		return
+	}
+
+	result, err := h.db.Exec("UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeAPIKeyNotFound)
+		return
+	}
+
+	apiKey, err := h.getAPIKey(id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKey)
+}
+
+// getAPIKey загружает API ключ по ID
+func (h *APIKeyHandler) getAPIKey(id int) (*models.APIKey, error) {
+	return scanAPIKey(h.db.QueryRow(fmt.Sprintf(`
+		SELECT %s
+		FROM api_keys k JOIN users u ON u.id = k.user_id
+		WHERE k.id = $1`, apiKeyColumns), id))
+}
+
+// scanAPIKey читает API ключ из строки результата, выбранной с apiKeyColumns
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(
+		&key.ID, &key.Name, &key.Prefix, &key.UserID, &key.Username, pq.Array(&key.Permissions),
+		&key.ExpiresAt, &key.LastUsedAt, &key.LastUsedIP, &key.RevokedAt, &key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
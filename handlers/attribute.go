@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"api-go/apierror"
+	"api-go/cache"
+	"api-go/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// attributeColumns список колонок для выборки характеристики
+const attributeColumns = `ad.id, ad.code, ad.name, ad.type, ad.unit, ad.is_filterable, ad.sort_order, ad.created_at, ad.updated_at`
+
+// attributeCodePattern допустимый код характеристики: он используется в параметрах фильтра attr.<code>
+var attributeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// AttributeHandler обрабатывает запросы для работы с характеристиками продуктов
+type AttributeHandler struct {
+	db    *sql.DB
+	cache *cache.ProductCache
+}
+
+// NewAttributeHandler создает новый экземпляр AttributeHandler
+func NewAttributeHandler(db *sql.DB, cache *cache.ProductCache) *AttributeHandler {
+	return &AttributeHandler{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// GetAttributes возвращает все характеристики
+// @Summary Список характеристик
+// @Description Возвращает все характеристики продуктов
+// @Tags attributes
+// @Produce json
+// @Success 200 {array} models.AttributeDefinition
+// @Failure 500 {object} apierror.Problem
+// @Router /attributes [get]
+func (h *AttributeHandler) GetAttributes(c *gin.Context) {
+	attributes, err := queryAttributes(h.db, fmt.Sprintf("SELECT %s FROM attribute_definitions ad ORDER BY ad.sort_order, ad.name", attributeColumns))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attributes)
+}
+
+// GetCategoryAttributes возвращает характеристики категории
+// @Summary Характеристики категории
+// @Description Возвращает характеристики, которые задаются продуктам категории
+// @Tags attributes
+// @Produce json
+// @Param id path int true "ID категории"
+// @Success 200 {array} models.AttributeDefinition
+// @Failure 400 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /categories/{id}/attributes [get]
+func (h *AttributeHandler) GetCategoryAttributes(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidCategoryID)
+		return
+	}
+
+	if !h.categoryExists(c, categoryID) {
+		return
+	}
+
+	attributes, err := getCategoryAttributes(h.db, categoryID, false)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attributes)
+}
+
+// CreateAttribute создает характеристику
+// @Summary Создание характеристики
+// @Description Создает характеристику продуктов (требует разрешение attributes:manage)
+// @Tags attributes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param attribute body models.AttributeCreateRequest true "Данные характеристики" example({"code":"screen_size","name":"Диагональ экрана","type":"number","unit":"дюйм"})
+// @Success 201 {object} models.AttributeDefinition
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/attributes [post]
+func (h *AttributeHandler) CreateAttribute(c *gin.Context) {
+	var req models.AttributeCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	if !attributeCodePattern.MatchString(req.Code) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidAttributeCode)
+		return
+	}
+
+	isFilterable := true
+	if req.IsFilterable != nil {
+		isFilterable = *req.IsFilterable
+	}
+
+	var attributeID int
+	err := h.db.QueryRow(`
+		INSERT INTO attribute_definitions (code, name, type, unit, is_filterable, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING id`,
+		req.Code, req.Name, req.Type, req.Unit, isFilterable, req.SortOrder,
+	).Scan(&attributeID)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeAttributeConflict)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	attribute, err := getAttribute(h.db, attributeID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attribute)
+}
+
+// UpdateAttribute изменяет характеристику
+// @Summary Изменение характеристики
+// @Description Изменяет название, единицу измерения и участие в фильтрах; код и тип не меняются (требует разрешение attributes:manage)
+// @Tags attributes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID характеристики"
+// @Param attribute body models.AttributeUpdateRequest true "Данные для обновления" example({"name":"Диагональ","is_filterable":true})
+// @Success 200 {object} models.AttributeDefinition
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/attributes/{id} [put]
+func (h *AttributeHandler) UpdateAttribute(c *gin.Context) {
+	attributeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidAttributeID)
+		return
+	}
+
+	var req models.AttributeUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	// Формируем SQL запрос для обновления
+	query := "UPDATE attribute_definitions SET updated_at = $1"
+	args := []interface{}{time.Now()}
+	argIndex := 2
+
+	if req.Name != nil {
+		query += fmt.Sprintf(", name = $%d", argIndex)
+		args = append(args, *req.Name)
+		argIndex++
+	}
+
+	if req.Unit != nil {
+		query += fmt.Sprintf(", unit = $%d", argIndex)
+		args = append(args, *req.Unit)
+		argIndex++
+	}
+
+	if req.IsFilterable != nil {
+		query += fmt.Sprintf(", is_filterable = $%d", argIndex)
+		args = append(args, *req.IsFilterable)
+		argIndex++
+	}
+
+	if req.SortOrder != nil {
+		query += fmt.Sprintf(", sort_order = $%d", argIndex)
+		args = append(args, *req.SortOrder)
+		argIndex++
+	}
+
+	query += " WHERE id = $" + strconv.Itoa(argIndex)
+	args = append(args, attributeID)
+
+	result, err := h.db.Exec(query, args...)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeAttributeNotFound)
+		return
+	}
+
+	// Название и единица измерения показываются в карточках продуктов
+	h.invalidateProducts(c)
+
+	attribute, err := getAttribute(h.db, attributeID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attribute)
+}
+
+// DeleteAttribute удаляет характеристику
+// @Summary Удаление характеристики
+// @Description Удаляет характеристику вместе с ее значениями у продуктов (требует разрешение attributes:manage)
+// @Tags attributes
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID характеристики"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/attributes/{id} [delete]
+func (h *AttributeHandler) DeleteAttribute(c *gin.Context) {
+	attributeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidAttributeID)
+		return
+	}
+
+	result, err := h.db.Exec("DELETE FROM attribute_definitions WHERE id = $1", attributeID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeAttributeNotFound)
+		return
+	}
+
+	h.invalidateProducts(c)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Характеристика успешно удалена"})
+}
+
+// SetCategoryAttributes задает набор характеристик категории
+// @Summary Характеристики категории
+// @Description Заменяет набор характеристик категории. Значения исключенных характеристик у продуктов сохраняются (требует разрешение attributes:manage)
+// @Tags attributes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID категории"
+// @Param attributes body models.CategoryAttributesRequest true "ID характеристик" example({"attribute_ids":[1,2]})
+// @Success 200 {array} models.AttributeDefinition
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/categories/{id}/attributes [put]
+func (h *AttributeHandler) SetCategoryAttributes(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidCategoryID)
+		return
+	}
+
+	var req models.CategoryAttributesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	if !h.categoryExists(c, categoryID) {
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM category_attributes WHERE category_id = $1", categoryID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO category_attributes (category_id, attribute_id)
+		SELECT $1, ad.id FROM attribute_definitions ad WHERE ad.id = ANY($2)`,
+		categoryID, pq.Array(req.AttributeIDs))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if rows, _ := result.RowsAffected(); int(rows) != len(uniqueInts(req.AttributeIDs)) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeAttributeNotFound)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	attributes, err := getCategoryAttributes(h.db, categoryID, false)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attributes)
+}
+
+// categoryExists проверяет, что категория существует.
+// Если ее нет или запрос не удался, отправляет ошибку и возвращает false.
+func (h *AttributeHandler) categoryExists(c *gin.Context, categoryID int) bool {
+	var exists bool
+	if err := h.db.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", categoryID).Scan(&exists); err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+	if !exists {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeCategoryNotFound)
+		return false
+	}
+	return true
+}
+
+// invalidateProducts сбрасывает кэш продуктов: характеристики входят в карточки продуктов
+func (h *AttributeHandler) invalidateProducts(c *gin.Context) {
+	if h.cache != nil {
+		h.cache.InvalidateAllProductCache(c.Request.Context())
+	}
+}
+
+// getAttribute получает характеристику по ID
+func getAttribute(db dbExecutor, attributeID int) (*models.AttributeDefinition, error) {
+	return scanAttribute(db.QueryRow(fmt.Sprintf("SELECT %s FROM attribute_definitions ad WHERE ad.id = $1", attributeColumns), attributeID))
+}
+
+// getCategoryAttributes получает характеристики категории, при filterableOnly - только участвующие в фильтрах
+func getCategoryAttributes(db *sql.DB, categoryID int, filterableOnly bool) ([]models.AttributeDefinition, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM attribute_definitions ad
+		JOIN category_attributes ca ON ca.attribute_id = ad.id
+		WHERE ca.category_id = $1`, attributeColumns)
+	if filterableOnly {
+		query += " AND ad.is_filterable = true"
+	}
+	query += " ORDER BY ad.sort_order, ad.name"
+	return queryAttributes(db, query, categoryID)
+}
+
+// queryAttributes выполняет запрос, возвращающий колонки attributeColumns
+func queryAttributes(db *sql.DB, query string, args ...interface{}) ([]models.AttributeDefinition, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []models.AttributeDefinition{}
+	for rows.Next() {
+		attribute, err := scanAttribute(rows)
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, *attribute)
+	}
+	return attributes, rows.Err()
+}
+
+// scanAttribute читает характеристику из строки результата с колонками attributeColumns
+func scanAttribute(row rowScanner) (*models.AttributeDefinition, error) {
+	var attribute models.AttributeDefinition
+	err := row.Scan(
+		&attribute.ID, &attribute.Code, &attribute.Name, &attribute.Type, &attribute.Unit,
+		&attribute.IsFilterable, &attribute.SortOrder, &attribute.CreatedAt, &attribute.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &attribute, nil
+}
+
+// uniqueInts возвращает значения без повторов
+func uniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	unique := make([]int, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-go/apierror"
+	"api-go/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditColumns список колонок для выборки записи журнала
+const auditColumns = `id, actor_id, actor_username, api_key_id, action, entity_type, entity_id,
+	changes, COALESCE(ip_address, ''), COALESCE(request_id, ''), created_at`
+
+// auditExportLimit ограничивает размер выгрузки журнала в CSV
+const auditExportLimit = 100000
+
+// auditIgnoredFields поля, которые меняются при каждом изменении и не попадают в журнал
+var auditIgnoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// AuditHandler обрабатывает запросы к журналу действий администраторов
+type AuditHandler struct {
+	db *sql.DB
+}
+
+// NewAuditHandler создает новый экземпляр AuditHandler
+func NewAuditHandler(db *sql.DB) *AuditHandler {
+	return &AuditHandler{
+		db: db,
+	}
+}
+
+// GetAuditLog возвращает страницу журнала действий
+// @Summary Журнал действий
+// @Description Возвращает изменения продуктов и заказов, новые записи первыми (требует разрешение audit:read)
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(50)
+// @Param actor_id query int false "Фильтр по пользователю, выполнившему действие"
+// @Param action query string false "Фильтр по действию" Enums(product.create, product.update, product.delete, product.restore, product.purge, product.rollback, sale_price.create, sale_price.delete, campaign.create, campaign.update, campaign.delete, variant.create, variant.update, variant.delete, image.create, image.update, image.delete, order.update)
+// @Param entity_type query string false "Фильтр по типу объекта" Enums(product, product_variant, product_image, product_sale_price, sale_campaign, order)
+// @Param entity_id query int false "Фильтр по ID объекта"
+// @Param from query string false "Не раньше (RFC 3339 или YYYY-MM-DD)"
+// @Param to query string false "Раньше (RFC 3339 или YYYY-MM-DD)"
+// @Success 200 {object} models.AuditLogResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/audit-log [get]
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+	offset := (page - 1) * limit
+
+	whereClause, args, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+	argIndex := len(args) + 1
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM audit_log %s", whereClause)
+	if err := h.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM audit_log %s
+		ORDER BY id DESC
+		LIMIT $%d OFFSET $%d
+	`, auditColumns, whereClause, argIndex, argIndex+1)
+
+	args = append(args, limit, offset)
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditLogResponse{
+		Entries: entries,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	})
+}
+
+// ExportAuditLog выгружает журнал действий в CSV
+// @Summary Выгрузка журнала действий в CSV
+// @Description Выгружает записи журнала с теми же фильтрами, что и GET /admin/audit-log, не более 100000 строк (требует разрешение audit:read)
+// @Tags audit
+// @Produce text/csv
+// @Security BearerAuth
+// @Param actor_id query int false "Фильтр по пользователю, выполнившему действие"
+// @Param action query string false "Фильтр по действию" Enums(product.create, product.update, product.delete, product.restore, product.purge, product.rollback, sale_price.create, sale_price.delete, campaign.create, campaign.update, campaign.delete, variant.create, variant.update, variant.delete, image.create, image.update, image.delete, order.update)
+// @Param entity_type query string false "Фильтр по типу объекта" Enums(product, product_variant, product_image, product_sale_price, sale_campaign, order)
+// @Param entity_id query int false "Фильтр по ID объекта"
+// @Param from query string false "Не раньше (RFC 3339 или YYYY-MM-DD)"
+// @Param to query string false "Раньше (RFC 3339 или YYYY-MM-DD)"
+// @Success 200 {file} file
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/audit-log/export [get]
+func (h *AuditHandler) ExportAuditLog(c *gin.Context) {
+	whereClause, args, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM audit_log %s
+		ORDER BY id DESC
+		LIMIT %d
+	`, auditColumns, whereClause, auditExportLimit)
+
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("audit_log_%s.csv", time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"id", "created_at", "actor_id", "actor_username", "api_key_id",
+		"action", "entity_type", "entity_id", "changes", "ip_address", "request_id",
+	})
+
+	// Заголовки уже отправлены: при ошибке чтения выгрузка обрывается
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			c.Error(err)
+			break
+		}
+
+		changes, _ := json.Marshal(entry.Changes)
+		w.Write([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			formatOptionalInt(entry.ActorID),
+			csvSafe(entry.ActorUsername),
+			formatOptionalInt(entry.APIKeyID),
+			entry.Action,
+			entry.EntityType,
+			strconv.Itoa(entry.EntityID),
+			csvSafe(string(changes)),
+			entry.IPAddress,
+			csvSafe(entry.RequestID),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		c.Error(err)
+	}
+	w.Flush()
+}
+
+// auditFilter строит условие выборки журнала по параметрам запроса.
+// При неверном параметре отправляет ошибку и возвращает ok = false.
+func auditFilter(c *gin.Context) (whereClause string, args []interface{}, ok bool) {
+	whereClause = "WHERE 1=1"
+	argIndex := 1
+
+	for _, param := range []string{"actor_id", "entity_id"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, param)
+			return "", nil, false
+		}
+		whereClause += fmt.Sprintf(" AND %s = $%d", param, argIndex)
+		args = append(args, id)
+		argIndex++
+	}
+
+	for _, param := range []string{"action", "entity_type"} {
+		if value := c.Query(param); value != "" {
+			whereClause += fmt.Sprintf(" AND %s = $%d", param, argIndex)
+			args = append(args, value)
+			argIndex++
+		}
+	}
+
+	for _, bound := range []struct{ param, op string }{{"from", ">="}, {"to", "<"}} {
+		value := c.Query(bound.param)
+		if value == "" {
+			continue
+		}
+		t, err := parseAuditTime(value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, bound.param)
+			return "", nil, false
+		}
+		whereClause += fmt.Sprintf(" AND created_at %s $%d", bound.op, argIndex)
+		args = append(args, t)
+		argIndex++
+	}
+
+	return whereClause, args, true
+}
+
+// parseAuditTime разбирает время в формате RFC 3339 или дату YYYY-MM-DD (начало суток UTC)
+func parseAuditTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// scanAuditEntry читает запись журнала из строки результата
+func scanAuditEntry(rows *sql.Rows) (models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var actorID, apiKeyID sql.NullInt64
+	var changes []byte
+	err := rows.Scan(
+		&entry.ID, &actorID, &entry.ActorUsername, &apiKeyID, &entry.Action, &entry.EntityType, &entry.EntityID,
+		&changes, &entry.IPAddress, &entry.RequestID, &entry.CreatedAt,
+	)
+	if err != nil {
+		return entry, err
+	}
+
+	if actorID.Valid {
+		id := int(actorID.Int64)
+		entry.ActorID = &id
+	}
+	if apiKeyID.Valid {
+		id := int(apiKeyID.Int64)
+		entry.APIKeyID = &id
+	}
+	if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+// recordAudit записывает действие текущего пользователя в журнал в транзакции изменения:
+// если запись не удалась, изменение откатывается вместе с ней.
+// before и after - состояние объекта до и после действия (nil при создании и удалении).
+func recordAudit(tx dbExecutor, c *gin.Context, action, entityType string, entityID int, before, after interface{}) error {
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	var actorID, apiKeyID sql.NullInt64
+	if id, ok := c.Get("user_id"); ok {
+		actorID = sql.NullInt64{Int64: int64(id.(int)), Valid: true}
+	}
+	if id, ok := c.Get("api_key_id"); ok {
+		apiKeyID = sql.NullInt64{Int64: int64(id.(int)), Valid: true}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO audit_log (actor_id, actor_username, api_key_id, action, entity_type, entity_id, changes, ip_address, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		actorID, c.GetString("username"), apiKeyID, action, entityType, entityID, data, c.ClientIP(), c.GetString("request_id"))
+	return err
+}
+
+// auditDiff сравнивает JSON представления объекта до и после изменения
+// и возвращает только отличающиеся поля
+func auditDiff(before, after interface{}) (map[string]models.AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.AuditChange{}
+	for name, value := range beforeFields {
+		if newValue, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, newValue) {
+			changes[name] = models.AuditChange{Before: value, After: newValue}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = models.AuditChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+// auditFields возвращает поля JSON представления объекта без служебных полей
+func auditFields(v interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if v == nil {
+		return fields, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for name := range auditIgnoredFields {
+		delete(fields, name)
+	}
+	return fields, nil
+}
+
+// formatOptionalInt форматирует необязательное число для CSV
+func formatOptionalInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// csvSafe защищает от выполнения формул при открытии выгрузки в табличном редакторе
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -2,9 +2,16 @@ package handlers
 
 import (
 	"database/sql"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"api-go/apierror"
 	"api-go/config"
+	"api-go/jwtkeys"
+	"api-go/mailer"
 	"api-go/models"
 	"api-go/utils"
 
@@ -13,140 +20,560 @@ import (
 
 // AuthHandler обрабатывает запросы аутентификации
 type AuthHandler struct {
-	db  *sql.DB
-	cfg *config.Config
+	db     *sql.DB
+	cfg    *config.Config
+	mailer mailer.Mailer
+	keys   *jwtkeys.KeySet
 }
 
 // NewAuthHandler создает новый экземпляр AuthHandler
-func NewAuthHandler(db *sql.DB, cfg *config.Config) *AuthHandler {
+func NewAuthHandler(db *sql.DB, cfg *config.Config, m mailer.Mailer, keys *jwtkeys.KeySet) *AuthHandler {
 	return &AuthHandler{
-		db:  db,
-		cfg: cfg,
+		db:     db,
+		cfg:    cfg,
+		mailer: m,
+		keys:   keys,
 	}
 }
 
+// registerMessage одинаковый ответ на регистрацию,
+// чтобы по нему нельзя было узнать, зарегистрирован ли email
+const registerMessage = "На указанный email отправлено письмо. Следуйте инструкциям в нем, чтобы завершить регистрацию"
+
+// dummyPasswordHash проверяется вместо хеша пароля, если email не найден:
+// bcrypt с тем же cost выравнивает время ответа для существующих и несуществующих email
+const dummyPasswordHash = "$2a$12$2u7MZXsdN1PXMx/bZ/urI.WrJvEtNTszTHeisVAntpdj0gHtX73QW"
+
 // Register обрабатывает регистрацию нового пользователя
 // @Summary Регистрация пользователя
-// @Description Создает нового пользователя в системе
+// @Description Создает нового пользователя в системе и отправляет письмо для подтверждения email. Ответ не зависит от того, зарегистрирован ли email: владельцу существующей учетной записи отправляется письмо о попытке регистрации
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param user body models.UserCreateRequest true "Данные пользователя"
-// @Success 201 {object} models.UserResponse
-// @Failure 400 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
 // @Router /auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.UserCreateRequest
 
 	// Валидируем входящие данные
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные: " + err.Error()})
+		apierror.RespondValidation(c, err)
 		return
 	}
 
-	// Проверяем, существует ли пользователь с таким email
-	var existingUser models.User
-	err := h.db.QueryRow("SELECT id FROM users WHERE email = $1", req.Email).Scan(&existingUser.ID)
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Пользователь с таким email уже существует"})
+	// Хешируем пароль до проверки email, чтобы время ответа не выдавало занятый адрес
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
-	// Хешируем пароль
-	hashedPassword, err := utils.HashPassword(req.Password)
+	// Email уже зарегистрирован: сообщаем об этом только владельцу адреса
+	taken, err := h.emailRegistered(req.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка хеширования пароля"})
+		apierror.Internal(c, err)
+		return
+	}
+	if taken {
+		sendMailAsync(h.mailer, accountExistsMessage(h.cfg, req.Email))
+		c.JSON(http.StatusAccepted, gin.H{"message": registerMessage})
 		return
 	}
 
 	// Создаем пользователя
-	var user models.User
+	var userID int
 	err = h.db.QueryRow(`
 		INSERT INTO users (username, email, password, role) 
 		VALUES ($1, $2, $3, $4) 
-		RETURNING id, username, email, role, created_at, updated_at`,
-		req.Username, req.Email, hashedPassword, "user",
-	).Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.CreatedAt, &user.UpdatedAt)
-
+		RETURNING id`,
+		req.Username, req.Email, hashedPassword, models.RoleUser,
+	).Scan(&userID)
+	if isUniqueViolation(err) {
+		// Email могли зарегистрировать параллельно, иначе занято имя пользователя
+		if taken, checkErr := h.emailRegistered(req.Email); checkErr == nil && taken {
+			sendMailAsync(h.mailer, accountExistsMessage(h.cfg, req.Email))
+			c.JSON(http.StatusAccepted, gin.H{"message": registerMessage})
+			return
+		}
+		apierror.Respond(c, http.StatusConflict, apierror.CodeUsernameTaken)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка создания пользователя"})
+		apierror.Internal(c, err)
 		return
 	}
 
-	// Формируем ответ
-	response := models.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	// Письмо с подтверждением; ошибка отправки не мешает регистрации
+	token, err := createUserToken(h.db, userID, models.TokenPurposeEmailVerify, req.Email, emailVerifyTokenTTL)
+	if err != nil {
+		log.Printf("Ошибка создания токена подтверждения email: %v", err)
+	} else {
+		sendMailAsync(h.mailer, emailVerifyMessage(h.cfg, req.Email, token))
 	}
 
-	c.JSON(http.StatusCreated, response)
+	c.JSON(http.StatusAccepted, gin.H{"message": registerMessage})
+}
+
+// emailRegistered проверяет, есть ли пользователь с таким email
+func (h *AuthHandler) emailRegistered(email string) (bool, error) {
+	var exists bool
+	err := h.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", email).Scan(&exists)
+	return exists, err
 }
 
 // Login обрабатывает вход пользователя
 // @Summary Вход пользователя
-// @Description Аутентифицирует пользователя и возвращает JWT токен
+// @Description Аутентифицирует пользователя и возвращает JWT токен. Если включена 2FA, возвращает 202 и токен для POST /auth/login/2fa. После серии неудачных попыток вход временно блокируется
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param credentials body models.UserLoginRequest true "Данные для входа"
 // @Success 200 {object} models.LoginResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Success 202 {object} models.TwoFactorChallengeResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 423 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.UserLoginRequest
 
 	// Валидируем входящие данные
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные: " + err.Error()})
+		apierror.RespondValidation(c, err)
 		return
 	}
 
 	// Ищем пользователя в базе
-	var user models.User
+	var userID, failedAttempts int
+	var passwordHash string
+	var lockedFor sql.NullFloat64
 	err := h.db.QueryRow(`
-		SELECT id, username, email, password, role, created_at, updated_at 
-		FROM users WHERE email = $1`,
-		req.Email,
-	).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt)
-
+		SELECT id, password, failed_login_attempts, EXTRACT(EPOCH FROM (locked_until - CURRENT_TIMESTAMP))
+		FROM users WHERE email = $1`, req.Email,
+	).Scan(&userID, &passwordHash, &failedAttempts, &lockedFor)
+	if err == sql.ErrNoRows {
+		utils.CheckPasswordHash(req.Password, dummyPasswordHash)
+		recordLoginEvent(h.db, c, 0, req.Email, models.LoginOutcomeUnknownEmail)
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidCredentials)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный email или пароль"})
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Пока действует блокировка, пароль не проверяется
+	if lockedFor.Valid && lockedFor.Float64 > 0 {
+		recordLoginEvent(h.db, c, userID, req.Email, models.LoginOutcomeLocked)
+		respondLocked(c, h.cfg.Lockout, failedAttempts, lockedFor.Float64)
 		return
 	}
 
 	// Проверяем пароль
-	if !utils.CheckPasswordHash(req.Password, user.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный email или пароль"})
+	if !utils.CheckPasswordHash(req.Password, passwordHash) {
+		if err := registerFailedLogin(h.db, h.cfg.Lockout, userID); err != nil {
+			log.Printf("Ошибка учета неудачной попытки входа: %v", err)
+		}
+		recordLoginEvent(h.db, c, userID, req.Email, models.LoginOutcomeInvalidPassword)
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidCredentials)
+		return
+	}
+
+	user, err := getUserByID(h.db, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Отключенные пользователи не могут войти
+	if !user.IsActive {
+		recordLoginEvent(h.db, c, userID, req.Email, models.LoginOutcomeDisabled)
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeAccountDisabled)
+		return
+	}
+
+	// С включенной 2FA JWT выдается только после проверки кода
+	if user.TOTPEnabled {
+		h.respondTwoFactorChallenge(c, user, req.Email)
+		return
+	}
+
+	h.completeLogin(c, user, req.Email)
+}
+
+// LoginTwoFactor завершает вход с 2FA
+// @Summary Вход: код 2FA
+// @Description Второй шаг входа для пользователей с 2FA: проверяет код TOTP или код восстановления и возвращает JWT токен
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorLoginRequest true "Токен первого шага и код"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 423 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
+// @Router /auth/login/2fa [post]
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	userID, _, err := lookupUserToken(h.db, models.TokenPurposeLogin2FA, req.ChallengeToken)
+	if err == errInvalidToken {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeTwoFactorChallengeExpired)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	user, err := getUserByID(h.db, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Неверные коды 2FA учитываются так же, как неверные пароли
+	var lockedFor sql.NullFloat64
+	err = h.db.QueryRow(
+		"SELECT EXTRACT(EPOCH FROM (locked_until - CURRENT_TIMESTAMP)) FROM users WHERE id = $1", userID,
+	).Scan(&lockedFor)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if lockedFor.Valid && lockedFor.Float64 > 0 {
+		recordLoginEvent(h.db, c, userID, user.Email, models.LoginOutcomeLocked)
+		respondLocked(c, h.cfg.Lockout, user.FailedLoginCount, lockedFor.Float64)
+		return
+	}
+
+	ok, err := verifyTwoFactorCode(h.db, userID, req.Code)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !ok {
+		if err := registerFailedLogin(h.db, h.cfg.Lockout, userID); err != nil {
+			log.Printf("Ошибка учета неудачной попытки входа: %v", err)
+		}
+		recordLoginEvent(h.db, c, userID, user.Email, models.LoginOutcomeInvalidTwoFactor)
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidTwoFactorCode)
+		return
+	}
+
+	// Токен первого шага одноразовый
+	if _, _, err := consumeUserToken(h.db, models.TokenPurposeLogin2FA, req.ChallengeToken); err != nil {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeTwoFactorChallengeExpired)
 		return
 	}
 
+	if !user.IsActive {
+		recordLoginEvent(h.db, c, userID, user.Email, models.LoginOutcomeDisabled)
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeAccountDisabled)
+		return
+	}
+
+	h.completeLogin(c, user, user.Email)
+}
+
+// respondTwoFactorChallenge выдает одноразовый токен для второго шага входа
+func (h *AuthHandler) respondTwoFactorChallenge(c *gin.Context, user *models.User, email string) {
+	ttl := time.Duration(h.cfg.TwoFactor.ChallengeMinutes) * time.Minute
+	challenge, err := createUserToken(h.db, user.ID, models.TokenPurposeLogin2FA, "", ttl)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	recordLoginEvent(h.db, c, user.ID, email, models.LoginOutcomeTwoFactorRequired)
+	c.JSON(http.StatusAccepted, models.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge,
+		ExpiresIn:         int(ttl.Seconds()),
+	})
+}
+
+// completeLogin выдает JWT токен и фиксирует успешный вход
+func (h *AuthHandler) completeLogin(c *gin.Context, user *models.User, email string) {
 	// Генерируем JWT токен
-	token, err := utils.GenerateToken(user.ID, user.Username, user.Role, h.cfg.JWT.Secret, h.cfg.JWT.ExpiryHours)
+	token, err := utils.GenerateToken(h.keys, user.ID, user.Username, user.Role, user.TokenVersion, h.cfg.JWT.ExpiryHours)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации токена"})
+		apierror.Internal(c, err)
 		return
 	}
 
-	// Формируем ответ
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	// Запоминаем время входа и сбрасываем счетчик неудачных попыток
+	now := time.Now()
+	_, err = h.db.Exec(`
+		UPDATE users SET last_login_at = $1, failed_login_attempts = 0, locked_until = NULL
+		WHERE id = $2`, now, user.ID)
+	if err == nil {
+		user.LastLoginAt = &now
+		user.FailedLoginCount = 0
+		user.LockedUntil = nil
 	}
+	recordLoginEvent(h.db, c, user.ID, email, models.LoginOutcomeSuccess)
 
 	response := models.LoginResponse{
-		User:  userResponse,
+		User:  user.ToResponse(),
 		Token: token,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// registerFailedLogin увеличивает счетчик неудачных попыток и при необходимости блокирует вход.
+// Счетчик, оставшийся после истекшей полной блокировки, начинается заново.
+func registerFailedLogin(db dbExecutor, cfg config.LockoutConfig, userID int) error {
+	var attempts int
+	err := db.QueryRow(`
+		UPDATE users
+		SET failed_login_attempts = CASE WHEN failed_login_attempts >= $2 THEN 1 ELSE failed_login_attempts + 1 END
+		WHERE id = $1
+		RETURNING failed_login_attempts`,
+		userID, cfg.MaxAttempts,
+	).Scan(&attempts)
+	if err != nil {
+		return err
+	}
+
+	delay := lockoutDelay(cfg, attempts)
+	if delay <= 0 {
+		return nil
+	}
+
+	_, err = db.Exec(`
+		UPDATE users SET locked_until = CURRENT_TIMESTAMP + $2 * INTERVAL '1 second'
+		WHERE id = $1`, userID, int(delay.Seconds()))
+	return err
+}
+
+// respondIfLocked отвечает 423 или 429, если вход пользователя заблокирован после неудачных попыток.
+// Неверные пароли и коды 2FA в учетной записи (смена пароля, отключение 2FA) учитываются так же,
+// как при входе: иначе украденным токеном можно подбирать их без ограничений.
+// Возвращает true, если ответ уже отправлен.
+func respondIfLocked(c *gin.Context, db dbExecutor, cfg config.LockoutConfig, userID int) bool {
+	var failedAttempts int
+	var lockedFor sql.NullFloat64
+	err := db.QueryRow(`
+		SELECT failed_login_attempts, EXTRACT(EPOCH FROM (locked_until - CURRENT_TIMESTAMP))
+		FROM users WHERE id = $1`, userID,
+	).Scan(&failedAttempts, &lockedFor)
+	if err != nil {
+		apierror.Internal(c, err)
+		return true
+	}
+	if lockedFor.Valid && lockedFor.Float64 > 0 {
+		respondLocked(c, cfg, failedAttempts, lockedFor.Float64)
+		return true
+	}
+	return false
+}
+
+// respondLocked отвечает на попытку входа во время блокировки
+func respondLocked(c *gin.Context, cfg config.LockoutConfig, failedAttempts int, seconds float64) {
+	retryAfter := int(math.Ceil(seconds))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	if failedAttempts >= cfg.MaxAttempts {
+		apierror.Respond(c, http.StatusLocked, apierror.CodeAccountLocked, int(math.Ceil(seconds/60)))
+		return
+	}
+
+	apierror.Respond(c, http.StatusTooManyRequests, apierror.CodeLoginThrottled, retryAfter)
+}
+
+// lockoutDelay возвращает время блокировки входа после attempts неудачных попыток подряд
+func lockoutDelay(cfg config.LockoutConfig, attempts int) time.Duration {
+	lockout := time.Duration(cfg.LockoutMinutes) * time.Minute
+	if attempts >= cfg.MaxAttempts {
+		return lockout
+	}
+	if attempts <= cfg.FreeAttempts {
+		return 0
+	}
+
+	delay := time.Duration(cfg.BaseDelay) * time.Second
+	for i := cfg.FreeAttempts + 1; i < attempts && delay < lockout; i++ {
+		delay *= 2
+	}
+	if delay > lockout {
+		delay = lockout
+	}
+	return delay
+}
+
+// VerifyEmail подтверждает email по токену из письма
+// @Summary Подтверждение email
+// @Description Подтверждает email пользователя по одноразовому токену из письма
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body models.TokenRequest true "Токен из письма"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	userID, email, err := consumeUserToken(tx, models.TokenPurposeEmailVerify, req.Token)
+	if err == errInvalidToken {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidLink)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Токен выдан на конкретный адрес: после смены email он недействителен
+	result, err := tx.Exec(`
+		UPDATE users SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND email = $2`, userID, email)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidLink)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email подтвержден"})
+}
+
+// forgotPasswordMessage одинаковый ответ на запрос сброса пароля,
+// чтобы по нему нельзя было узнать, зарегистрирован ли email
+const forgotPasswordMessage = "Если email зарегистрирован, на него отправлено письмо со ссылкой для сброса пароля"
+
+// ForgotPassword отправляет письмо для сброса пароля
+// @Summary Запрос сброса пароля
+// @Description Отправляет письмо с токеном для сброса пароля. Ответ не зависит от того, существует ли пользователь
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param email body models.ForgotPasswordRequest true "Email пользователя"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
+// @Router /auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	var userID int
+	err := h.db.QueryRow(
+		"SELECT id FROM users WHERE email = $1 AND COALESCE(is_active, true)", req.Email,
+	).Scan(&userID)
+	switch {
+	case err == sql.ErrNoRows:
+		// Не раскрываем, что пользователя нет
+	case err != nil:
+		log.Printf("Ошибка поиска пользователя для сброса пароля: %v", err)
+	default:
+		token, err := createUserToken(h.db, userID, models.TokenPurposePasswordReset, "", passwordResetTokenTTL)
+		if err != nil {
+			log.Printf("Ошибка создания токена сброса пароля: %v", err)
+			break
+		}
+		sendMailAsync(h.mailer, passwordResetMessage(h.cfg, req.Email, token))
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": forgotPasswordMessage})
+}
+
+// ResetPassword устанавливает новый пароль по токену из письма
+// @Summary Сброс пароля
+// @Description Устанавливает новый пароль по одноразовому токену. Все выданные ранее сессии становятся недействительными
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param reset body models.ResetPasswordRequest true "Токен из письма и новый пароль"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
+// @Router /auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	userID, _, err := consumeUserToken(tx, models.TokenPurposePasswordReset, req.Token)
+	if err == errInvalidToken {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidLink)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Письмо пришло на email пользователя, поэтому адрес считается подтвержденным,
+	// а блокировка входа снимается
+	_, err = tx.Exec(`
+		UPDATE users
+		SET password = $1, must_change_password = false, token_version = token_version + 1,
+		    failed_login_attempts = 0, locked_until = NULL,
+		    email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2`, hashedPassword, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Пароль успешно изменен. Войдите с новым паролем"})
+}
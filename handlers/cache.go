@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"api-go/apierror"
 	"api-go/cache"
 
 	"github.com/gin-gonic/gin"
@@ -27,7 +28,7 @@ func NewCacheHandler(productCache *cache.ProductCache) *CacheHandler {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]string
+// @Failure 401 {object} apierror.Problem
 // @Router /cache/stats [get]
 func (h *CacheHandler) GetCacheStats(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -41,18 +42,19 @@ func (h *CacheHandler) GetCacheStats(c *gin.Context) {
 
 // InvalidateCache инвалидирует весь кэш продуктов
 // @Summary Инвалидация кэша
-// @Description Инвалидирует весь кэш продуктов (требует аутентификации)
+// @Description Инвалидирует весь кэш продуктов (требует разрешение cache:manage)
 // @Tags cache
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
 // @Router /cache/invalidate [post]
 func (h *CacheHandler) InvalidateCache(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	if err := h.cache.InvalidateAllProductCache(ctx); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка инвалидации кэша: " + err.Error()})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"api-go/apierror"
+	"api-go/cache"
+	"api-go/models"
+	"api-go/pricing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// campaignColumns список колонок для выборки акции функцией scanCampaign
+const campaignColumns = `id, name, category_id, discount_percent, starts_at, ends_at, is_active, created_by, created_at, updated_at`
+
+// CampaignHandler обрабатывает запросы для работы с акциями на категории
+type CampaignHandler struct {
+	db    *sql.DB
+	cache *cache.ProductCache
+}
+
+// NewCampaignHandler создает новый экземпляр CampaignHandler
+func NewCampaignHandler(db *sql.DB, cache *cache.ProductCache) *CampaignHandler {
+	return &CampaignHandler{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// GetCampaigns возвращает акции
+// @Summary Список акций
+// @Description Возвращает акции на категории, последние по началу первыми (требует разрешение campaigns:manage)
+// @Tags campaigns
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param category_id query int false "Фильтр по ID категории"
+// @Success 200 {array} models.SaleCampaign
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/campaigns [get]
+func (h *CampaignHandler) GetCampaigns(c *gin.Context) {
+	query := "SELECT " + campaignColumns + " FROM sale_campaigns"
+	args := []interface{}{}
+	if value := c.Query("category_id"); value != "" {
+		categoryID, err := strconv.Atoi(value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, "category_id")
+			return
+		}
+		query += " WHERE category_id = $1"
+		args = append(args, categoryID)
+	}
+
+	rows, err := h.db.Query(query+" ORDER BY starts_at DESC, id DESC", args...)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	campaigns := []models.SaleCampaign{}
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		campaigns = append(campaigns, *campaign)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, campaigns)
+}
+
+// CreateCampaign создает акцию на категорию
+// @Summary Создание акции
+// @Description Создает скидку в процентах на все продукты категории в период [starts_at, ends_at).
+// @Description Цены меняются автоматически в начале и в конце периода; при пересечении с другими акциями
+// @Description и запланированными ценами действует наименьшая. Требует разрешение campaigns:manage.
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param request body models.SaleCampaignCreateRequest true "Данные акции" example({"name":"Черная пятница","category_id":1,"discount_percent":15,"starts_at":"2026-11-27T00:00:00Z","ends_at":"2026-12-01T00:00:00Z"})
+// @Success 201 {object} models.SaleCampaign
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/campaigns [post]
+func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
+	var req models.SaleCampaignCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	campaign := models.SaleCampaign{
+		Name:            req.Name,
+		CategoryID:      req.CategoryID,
+		DiscountPercent: req.DiscountPercent,
+		StartsAt:        req.StartsAt.UTC(),
+		EndsAt:          req.EndsAt.UTC(),
+		IsActive:        true,
+	}
+	if req.IsActive != nil {
+		campaign.IsActive = *req.IsActive
+	}
+	if !campaign.EndsAt.After(campaign.StartsAt) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidSalePeriod)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	if !h.categoryExists(c, tx, campaign.CategoryID) {
+		return
+	}
+
+	var createdBy sql.NullInt64
+	if userID, ok := c.Get("user_id"); ok {
+		createdBy = sql.NullInt64{Int64: int64(userID.(int)), Valid: true}
+	}
+
+	created, err := scanCampaign(tx.QueryRow(`
+		INSERT INTO sale_campaigns (name, category_id, discount_percent, starts_at, ends_at, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+campaignColumns,
+		campaign.Name, campaign.CategoryID, campaign.DiscountPercent, campaign.StartsAt, campaign.EndsAt, campaign.IsActive, createdBy,
+	))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditCampaignCreate, models.AuditEntityCampaign, created.ID, nil, created); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if !h.commitCampaign(c, tx, created.CategoryID) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateCampaign изменяет акцию
+// @Summary Изменение акции
+// @Description Изменяет переданные поля акции; is_active=false приостанавливает акцию (требует разрешение campaigns:manage)
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID акции"
+// @Param request body models.SaleCampaignUpdateRequest true "Изменяемые поля" example({"discount_percent":20,"ends_at":"2026-12-02T00:00:00Z"})
+// @Success 200 {object} models.SaleCampaign
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/campaigns/{id} [put]
+func (h *CampaignHandler) UpdateCampaign(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidCampaignID)
+		return
+	}
+
+	var req models.SaleCampaignUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := scanCampaign(tx.QueryRow("SELECT "+campaignColumns+" FROM sale_campaigns WHERE id = $1 FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeCampaignNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	campaign := *before
+	if req.Name != nil {
+		campaign.Name = *req.Name
+	}
+	if req.CategoryID != nil {
+		campaign.CategoryID = *req.CategoryID
+	}
+	if req.DiscountPercent != nil {
+		campaign.DiscountPercent = *req.DiscountPercent
+	}
+	if req.StartsAt != nil {
+		campaign.StartsAt = req.StartsAt.UTC()
+	}
+	if req.EndsAt != nil {
+		campaign.EndsAt = req.EndsAt.UTC()
+	}
+	if req.IsActive != nil {
+		campaign.IsActive = *req.IsActive
+	}
+	if !campaign.EndsAt.After(campaign.StartsAt) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidSalePeriod)
+		return
+	}
+
+	if campaign.CategoryID != before.CategoryID && !h.categoryExists(c, tx, campaign.CategoryID) {
+		return
+	}
+
+	updated, err := scanCampaign(tx.QueryRow(`
+		UPDATE sale_campaigns SET name = $1, category_id = $2, discount_percent = $3, starts_at = $4, ends_at = $5, is_active = $6
+		WHERE id = $7
+		RETURNING `+campaignColumns,
+		campaign.Name, campaign.CategoryID, campaign.DiscountPercent, campaign.StartsAt, campaign.EndsAt, campaign.IsActive, id,
+	))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditCampaignUpdate, models.AuditEntityCampaign, id, before, updated); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if !h.commitCampaign(c, tx, before.CategoryID, updated.CategoryID) {
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteCampaign удаляет акцию
+// @Summary Удаление акции
+// @Description Удаляет акцию; если она действует, цены продуктов категории сразу пересчитываются (требует разрешение campaigns:manage)
+// @Tags campaigns
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID акции"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/campaigns/{id} [delete]
+func (h *CampaignHandler) DeleteCampaign(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidCampaignID)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	campaign, err := scanCampaign(tx.QueryRow("DELETE FROM sale_campaigns WHERE id = $1 RETURNING "+campaignColumns, id))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeCampaignNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditCampaignDelete, models.AuditEntityCampaign, id, campaign, nil); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if !h.commitCampaign(c, tx, campaign.CategoryID) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Акция удалена"})
+}
+
+// categoryExists проверяет, что категория существует; иначе отправляет 404
+func (h *CampaignHandler) categoryExists(c *gin.Context, tx *sql.Tx, categoryID int) bool {
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", categoryID).Scan(&exists); err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+	if !exists {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeCategoryNotFound)
+		return false
+	}
+	return true
+}
+
+// commitCampaign пересчитывает действующие цены продуктов категорий, фиксирует транзакцию
+// и сбрасывает кэш продуктов, если цены изменились. При ошибке отправляет ответ и возвращает false.
+func (h *CampaignHandler) commitCampaign(c *gin.Context, tx *sql.Tx, categoryIDs ...int) bool {
+	changed, err := pricing.RefreshCategories(c.Request.Context(), tx, categoryIDs...)
+	if err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+
+	if len(changed) > 0 && h.cache != nil {
+		h.cache.InvalidateAllProductCache(c.Request.Context())
+	}
+	return true
+}
+
+// scanCampaign читает акцию из строки результата с колонками campaignColumns
+func scanCampaign(row rowScanner) (*models.SaleCampaign, error) {
+	var campaign models.SaleCampaign
+	err := row.Scan(
+		&campaign.ID, &campaign.Name, &campaign.CategoryID, &campaign.DiscountPercent, &campaign.StartsAt, &campaign.EndsAt,
+		&campaign.IsActive, &campaign.CreatedBy, &campaign.CreatedAt, &campaign.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
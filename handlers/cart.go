@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"database/sql"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
+	"api-go/apierror"
 	"api-go/models"
 
 	"github.com/gin-gonic/gin"
@@ -25,32 +27,47 @@ func NewCartHandler(db *sql.DB) *CartHandler {
 
 // GetCart получает корзину пользователя
 // @Summary Получение корзины
-// @Description Возвращает содержимое корзины аутентифицированного пользователя
+// @Description Возвращает содержимое корзины аутентифицированного пользователя. Цены позиций и сумма
+// @Description пересчитываются по текущему курсу в валюту из параметра currency или заголовка Accept-Currency
 // @Tags cart
 // @Produce json
 // @Security BearerAuth
+// @Param currency query string false "Валюта цен (ISO 4217), по умолчанию базовая"
+// @Param Accept-Currency header string false "Валюта цен, если не указан параметр currency"
 // @Success 200 {object} models.CartResponse
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /api/v1/cart [get]
 func (h *CartHandler) GetCart(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	rates, err := loadExchangeRates(h.db)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	currency, ok := requestCurrency(c, rates)
+	if !ok {
 		return
 	}
 
 	// Получаем товары в корзине
 	rows, err := h.db.Query(`
-		SELECT ci.id, ci.user_id, ci.product_id, ci.quantity, ci.price, ci.created_at, ci.updated_at,
+		SELECT ci.id, ci.user_id, ci.product_id, ci.variant_id, ci.quantity, ci.price, ci.currency, ci.created_at, ci.updated_at,
 		       p.id, p.name, p.description, p.image_url, COALESCE(p.category_id, 0), p.stock, COALESCE(p.sku, ''), p.is_active, p.created_at, p.updated_at
 		FROM cart_items ci
 		JOIN products p ON ci.product_id = p.id
-		WHERE ci.user_id = $1 AND p.is_active = true
+		LEFT JOIN product_variants v ON ci.variant_id = v.id
+		WHERE ci.user_id = $1 AND p.is_active = true AND p.deleted_at IS NULL AND (ci.variant_id IS NULL OR v.is_active = true)
 		ORDER BY ci.created_at DESC
 	`, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения корзины"})
+		apierror.Internal(c, err)
 		return
 	}
 	defer rows.Close()
@@ -63,7 +80,7 @@ func (h *CartHandler) GetCart(c *gin.Context) {
 		var item models.CartItem
 		var product models.Product
 		err := rows.Scan(
-			&item.ID, &item.UserID, &item.ProductID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt,
+			&item.ID, &item.UserID, &item.ProductID, &item.VariantID, &item.Quantity, &item.Price, &item.Currency, &item.CreatedAt, &item.UpdatedAt,
 			&product.ID, &product.Name, &product.Description, &product.ImageURL, &product.CategoryID, &product.Stock, &product.SKU, &product.IsActive, &product.CreatedAt, &product.UpdatedAt,
 		)
 		if err != nil {
@@ -84,7 +101,9 @@ func (h *CartHandler) GetCart(c *gin.Context) {
 			UpdatedAt:   product.UpdatedAt,
 		}
 
-		itemTotal := item.Price * float64(item.Quantity)
+		// Цена позиции хранится в валюте продукта на момент добавления
+		price := convertPrice(item.Price, item.Currency, currency, rates)
+		itemTotal := price * float64(item.Quantity)
 		totalPrice += itemTotal
 		totalItems += item.Quantity
 
@@ -93,21 +112,41 @@ func (h *CartHandler) GetCart(c *gin.Context) {
 			UserID:    item.UserID,
 			ProductID: item.ProductID,
 			Product:   productResponse,
+			VariantID: item.VariantID,
 			Quantity:  item.Quantity,
-			Price:     item.Price,
+			Price:     price,
 			Total:     itemTotal,
+			Currency:  currency,
 			CreatedAt: item.CreatedAt,
 			UpdatedAt: item.UpdatedAt,
 		}
 
 		items = append(items, itemResponse)
 	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Добавляем выбранные варианты
+	for i := range items {
+		if items[i].VariantID == nil {
+			continue
+		}
+		variant, err := getVariant(h.db, *items[i].VariantID)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		items[i].Variant = variant
+	}
 
 	response := models.CartResponse{
 		Items:      items,
 		TotalItems: totalItems,
-		TotalPrice: totalPrice,
+		TotalPrice: math.Round(totalPrice*100) / 100,
 		ItemCount:  len(items),
+		Currency:   currency,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -115,66 +154,65 @@ func (h *CartHandler) GetCart(c *gin.Context) {
 
 // AddToCart добавляет товар в корзину
 // @Summary Добавление товара в корзину
-// @Description Добавляет товар в корзину аутентифицированного пользователя
+// @Description Добавляет товар в корзину аутентифицированного пользователя. Для продукта с вариантами нужно указать variant_id
 // @Tags cart
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param item body models.CartItemRequest true "Данные товара"
 // @Success 201 {object} models.CartItemResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /api/v1/cart [post]
 func (h *CartHandler) AddToCart(c *gin.Context) {
 	var req models.CartItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные: " + err.Error()})
+		apierror.RespondValidation(c, err)
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
 		return
 	}
 
-	// Проверяем, что продукт существует и активен
-	var price float64
-	var stock int
-	err := h.db.QueryRow("SELECT price, stock FROM products WHERE id = $1 AND is_active = true", req.ProductID).Scan(&price, &stock)
+	// Проверяем, что продукт (и вариант, если он нужен) существует и активен
+	purchase, err := resolvePurchasable(h.db, req.ProductID, req.VariantID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Продукт не найден или неактивен"})
+		respondPurchasableError(c, err)
 		return
 	}
 
-	if stock < req.Quantity {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Недостаточно товара на складе"})
+	if purchase.stock < req.Quantity {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInsufficientStock)
 		return
 	}
 
 	// Проверяем, есть ли уже такой товар в корзине
 	var existingID int
 	var existingQuantity int
-	err = h.db.QueryRow("SELECT id, quantity FROM cart_items WHERE user_id = $1 AND product_id = $2", userID, req.ProductID).Scan(&existingID, &existingQuantity)
+	err = h.db.QueryRow("SELECT id, quantity FROM cart_items WHERE user_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3",
+		userID, req.ProductID, req.VariantID).Scan(&existingID, &existingQuantity)
 
 	if err == sql.ErrNoRows {
 		// Товара нет в корзине, добавляем новый
 		var cartItemID int
 		err = h.db.QueryRow(`
-			INSERT INTO cart_items (user_id, product_id, quantity, price)
-			VALUES ($1, $2, $3, $4)
+			INSERT INTO cart_items (user_id, product_id, variant_id, quantity, price, currency)
+			VALUES ($1, $2, $3, $4, $5, $6)
 			RETURNING id
-		`, userID, req.ProductID, req.Quantity, price).Scan(&cartItemID)
+		`, userID, req.ProductID, req.VariantID, req.Quantity, purchase.price, purchase.currency).Scan(&cartItemID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка добавления товара в корзину"})
+			apierror.Internal(c, err)
 			return
 		}
 
 		// Получаем добавленный товар
 		cartItem, err := h.getCartItemByID(cartItemID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения товара из корзины"})
+			apierror.Internal(c, err)
 			return
 		}
 
@@ -183,28 +221,28 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка проверки корзины"})
+		apierror.Internal(c, err)
 		return
 	}
 
 	// Товар уже есть в корзине, обновляем количество
 	newQuantity := existingQuantity + req.Quantity
-	if newQuantity > stock {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Недостаточно товара на складе"})
+	if newQuantity > purchase.stock {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInsufficientStock)
 		return
 	}
 
 	_, err = h.db.Exec("UPDATE cart_items SET quantity = $1, updated_at = $2 WHERE id = $3",
 		newQuantity, time.Now(), existingID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления количества товара"})
+		apierror.Internal(c, err)
 		return
 	}
 
 	// Получаем обновленный товар
 	cartItem, err := h.getCartItemByID(existingID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения товара из корзины"})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -221,55 +259,55 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 // @Param id path int true "ID товара в корзине"
 // @Param item body models.CartItemUpdateRequest true "Данные для обновления"
 // @Success 200 {object} models.CartItemResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /api/v1/cart/{id} [put]
 func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 	cartItemID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID товара"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidCartItemID)
 		return
 	}
 
 	var req models.CartItemUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные: " + err.Error()})
+		apierror.RespondValidation(c, err)
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
 		return
 	}
 
 	// Проверяем, что товар принадлежит пользователю
 	var itemUserID int
 	var productID int
-	err = h.db.QueryRow("SELECT user_id, product_id FROM cart_items WHERE id = $1", cartItemID).Scan(&itemUserID, &productID)
+	var variantID *int
+	err = h.db.QueryRow("SELECT user_id, product_id, variant_id FROM cart_items WHERE id = $1", cartItemID).Scan(&itemUserID, &productID, &variantID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Товар в корзине не найден"})
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeCartItemNotFound)
 		return
 	}
 
 	if itemUserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeForbidden)
 		return
 	}
 
-	// Проверяем остаток товара
-	var stock int
-	err = h.db.QueryRow("SELECT stock FROM products WHERE id = $1 AND is_active = true", productID).Scan(&stock)
+	// Проверяем остаток товара (или выбранного варианта)
+	purchase, err := resolvePurchasable(h.db, productID, variantID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Продукт не найден"})
+		respondPurchasableError(c, err)
 		return
 	}
 
-	if req.Quantity > stock {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Недостаточно товара на складе"})
+	if req.Quantity > purchase.stock {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInsufficientStock)
 		return
 	}
 
@@ -277,14 +315,14 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 	_, err = h.db.Exec("UPDATE cart_items SET quantity = $1, updated_at = $2 WHERE id = $3",
 		req.Quantity, time.Now(), cartItemID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления товара"})
+		apierror.Internal(c, err)
 		return
 	}
 
 	// Получаем обновленный товар
 	cartItem, err := h.getCartItemByID(cartItemID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения товара из корзины"})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -299,22 +337,22 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path int true "ID товара в корзине"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /api/v1/cart/{id} [delete]
 func (h *CartHandler) RemoveFromCart(c *gin.Context) {
 	cartItemID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID товара"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidCartItemID)
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
 		return
 	}
 
@@ -322,19 +360,19 @@ func (h *CartHandler) RemoveFromCart(c *gin.Context) {
 	var itemUserID int
 	err = h.db.QueryRow("SELECT user_id FROM cart_items WHERE id = $1", cartItemID).Scan(&itemUserID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Товар в корзине не найден"})
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeCartItemNotFound)
 		return
 	}
 
 	if itemUserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeForbidden)
 		return
 	}
 
 	// Удаляем товар
 	_, err = h.db.Exec("DELETE FROM cart_items WHERE id = $1", cartItemID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка удаления товара"})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -348,19 +386,19 @@ func (h *CartHandler) RemoveFromCart(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /api/v1/cart/clear [post]
 func (h *CartHandler) ClearCart(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
 		return
 	}
 
 	_, err := h.db.Exec("DELETE FROM cart_items WHERE user_id = $1", userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка очистки корзины"})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -374,13 +412,13 @@ func (h *CartHandler) getCartItemByID(cartItemID int) (*models.CartItemResponse,
 	var item models.CartItem
 	var product models.Product
 	err := h.db.QueryRow(`
-		SELECT ci.id, ci.user_id, ci.product_id, ci.quantity, ci.price, ci.created_at, ci.updated_at,
+		SELECT ci.id, ci.user_id, ci.product_id, ci.variant_id, ci.quantity, ci.price, ci.currency, ci.created_at, ci.updated_at,
 		       p.id, p.name, p.description, p.image_url, COALESCE(p.category_id, 0), p.stock, COALESCE(p.sku, ''), p.is_active, p.created_at, p.updated_at
 		FROM cart_items ci
 		JOIN products p ON ci.product_id = p.id
 		WHERE ci.id = $1
 	`, cartItemID).Scan(
-		&item.ID, &item.UserID, &item.ProductID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt,
+		&item.ID, &item.UserID, &item.ProductID, &item.VariantID, &item.Quantity, &item.Price, &item.Currency, &item.CreatedAt, &item.UpdatedAt,
 		&product.ID, &product.Name, &product.Description, &product.ImageURL, &product.CategoryID, &product.Stock, &product.SKU, &product.IsActive, &product.CreatedAt, &product.UpdatedAt,
 	)
 	if err != nil {
@@ -401,15 +439,27 @@ func (h *CartHandler) getCartItemByID(cartItemID int) (*models.CartItemResponse,
 		UpdatedAt:   product.UpdatedAt,
 	}
 
-	return &models.CartItemResponse{
+	response := &models.CartItemResponse{
 		ID:        item.ID,
 		UserID:    item.UserID,
 		ProductID: item.ProductID,
 		Product:   productResponse,
+		VariantID: item.VariantID,
 		Quantity:  item.Quantity,
 		Price:     item.Price,
 		Total:     item.Price * float64(item.Quantity),
+		Currency:  item.Currency,
 		CreatedAt: item.CreatedAt,
 		UpdatedAt: item.UpdatedAt,
-	}, nil
+	}
+
+	if item.VariantID != nil {
+		variant, err := getVariant(h.db, *item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		response.Variant = variant
+	}
+
+	return response, nil
 }
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"api-go/apierror"
+	"api-go/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// exchangeRateColumns список колонок для выборки курса функцией scanExchangeRate
+const exchangeRateColumns = `currency, rate, updated_by, updated_at`
+
+// currencyPattern код валюты ISO 4217
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ExchangeRateHandler обрабатывает запросы для работы с курсами валют
+type ExchangeRateHandler struct {
+	db *sql.DB
+}
+
+// NewExchangeRateHandler создает новый экземпляр ExchangeRateHandler
+func NewExchangeRateHandler(db *sql.DB) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		db: db,
+	}
+}
+
+// GetExchangeRates возвращает курсы валют
+// @Summary Курсы валют
+// @Description Возвращает валюты, в которых можно запросить каталог и оформить заказ, и их курсы к базовой валюте (RUB)
+// @Tags currencies
+// @Produce json
+// @Success 200 {array} models.ExchangeRate
+// @Failure 500 {object} apierror.Problem
+// @Router /exchange-rates [get]
+func (h *ExchangeRateHandler) GetExchangeRates(c *gin.Context) {
+	rates, err := h.queryRates()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// SetExchangeRate устанавливает курс валюты
+// @Summary Установка курса валюты
+// @Description Добавляет валюту или меняет ее курс к базовой валюте. Уже оформленные заказы сохраняют свой курс.
+// @Description Требует разрешение currencies:manage.
+// @Tags currencies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param currency path string true "Код валюты ISO 4217" example(USD)
+// @Param request body models.ExchangeRateUpdateRequest true "Стоимость единицы валюты в базовой валюте" example({"rate":92.5})
+// @Success 200 {object} models.ExchangeRate
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/exchange-rates/{currency} [put]
+func (h *ExchangeRateHandler) SetExchangeRate(c *gin.Context) {
+	currency, ok := rateCurrency(c, c.Param("currency"))
+	if !ok {
+		return
+	}
+
+	var req models.ExchangeRateUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	rate, err := scanExchangeRate(upsertExchangeRate(h.db, c, currency, req.Rate))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// ImportExchangeRates устанавливает курсы нескольких валют
+// @Summary Загрузка курсов валют
+// @Description Устанавливает курсы всех переданных валют в одной транзакции (например, выгрузку курсов ЦБ);
+// @Description валюты, которых нет в запросе, не меняются. Возвращает все курсы. Требует разрешение currencies:manage.
+// @Tags currencies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param request body models.ExchangeRatesImportRequest true "Курсы по коду валюты" example({"rates":{"USD":92.5,"EUR":100.1,"CNY":12.7}})
+// @Success 200 {array} models.ExchangeRate
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/exchange-rates/import [post]
+func (h *ExchangeRateHandler) ImportExchangeRates(c *gin.Context) {
+	var req models.ExchangeRatesImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	rates := make(map[string]float64, len(req.Rates))
+	for code, rate := range req.Rates {
+		currency, ok := rateCurrency(c, code)
+		if !ok {
+			return
+		}
+		rates[currency] = rate
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	for currency, rate := range rates {
+		if _, err := scanExchangeRate(upsertExchangeRate(tx, c, currency, rate)); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	all, err := h.queryRates()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, all)
+}
+
+// DeleteExchangeRate удаляет валюту
+// @Summary Удаление валюты
+// @Description Удаляет курс валюты: каталог и заказы в ней станут недоступны. Валюту, в которой заданы цены продуктов,
+// @Description удалить нельзя. Требует разрешение currencies:manage.
+// @Tags currencies
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param currency path string true "Код валюты ISO 4217" example(USD)
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/exchange-rates/{currency} [delete]
+func (h *ExchangeRateHandler) DeleteExchangeRate(c *gin.Context) {
+	currency, ok := rateCurrency(c, c.Param("currency"))
+	if !ok {
+		return
+	}
+
+	result, err := h.db.Exec("DELETE FROM exchange_rates WHERE currency = $1", currency)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeCurrencyInUse)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeExchangeRateNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Валюта удалена"})
+}
+
+// queryRates возвращает все курсы по коду валюты
+func (h *ExchangeRateHandler) queryRates() ([]models.ExchangeRate, error) {
+	rows, err := h.db.Query("SELECT " + exchangeRateColumns + " FROM exchange_rates ORDER BY currency")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.ExchangeRate{}
+	for rows.Next() {
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, *rate)
+	}
+	return rates, rows.Err()
+}
+
+// rateCurrency проверяет код валюты, курс которой меняется: базовую валюту менять нельзя.
+// При ошибке отправляет ответ и возвращает false.
+func rateCurrency(c *gin.Context, code string) (string, bool) {
+	currency := strings.ToUpper(strings.TrimSpace(code))
+	if !currencyPattern.MatchString(currency) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidCurrency, code)
+		return "", false
+	}
+	if currency == models.BaseCurrency {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeBaseCurrencyRate, models.BaseCurrency)
+		return "", false
+	}
+	return currency, true
+}
+
+// upsertExchangeRate добавляет или изменяет курс валюты и возвращает строку с колонками exchangeRateColumns
+func upsertExchangeRate(db dbExecutor, c *gin.Context, currency string, rate float64) *sql.Row {
+	var updatedBy sql.NullInt64
+	if id, ok := c.Get("user_id"); ok {
+		updatedBy = sql.NullInt64{Int64: int64(id.(int)), Valid: true}
+	}
+
+	return db.QueryRow(`
+		INSERT INTO exchange_rates (currency, rate, updated_by, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (currency) DO UPDATE SET rate = EXCLUDED.rate, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+		RETURNING `+exchangeRateColumns,
+		currency, rate, updatedBy)
+}
+
+// scanExchangeRate читает курс из строки результата с колонками exchangeRateColumns
+func scanExchangeRate(row rowScanner) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	if err := row.Scan(&rate.Currency, &rate.Rate, &rate.UpdatedBy, &rate.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// loadExchangeRates возвращает курсы всех валют к базовой по коду валюты
+func loadExchangeRates(db dbExecutor) (map[string]float64, error) {
+	rows, err := db.Query("SELECT currency, rate FROM exchange_rates")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := map[string]float64{models.BaseCurrency: 1}
+	for rows.Next() {
+		var currency string
+		var rate float64
+		if err := rows.Scan(&currency, &rate); err != nil {
+			return nil, err
+		}
+		rates[currency] = rate
+	}
+	return rates, rows.Err()
+}
+
+// requestCurrency определяет валюту ответа по параметру currency или заголовку Accept-Currency;
+// без них - базовая валюта. Для неизвестной валюты отправляет 400 и возвращает false.
+func requestCurrency(c *gin.Context, rates map[string]float64) (string, bool) {
+	// Ответ зависит от заголовка: кэши не должны отдавать его клиентам с другой валютой
+	c.Header("Vary", "Accept-Currency")
+
+	code := c.Query("currency")
+	if code == "" {
+		code = c.GetHeader("Accept-Currency")
+	}
+	return knownCurrency(c, code, rates)
+}
+
+// knownCurrency проверяет, что для валюты задан курс; пустой код - базовая валюта.
+// Для неизвестной валюты отправляет 400 и возвращает false.
+func knownCurrency(c *gin.Context, code string, rates map[string]float64) (string, bool) {
+	currency := strings.ToUpper(strings.TrimSpace(code))
+	if currency == "" {
+		return models.BaseCurrency, true
+	}
+	if _, ok := rates[currency]; !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeUnknownCurrency, code)
+		return "", false
+	}
+	return currency, true
+}
+
+// convertPrice переводит сумму из валюты from в валюту to через базовую валюту с округлением до копеек.
+// Пустая валюта from - базовая (записи кэша, созданные до появления валют).
+func convertPrice(amount float64, from, to string, rates map[string]float64) float64 {
+	if from == "" {
+		from = models.BaseCurrency
+	}
+	if from == to {
+		return amount
+	}
+	return math.Round(amount*rates[from]/rates[to]*100) / 100
+}
+
+// convertProductPrices переводит цены продукта и его вариантов в валюту currency
+func convertProductPrices(product *models.ProductResponse, currency string, rates map[string]float64) {
+	from := product.Currency
+	product.Price = convertPrice(product.Price, from, currency, rates)
+	product.EffectivePrice = convertPrice(product.EffectivePrice, from, currency, rates)
+	product.Currency = currency
+
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+		variant.Price = convertPrice(variant.Price, variant.Currency, currency, rates)
+		variant.EffectivePrice = convertPrice(variant.EffectivePrice, variant.Currency, currency, rates)
+		if variant.PriceOverride != nil {
+			override := convertPrice(*variant.PriceOverride, variant.Currency, currency, rates)
+			variant.PriceOverride = &override
+		}
+		variant.Currency = currency
+	}
+}
+
+// productCurrency проверяет валюту цены продукта; пустой код - базовая валюта.
+// При ошибке отправляет ответ и возвращает false.
+func productCurrency(c *gin.Context, db dbExecutor, code string) (string, bool) {
+	currency := strings.ToUpper(strings.TrimSpace(code))
+	if currency == "" {
+		return models.BaseCurrency, true
+	}
+	if !currencyPattern.MatchString(currency) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidCurrency, code)
+		return "", false
+	}
+
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM exchange_rates WHERE currency = $1)", currency).Scan(&exists); err != nil {
+		apierror.Internal(c, err)
+		return "", false
+	}
+	if !exists {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeUnknownCurrency, code)
+		return "", false
+	}
+	return currency, true
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"api-go/jwtkeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler публикует открытые ключи проверки JWT для других сервисов
+type JWKSHandler struct {
+	keys *jwtkeys.KeySet
+}
+
+// NewJWKSHandler создает новый экземпляр JWKSHandler
+func NewJWKSHandler(keys *jwtkeys.KeySet) *JWKSHandler {
+	return &JWKSHandler{
+		keys: keys,
+	}
+}
+
+// GetJWKS возвращает открытые ключи проверки JWT в формате JWKS (RFC 7517):
+// текущий ключ подписи, новые ключи до их активации и старые до вывода из оборота.
+// При подписи HS256 список пуст.
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	// Клиенты могут кэшировать ключи: новый ключ публикуется за jwtkeys.ActivationDelay до того, как им начнут подписывать
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordLoginEvent записывает попытку входа в журнал. userID = 0 - пользователь не найден.
+// Ошибка записи не влияет на результат входа.
+func recordLoginEvent(db *sql.DB, c *gin.Context, userID int, email, outcome string) {
+	var uid sql.NullInt64
+	if userID > 0 {
+		uid = sql.NullInt64{Int64: int64(userID), Valid: true}
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO login_events (user_id, email, ip_address, user_agent, outcome)
+		VALUES ($1, $2, $3, $4, $5)`,
+		uid, email, c.ClientIP(), c.Request.UserAgent(), outcome)
+	if err != nil {
+		log.Printf("Ошибка записи в журнал входов: %v", err)
+	}
+}
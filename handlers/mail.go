@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"api-go/config"
+	"api-go/mailer"
+)
+
+// Время жизни одноразовых токенов, отправляемых по почте
+const (
+	emailChangeTokenTTL   = 24 * time.Hour
+	emailVerifyTokenTTL   = 48 * time.Hour
+	passwordResetTokenTTL = time.Hour
+)
+
+// mailSendTimeout ограничивает фоновую отправку письма
+const mailSendTimeout = 30 * time.Second
+
+// tokenMessage формирует письмо с одноразовым токеном и адресом, куда его отправить
+func tokenMessage(cfg *config.Config, to, subject, intro, path, token string, ttl time.Duration) mailer.Message {
+	return mailer.Message{
+		To:      to,
+		Subject: subject,
+		Body: fmt.Sprintf("%s\n\n%s\n\nОтправьте его в POST %s/api/v1%s. Токен действителен %s.\n\n"+
+			"Если вы не запрашивали это письмо, просто проигнорируйте его.",
+			intro, token, cfg.Server.PublicURL, path, formatTTL(ttl)),
+	}
+}
+
+// emailChangeMessage письмо с подтверждением нового email
+func emailChangeMessage(cfg *config.Config, to, token string) mailer.Message {
+	return tokenMessage(cfg, to, "Подтверждение нового email",
+		"Для подтверждения нового адреса используйте токен:",
+		"/auth/confirm-email-change", token, emailChangeTokenTTL)
+}
+
+// emailVerifyMessage письмо с подтверждением email после регистрации
+func emailVerifyMessage(cfg *config.Config, to, token string) mailer.Message {
+	return tokenMessage(cfg, to, "Подтверждение email",
+		"Для подтверждения email используйте токен:",
+		"/auth/verify-email", token, emailVerifyTokenTTL)
+}
+
+// accountExistsMessage письмо владельцу email, с которым пытаются зарегистрироваться повторно
+func accountExistsMessage(cfg *config.Config, to string) mailer.Message {
+	return mailer.Message{
+		To:      to,
+		Subject: "Попытка регистрации",
+		Body: fmt.Sprintf("Кто-то пытается зарегистрироваться с вашим email, но учетная запись с ним уже существует.\n\n"+
+			"Если это были вы, войдите через POST %[1]s/api/v1/auth/login или восстановите пароль через POST %[1]s/api/v1/auth/forgot-password.\n\n"+
+			"Если вы не запрашивали это письмо, просто проигнорируйте его.",
+			cfg.Server.PublicURL),
+	}
+}
+
+// passwordResetMessage письмо со сбросом пароля
+func passwordResetMessage(cfg *config.Config, to, token string) mailer.Message {
+	return tokenMessage(cfg, to, "Сброс пароля",
+		"Для установки нового пароля используйте токен:",
+		"/auth/reset-password", token, passwordResetTokenTTL)
+}
+
+// sendMailAsync отправляет письмо в фоне, чтобы время ответа не зависело от почтового сервера.
+// Ошибки отправки только логируются.
+func sendMailAsync(m mailer.Mailer, msg mailer.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+
+		if err := m.Send(ctx, msg); err != nil {
+			log.Printf("Ошибка отправки письма %q для %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
+
+// formatTTL выводит срок действия токена в часах
+func formatTTL(ttl time.Duration) string {
+	hours := int(ttl.Hours())
+	switch {
+	case hours%10 == 1 && hours%100 != 11:
+		return fmt.Sprintf("%d час", hours)
+	case hours%10 >= 2 && hours%10 <= 4 && (hours%100 < 10 || hours%100 >= 20):
+		return fmt.Sprintf("%d часа", hours)
+	default:
+		return fmt.Sprintf("%d часов", hours)
+	}
+}
@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"api-go/apierror"
+	"api-go/models"
+	"api-go/oidcauth"
+	"api-go/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+// oidcStateTTL время на вход у провайдера
+const oidcStateTTL = 10 * time.Minute
+
+// oidcStateCookie cookie с хешем state: привязывает вход к браузеру, который его начал
+const oidcStateCookie = "oidc_state"
+
+// usernameInvalidChars символы, недопустимые в имени пользователя, созданного через OIDC
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// errOIDCEmailNotVerified возвращается, если провайдер не подтвердил email пользователя
+var errOIDCEmailNotVerified = errors.New("email не подтвержден провайдером")
+
+// errOIDCLinkUnverified возвращается, если email принадлежит локальной учетной записи с неподтвержденным email
+var errOIDCLinkUnverified = errors.New("email учетной записи не подтвержден")
+
+// OIDCHandler обрабатывает вход через провайдеров OpenID Connect
+type OIDCHandler struct {
+	auth      *AuthHandler
+	providers *oidcauth.Registry
+}
+
+// NewOIDCHandler создает новый экземпляр OIDCHandler
+func NewOIDCHandler(auth *AuthHandler, providers *oidcauth.Registry) *OIDCHandler {
+	return &OIDCHandler{
+		auth:      auth,
+		providers: providers,
+	}
+}
+
+// GetProviders возвращает список провайдеров OpenID Connect
+// @Summary Провайдеры входа
+// @Description Возвращает настроенных провайдеров OpenID Connect и адреса для начала входа
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.OIDCProvidersResponse
+// @Router /auth/oidc/providers [get]
+func (h *OIDCHandler) GetProviders(c *gin.Context) {
+	providers := []models.OIDCProviderInfo{}
+	for _, name := range h.providers.Names() {
+		providers = append(providers, models.OIDCProviderInfo{
+			Name:     name,
+			LoginURL: "/api/v1/auth/oidc/" + name + "/login",
+		})
+	}
+
+	c.JSON(http.StatusOK, models.OIDCProvidersResponse{Providers: providers})
+}
+
+// Login перенаправляет пользователя на страницу входа провайдера
+// @Summary Вход через провайдера
+// @Description Перенаправляет на страницу входа провайдера OpenID Connect (authorization code + PKCE). После входа провайдер вернет пользователя на /auth/oidc/{provider}/callback.
+// @Description Устанавливает cookie oidc_state, без которой возврат от провайдера отклоняется
+// @Tags auth
+// @Param provider path string true "Имя провайдера"
+// @Success 302
+// @Failure 404 {object} apierror.Problem
+// @Failure 502 {object} apierror.Problem
+// @Router /auth/oidc/{provider}/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	provider, ok := h.providers.Get(c.Param("provider"))
+	if !ok {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeOIDCProviderNotFound)
+		return
+	}
+
+	state, stateHash, err := utils.GenerateSecureToken()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	nonce, _, err := utils.GenerateSecureToken()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Printf("Ошибка OIDC: %v", err)
+		apierror.Respond(c, http.StatusBadGateway, apierror.CodeOIDCProviderUnavailable)
+		return
+	}
+
+	// Заодно удаляем незавершенные попытки входа
+	if _, err := h.auth.db.Exec("DELETE FROM oidc_login_states WHERE expires_at < CURRENT_TIMESTAMP"); err != nil {
+		log.Printf("Ошибка очистки состояний OIDC: %v", err)
+	}
+
+	_, err = h.auth.db.Exec(`
+		INSERT INTO oidc_login_states (state_hash, provider, code_verifier, nonce, expires_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP + $5 * INTERVAL '1 second')`,
+		stateHash, provider.Name(), verifier, nonce, int(oidcStateTTL.Seconds()))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	setOIDCStateCookie(c, provider.Name(), stateHash, int(oidcStateTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback завершает вход через провайдера
+// @Summary Возврат от провайдера
+// @Description Обменивает код авторизации на ID токен и выдает JWT. state должен совпадать с cookie oidc_state, выданной при начале входа в этом браузере. Учетная запись находится по привязке к провайдеру, затем по подтвержденному email; если ее нет, создается новая. Если включена 2FA, возвращает 202 и токен для POST /auth/login/2fa
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Имя провайдера"
+// @Param code query string true "Код авторизации"
+// @Param state query string true "Состояние, выданное при перенаправлении"
+// @Success 200 {object} models.LoginResponse
+// @Success 202 {object} models.TwoFactorChallengeResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 502 {object} apierror.Problem
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	provider, ok := h.providers.Get(c.Param("provider"))
+	if !ok {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeOIDCProviderNotFound)
+		return
+	}
+
+	if providerError := c.Query("error"); providerError != "" {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeOIDCProviderDenied, providerError)
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeOIDCMissingCode)
+		return
+	}
+
+	// state должен быть выдан этому браузеру: иначе по чужой ссылке возврата
+	// пользователь войдет в учетную запись злоумышленника
+	stateHash := utils.HashToken(state)
+	cookie, err := c.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie), []byte(stateHash)) != 1 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeOIDCInvalidState)
+		return
+	}
+	setOIDCStateCookie(c, provider.Name(), "", -1)
+
+	// state одноразовый и привязан к провайдеру
+	var verifier, nonce string
+	err = h.auth.db.QueryRow(`
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1 AND provider = $2 AND expires_at > CURRENT_TIMESTAMP
+		RETURNING code_verifier, nonce`,
+		stateHash, provider.Name(),
+	).Scan(&verifier, &nonce)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeOIDCInvalidState)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), code, verifier, nonce)
+	if err != nil {
+		log.Printf("Ошибка OIDC (%s): %v", provider.Name(), err)
+		apierror.Respond(c, http.StatusBadGateway, apierror.CodeOIDCExchangeFailed)
+		return
+	}
+
+	userID, err := h.resolveUser(provider.Name(), identity)
+	switch {
+	case errors.Is(err, errOIDCEmailNotVerified):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeOIDCEmailNotVerified)
+		return
+	case errors.Is(err, errOIDCLinkUnverified):
+		apierror.Respond(c, http.StatusConflict, apierror.CodeOIDCEmailTaken)
+		return
+	case err != nil:
+		apierror.Internal(c, err)
+		return
+	}
+
+	user, err := getUserByID(h.auth.db, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if !user.IsActive {
+		recordLoginEvent(h.auth.db, c, user.ID, user.Email, models.LoginOutcomeDisabled)
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeAccountDisabled)
+		return
+	}
+
+	if user.TOTPEnabled {
+		h.auth.respondTwoFactorChallenge(c, user, user.Email)
+		return
+	}
+
+	h.auth.completeLogin(c, user, user.Email)
+}
+
+// resolveUser находит пользователя по привязке к провайдеру или по подтвержденному email,
+// при необходимости создает его, и возвращает ID пользователя
+func (h *OIDCHandler) resolveUser(provider string, identity *oidcauth.Identity) (int, error) {
+	db := h.auth.db
+
+	var userID int
+	err := db.QueryRow(`
+		UPDATE user_identities SET last_login_at = CURRENT_TIMESTAMP, email = COALESCE(NULLIF($3, ''), email)
+		WHERE provider = $1 AND subject = $2
+		RETURNING user_id`,
+		provider, identity.Subject, identity.Email,
+	).Scan(&userID)
+	if err == nil {
+		return userID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	// Привязка по email допустима, только если его подтвердили обе стороны:
+	// иначе заранее зарегистрированный чужой аккаунт получил бы доступ к учетной записи
+	if identity.Email == "" || !identity.EmailVerified {
+		return 0, errOIDCEmailNotVerified
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var emailVerified bool
+	err = tx.QueryRow(
+		"SELECT id, email_verified_at IS NOT NULL FROM users WHERE LOWER(email) = LOWER($1)",
+		identity.Email,
+	).Scan(&userID, &emailVerified)
+	switch {
+	case err == sql.ErrNoRows:
+		userID, err = createOIDCUser(tx, identity)
+		if err != nil {
+			return 0, err
+		}
+	case err != nil:
+		return 0, err
+	case !emailVerified:
+		return 0, errOIDCLinkUnverified
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO user_identities (user_id, provider, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)`,
+		userID, provider, identity.Subject, identity.Email)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// createOIDCUser создает пользователя с подтвержденным email и случайным паролем.
+// Задать пароль для входа без провайдера можно через восстановление пароля.
+func createOIDCUser(tx *sql.Tx, identity *oidcauth.Identity) (int, error) {
+	password, err := utils.GenerateRandomPassword()
+	if err != nil {
+		return 0, err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return 0, err
+	}
+
+	username, err := availableUsername(tx, oidcUsername(identity))
+	if err != nil {
+		return 0, err
+	}
+
+	firstName := []rune(strings.TrimSpace(identity.Name))
+	if len(firstName) > 50 {
+		firstName = firstName[:50]
+	}
+
+	var userID int
+	err = tx.QueryRow(`
+		INSERT INTO users (username, email, password, role, first_name, email_verified_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), CURRENT_TIMESTAMP)
+		RETURNING id`,
+		username, identity.Email, hashedPassword, models.RoleUser, string(firstName),
+	).Scan(&userID)
+	return userID, err
+}
+
+// oidcUsername выбирает имя пользователя из данных провайдера
+func oidcUsername(identity *oidcauth.Identity) string {
+	username := identity.PreferredUsername
+	if username == "" {
+		username, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	username = usernameInvalidChars.ReplaceAllString(username, "")
+	if len(username) > 40 {
+		username = username[:40]
+	}
+	if len(username) < 3 {
+		username = "user"
+	}
+	return username
+}
+
+// availableUsername возвращает base или base с числовым суффиксом, если имя занято
+func availableUsername(tx *sql.Tx, base string) (string, error) {
+	for i := 0; i < 100; i++ {
+		username := base
+		if i > 0 {
+			username = fmt.Sprintf("%s%d", base, i+1)
+		}
+
+		var taken bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)", username).Scan(&taken); err != nil {
+			return "", err
+		}
+		if !taken {
+			return username, nil
+		}
+	}
+	return "", fmt.Errorf("не удалось подобрать свободное имя пользователя для %q", base)
+}
+
+// setOIDCStateCookie устанавливает (maxAge > 0) или удаляет (maxAge < 0) cookie с хешем state.
+// Cookie видна только адресам провайдера; SameSite=Lax пропускает ее при возврате от провайдера.
+func setOIDCStateCookie(c *gin.Context, provider, stateHash string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    stateHash,
+		Path:     "/api/v1/auth/oidc/" + provider,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"api-go/apierror"
 	"api-go/models"
 
 	"github.com/gin-gonic/gin"
@@ -26,48 +28,70 @@ func NewOrderHandler(db *sql.DB) *OrderHandler {
 
 // CreateOrder создает новый заказ
 // @Summary Создание заказа
-// @Description Создает новый заказ для аутентифицированного пользователя
+// @Description Создает новый заказ для аутентифицированного пользователя. Для продуктов с вариантами нужно указать variant_id.
+// @Description Цены фиксируются в валюте заказа (поле currency, параметр currency, заголовок Accept-Currency или базовая)
+// @Description по текущему курсу, курс сохраняется в заказе
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param order body models.OrderCreateRequest true "Данные заказа"
+// @Param currency query string false "Валюта заказа (ISO 4217), если она не указана в теле"
+// @Param Accept-Currency header string false "Валюта заказа, если не указаны поле и параметр currency"
 // @Success 201 {object} models.OrderResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /api/v1/orders [post]
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	var req models.OrderCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные: " + err.Error()})
+		apierror.RespondValidation(c, err)
 		return
 	}
 
 	// Получаем ID пользователя из JWT токена
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
 		return
 	}
 
 	// Начинаем транзакцию
 	tx, err := h.db.Begin()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка базы данных"})
+		apierror.Internal(c, err)
 		return
 	}
 	defer tx.Rollback()
 
+	// Определяем валюту заказа; курсы читаются в транзакции, чтобы цены и сохраненный курс были согласованы
+	rates, err := loadExchangeRates(tx)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	var currency string
+	var ok bool
+	if req.Currency != "" {
+		currency, ok = knownCurrency(c, req.Currency, rates)
+	} else {
+		currency, ok = requestCurrency(c, rates)
+	}
+	if !ok {
+		return
+	}
+
 	// Создаем заказ
 	var orderID int
 	err = tx.QueryRow(`
-		INSERT INTO orders (user_id, status, total_amount, shipping_address, billing_address, payment_method)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO orders (user_id, status, total_amount, shipping_address, billing_address, payment_method, currency, exchange_rate)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
-	`, userID, models.OrderStatusPending, 0, req.ShippingAddress, req.BillingAddress, req.PaymentMethod).Scan(&orderID)
+	`, userID, models.OrderStatusPending, 0, req.ShippingAddress, req.BillingAddress, req.PaymentMethod,
+		currency, rates[currency]).Scan(&orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка создания заказа"})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -75,37 +99,62 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 
 	// Добавляем товары в заказ
 	for _, item := range req.Items {
-		// Получаем информацию о продукте
-		var price float64
-		var stock int
-		err := tx.QueryRow("SELECT price, stock FROM products WHERE id = $1 AND is_active = true", item.ProductID).Scan(&price, &stock)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Продукт с ID %d не найден", item.ProductID)})
+		// Получаем информацию о продукте или его варианте
+		purchase, err := resolvePurchasable(tx, item.ProductID, item.VariantID)
+		switch err {
+		case nil:
+		case errProductUnavailable:
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeOrderProductNotFound, item.ProductID)
+			return
+		case errVariantRequired:
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeOrderVariantRequired, item.ProductID)
+			return
+		case errVariantUnavailable:
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeOrderVariantNotFound, *item.VariantID)
+			return
+		default:
+			apierror.Internal(c, err)
 			return
 		}
 
-		if stock < item.Quantity {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Недостаточно товара для продукта с ID %d", item.ProductID)})
+		if purchase.stock < item.Quantity {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeOrderInsufficientStock, item.ProductID)
 			return
 		}
 
-		// Добавляем товар в заказ
+		// Добавляем товар в заказ, сохраняя SKU и атрибуты варианта на момент покупки
+		price := convertPrice(purchase.price, purchase.currency, currency, rates)
 		itemTotal := price * float64(item.Quantity)
 		totalAmount += itemTotal
 
+		var variantSKU, variantAttributes sql.NullString
+		if purchase.variant != nil {
+			attributes, err := json.Marshal(purchase.variant.Attributes)
+			if err != nil {
+				apierror.Internal(c, err)
+				return
+			}
+			variantSKU = sql.NullString{String: purchase.variant.SKU, Valid: true}
+			variantAttributes = sql.NullString{String: string(attributes), Valid: true}
+		}
+
 		_, err = tx.Exec(`
-			INSERT INTO order_items (order_id, product_id, quantity, price, total)
-			VALUES ($1, $2, $3, $4, $5)
-		`, orderID, item.ProductID, item.Quantity, price, itemTotal)
+			INSERT INTO order_items (order_id, product_id, variant_id, variant_sku, variant_attributes, quantity, price, total)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, orderID, item.ProductID, item.VariantID, variantSKU, variantAttributes, item.Quantity, price, itemTotal)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка добавления товара в заказ"})
+			apierror.Internal(c, err)
 			return
 		}
 
-		// Обновляем остаток товара
-		_, err = tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, item.ProductID)
+		// Обновляем остаток товара (у продукта с вариантами - остаток варианта)
+		if item.VariantID != nil {
+			_, err = tx.Exec("UPDATE product_variants SET stock = stock - $1 WHERE id = $2", item.Quantity, *item.VariantID)
+		} else {
+			_, err = tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, item.ProductID)
+		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления остатка товара"})
+			apierror.Internal(c, err)
 			return
 		}
 	}
@@ -113,20 +162,20 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	// Обновляем общую сумму заказа
 	_, err = tx.Exec("UPDATE orders SET total_amount = $1 WHERE id = $2", totalAmount, orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления суммы заказа"})
+		apierror.Internal(c, err)
 		return
 	}
 
 	// Подтверждаем транзакцию
 	if err := tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка подтверждения заказа"})
+		apierror.Internal(c, err)
 		return
 	}
 
 	// Получаем созданный заказ
 	order, err := h.getOrderByID(orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения заказа"})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -143,16 +192,40 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 // @Param limit query int false "Количество элементов на странице" default(10)
 // @Param status query string false "Статус заказа"
 // @Success 200 {object} models.OrderListResponse
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /api/v1/orders [get]
 func (h *OrderHandler) GetOrders(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
 		return
 	}
 
+	h.listOrders(c, userID)
+}
+
+// GetAllOrders получает список заказов всех пользователей
+// @Summary Список всех заказов
+// @Description Возвращает заказы всех пользователей (требует разрешение orders:read_all)
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество элементов на странице" default(10)
+// @Param status query string false "Статус заказа"
+// @Success 200 {object} models.OrderListResponse
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /api/v1/admin/orders [get]
+func (h *OrderHandler) GetAllOrders(c *gin.Context) {
+	h.listOrders(c, nil)
+}
+
+// listOrders отправляет страницу заказов пользователя (userID = nil - всех пользователей)
+func (h *OrderHandler) listOrders(c *gin.Context, userID interface{}) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	status := c.Query("status")
@@ -160,9 +233,15 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 	offset := (page - 1) * limit
 
 	// Формируем SQL запрос
-	whereClause := "WHERE user_id = $1"
-	args := []interface{}{userID}
-	argIndex := 2
+	whereClause := "WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if userID != nil {
+		whereClause += fmt.Sprintf(" AND user_id = $%d", argIndex)
+		args = append(args, userID)
+		argIndex++
+	}
 
 	if status != "" {
 		whereClause += fmt.Sprintf(" AND status = $%d", argIndex)
@@ -175,7 +254,7 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM orders %s", whereClause)
 	err := h.db.QueryRow(countQuery, args...).Scan(&total)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка подсчета заказов"})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -183,7 +262,7 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 	query := fmt.Sprintf(`
 		SELECT id, user_id, status, total_amount, tax_amount, discount_amount, 
 		       shipping_address, billing_address, payment_method, payment_status, 
-		       notes, created_at, updated_at
+		       notes, currency, exchange_rate, created_at, updated_at
 		FROM orders %s
 		ORDER BY created_at DESC
 		LIMIT $%d OFFSET $%d
@@ -192,7 +271,7 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 	args = append(args, limit, offset)
 	rows, err := h.db.Query(query, args...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения заказов"})
+		apierror.Internal(c, err)
 		return
 	}
 	defer rows.Close()
@@ -204,7 +283,7 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 			&order.ID, &order.UserID, &order.Status, &order.TotalAmount,
 			&order.TaxAmount, &order.DiscountAmount, &order.ShippingAddress,
 			&order.BillingAddress, &order.PaymentMethod, &order.PaymentStatus,
-			&order.Notes, &order.CreatedAt, &order.UpdatedAt,
+			&order.Notes, &order.Currency, &order.ExchangeRate, &order.CreatedAt, &order.UpdatedAt,
 		)
 		if err != nil {
 			continue
@@ -229,12 +308,18 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 			PaymentStatus:   order.PaymentStatus,
 			Notes:           order.Notes,
 			Items:           items,
+			Currency:        order.Currency,
+			ExchangeRate:    order.ExchangeRate,
 			CreatedAt:       order.CreatedAt,
 			UpdatedAt:       order.UpdatedAt,
 		}
 
 		orders = append(orders, orderResponse)
 	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
 
 	response := models.OrderListResponse{
 		Orders: orders,
@@ -254,21 +339,21 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path int true "ID заказа"
 // @Success 200 {object} models.OrderResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /api/v1/orders/{id} [get]
 func (h *OrderHandler) GetOrder(c *gin.Context) {
 	orderID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заказа"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidOrderID)
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
 		return
 	}
 
@@ -276,18 +361,18 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 	var orderUserID int
 	err = h.db.QueryRow("SELECT user_id FROM orders WHERE id = $1", orderID).Scan(&orderUserID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Заказ не найден"})
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeOrderNotFound)
 		return
 	}
 
 	if orderUserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeForbidden)
 		return
 	}
 
 	order, err := h.getOrderByID(orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения заказа"})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -296,38 +381,49 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 
 // UpdateOrder обновляет заказ
 // @Summary Обновление заказа
-// @Description Обновляет информацию о заказе (только для админов)
+// @Description Обновляет информацию о заказе (требует разрешение orders:update)
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "ID заказа"
 // @Param order body models.OrderUpdateRequest true "Данные для обновления"
 // @Success 200 {object} models.OrderResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /api/v1/orders/{id} [put]
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /api/v1/admin/orders/{id} [put]
 func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 	orderID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заказа"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidOrderID)
 		return
 	}
 
 	var req models.OrderUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные: " + err.Error()})
+		apierror.RespondValidation(c, err)
 		return
 	}
 
-	// Проверяем, что заказ существует
-	var exists bool
-	err = h.db.QueryRow("SELECT EXISTS(SELECT 1 FROM orders WHERE id = $1)", orderID).Scan(&exists)
-	if err != nil || !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Заказ не найден"})
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	// Блокируем заказ до конца транзакции, чтобы состояние "до" в журнале было точным
+	before, err := scanOrder(tx.QueryRow("SELECT "+orderColumns+" FROM orders WHERE id = $1 FOR UPDATE", orderID))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeOrderNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -369,16 +465,31 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 	query += " WHERE id = $" + strconv.Itoa(argIndex)
 	args = append(args, orderID)
 
-	_, err = h.db.Exec(query, args...)
+	if _, err := tx.Exec(query, args...); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	after, err := scanOrder(tx.QueryRow("SELECT "+orderColumns+" FROM orders WHERE id = $1", orderID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления заказа"})
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditOrderUpdate, models.AuditEntityOrder, orderID, before, after); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
 	// Получаем обновленный заказ
 	order, err := h.getOrderByID(orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения заказа"})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -393,22 +504,22 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path int true "ID заказа"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /api/v1/orders/{id}/cancel [post]
 func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	orderID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заказа"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidOrderID)
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
 		return
 	}
 
@@ -417,25 +528,25 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	var status string
 	err = h.db.QueryRow("SELECT user_id, status FROM orders WHERE id = $1", orderID).Scan(&orderUserID, &status)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Заказ не найден"})
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeOrderNotFound)
 		return
 	}
 
 	if orderUserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeForbidden)
 		return
 	}
 
 	// Проверяем, что заказ можно отменить
 	if status != string(models.OrderStatusPending) && status != string(models.OrderStatusConfirmed) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Заказ нельзя отменить в текущем статусе"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeOrderNotCancellable)
 		return
 	}
 
 	// Начинаем транзакцию
 	tx, err := h.db.Begin()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка базы данных"})
+		apierror.Internal(c, err)
 		return
 	}
 	defer tx.Rollback()
@@ -444,34 +555,43 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	_, err = tx.Exec("UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3",
 		models.OrderStatusCancelled, time.Now(), orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка отмены заказа"})
+		apierror.Internal(c, err)
 		return
 	}
 
 	// Возвращаем товары на склад
-	rows, err := tx.Query("SELECT product_id, quantity FROM order_items WHERE order_id = $1", orderID)
+	rows, err := tx.Query("SELECT product_id, variant_id, quantity FROM order_items WHERE order_id = $1", orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения товаров заказа"})
+		apierror.Internal(c, err)
 		return
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var productID, quantity int
-		if err := rows.Scan(&productID, &quantity); err != nil {
+		var variantID *int
+		if err := rows.Scan(&productID, &variantID, &quantity); err != nil {
 			continue
 		}
 
-		_, err = tx.Exec("UPDATE products SET stock = stock + $1 WHERE id = $2", quantity, productID)
+		if variantID != nil {
+			_, err = tx.Exec("UPDATE product_variants SET stock = stock + $1 WHERE id = $2", quantity, *variantID)
+		} else {
+			_, err = tx.Exec("UPDATE products SET stock = stock + $1 WHERE id = $2", quantity, productID)
+		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка возврата товара на склад"})
+			apierror.Internal(c, err)
 			return
 		}
 	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
 
 	// Подтверждаем транзакцию
 	if err := tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка подтверждения отмены заказа"})
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -480,23 +600,32 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 
 // Вспомогательные методы
 
-// getOrderByID получает заказ по ID с товарами
-func (h *OrderHandler) getOrderByID(orderID int) (*models.OrderResponse, error) {
+// orderColumns список колонок для выборки заказа функцией scanOrder
+const orderColumns = `id, user_id, status, total_amount, tax_amount, discount_amount,
+	shipping_address, billing_address, payment_method, payment_status,
+	notes, currency, exchange_rate, created_at, updated_at`
+
+// scanOrder читает заказ из строки результата с колонками orderColumns
+func scanOrder(row rowScanner) (*models.Order, error) {
 	var order models.Order
-	err := h.db.QueryRow(`
-		SELECT id, user_id, status, total_amount, tax_amount, discount_amount,
-		       shipping_address, billing_address, payment_method, payment_status,
-		       notes, created_at, updated_at
-		FROM orders WHERE id = $1
-	`, orderID).Scan(
+	err := row.Scan(
 		&order.ID, &order.UserID, &order.Status, &order.TotalAmount,
 		&order.TaxAmount, &order.DiscountAmount, &order.ShippingAddress,
 		&order.BillingAddress, &order.PaymentMethod, &order.PaymentStatus,
-		&order.Notes, &order.CreatedAt, &order.UpdatedAt,
+		&order.Notes, &order.Currency, &order.ExchangeRate, &order.CreatedAt, &order.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+	return &order, nil
+}
+
+// getOrderByID получает заказ по ID с товарами
+func (h *OrderHandler) getOrderByID(orderID int) (*models.OrderResponse, error) {
+	order, err := scanOrder(h.db.QueryRow("SELECT "+orderColumns+" FROM orders WHERE id = $1", orderID))
+	if err != nil {
+		return nil, err
+	}
 
 	items, err := h.getOrderItems(orderID)
 	if err != nil {
@@ -516,6 +645,8 @@ func (h *OrderHandler) getOrderByID(orderID int) (*models.OrderResponse, error)
 		PaymentStatus:   order.PaymentStatus,
 		Notes:           order.Notes,
 		Items:           items,
+		Currency:        order.Currency,
+		ExchangeRate:    order.ExchangeRate,
 		CreatedAt:       order.CreatedAt,
 		UpdatedAt:       order.UpdatedAt,
 	}, nil
@@ -525,6 +656,7 @@ func (h *OrderHandler) getOrderByID(orderID int) (*models.OrderResponse, error)
 func (h *OrderHandler) getOrderItems(orderID int) ([]models.OrderItemResponse, error) {
 	rows, err := h.db.Query(`
 		SELECT oi.id, oi.order_id, oi.product_id, oi.quantity, oi.price, oi.discount, oi.total,
+		       oi.variant_id, COALESCE(oi.variant_sku, ''), oi.variant_attributes,
 		       p.name, p.description, p.image_url, p.category_id, p.stock, p.sku, p.is_active, p.created_at, p.updated_at
 		FROM order_items oi
 		JOIN products p ON oi.product_id = p.id
@@ -539,8 +671,11 @@ func (h *OrderHandler) getOrderItems(orderID int) ([]models.OrderItemResponse, e
 	for rows.Next() {
 		var item models.OrderItem
 		var product models.Product
+		var variantSKU string
+		var variantAttributes []byte
 		err := rows.Scan(
 			&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.Price, &item.Discount, &item.Total,
+			&item.VariantID, &variantSKU, &variantAttributes,
 			&product.Name, &product.Description, &product.ImageURL, &product.CategoryID, &product.Stock, &product.SKU, &product.IsActive, &product.CreatedAt, &product.UpdatedAt,
 		)
 		if err != nil {
@@ -570,10 +705,21 @@ func (h *OrderHandler) getOrderItems(orderID int) ([]models.OrderItemResponse, e
 			Price:     item.Price,
 			Discount:  item.Discount,
 			Total:     item.Total,
+
+			VariantID:  item.VariantID,
+			VariantSKU: variantSKU,
+		}
+		if variantAttributes != nil {
+			if err := json.Unmarshal(variantAttributes, &itemResponse.VariantAttributes); err != nil {
+				return nil, err
+			}
 		}
 
 		items = append(items, itemResponse)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return items, nil
 }
@@ -5,61 +5,100 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
+	"api-go/apierror"
 	"api-go/cache"
 	"api-go/models"
+	"api-go/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
 // ProductHandler обрабатывает запросы для работы с продуктами
 type ProductHandler struct {
-	db    *sql.DB
-	cache *cache.ProductCache
+	db           *sql.DB
+	cache        *cache.ProductCache
+	storage      storage.Storage
+	maxImageSize int64 // Максимальный размер загружаемого изображения в байтах
 }
 
 // NewProductHandler создает новый экземпляр ProductHandler
-func NewProductHandler(db *sql.DB, productCache *cache.ProductCache) *ProductHandler {
+func NewProductHandler(db *sql.DB, productCache *cache.ProductCache, store storage.Storage, maxImageSize int64) *ProductHandler {
 	return &ProductHandler{
-		db:    db,
-		cache: productCache,
+		db:           db,
+		cache:        productCache,
+		storage:      store,
+		maxImageSize: maxImageSize,
 	}
 }
 
 // CreateProduct создает новый продукт
 // @Summary Создание продукта
-// @Description Создает новый продукт (требует роль admin)
+// @Description Создает новый продукт (требует разрешение products:create)
 // @Tags products
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param product body models.ProductCreateRequest true "Данные продукта" example({"name":"iPhone 15 Pro","description":"Смартфон Apple с чипом A17 Pro","price":999.99,"category_id":1,"stock":50,"stock_type":"piece","image_url":"https://example.com/iphone15.jpg","sku":"IPHONE15-PRO","color":"Titanium","size":"6.1 inch","is_active":true,"is_featured":true,"sort_order":1})
 // @Success 201 {object} models.ProductResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /products [post]
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	var req models.ProductCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные: " + err.Error()})
+		apierror.RespondValidation(c, err)
 		return
 	}
 
-	var product models.Product
-	err := h.db.QueryRow(`
-			INSERT INTO products (name, description, price, category_id, stock, stock_type, image_url, sku, color, size, is_active, is_featured, sort_order)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
-	RETURNING id, name, description, price, category_id, stock, stock_type, image_url, sku, color, size, is_active, is_featured, sort_order, created_at, updated_at
-	`, req.Name, req.Description, req.Price, req.CategoryID, req.Stock, req.StockType, req.ImageURL, req.SKU, req.Color, req.Size, req.IsActive, req.IsFeatured, req.SortOrder,
-	).Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.CategoryID, &product.Stock, &product.StockType, &product.ImageURL, &product.SKU, &product.Color, &product.Size, &product.IsActive, &product.IsFeatured, &product.SortOrder, &product.CreatedAt, &product.UpdatedAt)
+	currency, ok := productCurrency(c, h.db, req.Currency)
+	if !ok {
+		return
+	}
 
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	product, err := scanProduct(tx.QueryRow(`
+		INSERT INTO products (name, description, price, category_id, stock, stock_type, image_url, sku, color, size, is_active, is_featured, sort_order, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING `+productColumns,
+		req.Name, req.Description, req.Price, req.CategoryID, req.Stock, req.StockType, req.ImageURL, req.SKU, req.Color, req.Size, req.IsActive, req.IsFeatured, req.SortOrder, currency,
+	))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка создания продукта: " + err.Error()})
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordPriceChange(tx, product, nil); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Продукт попадает под действующую акцию своей категории
+	if err := refreshProductPrice(c.Request.Context(), tx, product); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditProductCreate, models.AuditEntityProduct, product.ID, nil, product); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -69,22 +108,25 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	}
 
 	response := models.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		CategoryID:  product.CategoryID,
-		Stock:       product.Stock,
-		StockType:   product.StockType,
-		ImageURL:    product.ImageURL,
-		SKU:         product.SKU,
-		Color:       product.Color,
-		Size:        product.Size,
-		IsActive:    product.IsActive,
-		IsFeatured:  product.IsFeatured,
-		SortOrder:   product.SortOrder,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
+		ID:             product.ID,
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		EffectivePrice: product.EffectivePrice(),
+		SaleEndsAt:     product.SaleEndsAt,
+		Currency:       product.Currency,
+		CategoryID:     product.CategoryID,
+		Stock:          product.Stock,
+		StockType:      product.StockType,
+		ImageURL:       product.ImageURL,
+		SKU:            product.SKU,
+		Color:          product.Color,
+		Size:           product.Size,
+		IsActive:       product.IsActive,
+		IsFeatured:     product.IsFeatured,
+		SortOrder:      product.SortOrder,
+		CreatedAt:      product.CreatedAt,
+		UpdatedAt:      product.UpdatedAt,
 	}
 
 	c.JSON(http.StatusCreated, response)
@@ -92,19 +134,27 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 
 // GetProducts получает список продуктов с пагинацией и фильтрацией
 // @Summary Список продуктов
-// @Description Возвращает список продуктов с пагинацией и фильтрацией
+// @Description Возвращает список продуктов с пагинацией и фильтрацией.
+// @Description Фильтры по характеристикам: attr.<code>=значение (несколько значений через запятую или повтором параметра),
+// @Description для числовых характеристик также attr.<code>.min и attr.<code>.max, например attr.brand=Apple,Samsung&attr.screen_size.min=6.
+// @Description При facets=true в ответ добавляется количество продуктов по значениям характеристик и диапазонам цен.
 // @Tags products
 // @Produce json
 // @Param page query int false "Номер страницы" default(1)
 // @Param limit query int false "Количество элементов на странице" default(10)
 // @Param category_id query string false "Фильтр по ID категории"
-// @Param search query string false "Поиск по названию"
-// @Param min_price query number false "Минимальная цена"
-// @Param max_price query number false "Максимальная цена"
-// @Param sort query string false "Сортировка (name, price, created_at)" default(created_at)
+// @Param search query string false "Полнотекстовый поиск по названию, описанию и SKU (кавычки для фраз, OR, минус для исключения слов)"
+// @Param min_price query number false "Минимальная цена (в валюте ответа, с учетом акций)"
+// @Param max_price query number false "Максимальная цена (в валюте ответа, с учетом акций)"
+// @Param currency query string false "Валюта цен в ответе (по умолчанию - заголовок Accept-Currency или RUB)" example(USD)
+// @Param Accept-Currency header string false "Валюта цен в ответе" example(USD)
+// @Param sort query string false "Сортировка (name, price, created_at, relevance); при поиске по умолчанию relevance" default(created_at)
 // @Param order query string false "Порядок сортировки (asc, desc)" default(desc)
+// @Param facets query bool false "Вернуть фасеты" default(false)
+// @Param price_buckets query string false "Границы диапазонов цен для фасета через запятую" default(1000,5000,10000,50000,100000)
 // @Success 200 {object} models.ProductListResponse
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /products [get]
 func (h *ProductHandler) GetProducts(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -115,15 +165,38 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	maxPrice := c.Query("max_price")
 	sort := c.DefaultQuery("sort", "created_at")
 	order := c.DefaultQuery("order", "desc")
+	withFacets := c.Query("facets") == "true"
+
+	minPriceValue, ok := parsePriceParam(c, "min_price", minPrice)
+	if !ok {
+		return
+	}
+	maxPriceValue, ok := parsePriceParam(c, "max_price", maxPrice)
+	if !ok {
+		return
+	}
+
+	// Цены возвращаются в запрошенной валюте; в кэше они хранятся в валютах продуктов
+	rates, err := loadExchangeRates(h.db)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	currency, ok := requestCurrency(c, rates)
+	if !ok {
+		return
+	}
 
-	log.Printf("DEBUG: Sort: %s, Order: %s", sort, order)
+	// В кэше хранится полный список продуктов: он подходит только для выборки без фильтров, кроме категории
+	cacheable := search == "" && minPrice == "" && maxPrice == "" && !withFacets && !hasAttributeFilters(c.Request.URL.Query())
 
 	// Проверяем кэш
-	if h.cache != nil {
-		log.Printf("DEBUG: Checking cache for page=%d, limit=%d, category_id=%s", page, limit, categoryID)
+	if h.cache != nil && cacheable {
 		cachedProducts, err := h.cache.GetProducts(c.Request.Context(), page, limit, categoryID)
 		if err == nil {
-			log.Printf("DEBUG: Cache HIT, returning %d products", len(cachedProducts))
+			for i := range cachedProducts {
+				convertProductPrices(&cachedProducts[i], currency, rates)
+			}
 			c.Header("X-Cache", "HIT")
 			c.JSON(http.StatusOK, models.ProductListResponse{
 				Products: cachedProducts,
@@ -133,47 +206,54 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 			})
 			return
 		}
-		log.Printf("DEBUG: Cache MISS, error: %v", err)
 	}
 
 	c.Header("X-Cache", "MISS")
 
 	offset := (page - 1) * limit
 
-	// Формируем SQL запрос
-	whereClause := "WHERE p.is_active = true"
-	args := []interface{}{}
-	argIndex := 1
+	// Формируем условия фильтра; ключ фильтра исключает его при подсчете собственного фасета
+	var filters []productFilter
 
 	if categoryID != "" {
-		whereClause += fmt.Sprintf(" AND category_id = $%d", argIndex)
-		args = append(args, categoryID)
-		argIndex++
+		filters = append(filters, productFilter{key: "category", cond: "p.category_id = %[1]s", args: []interface{}{categoryID}})
 	}
 
 	if search != "" {
-		whereClause += fmt.Sprintf(" AND (name ILIKE $%d OR description ILIKE $%d)", argIndex, argIndex)
-		args = append(args, "%"+search+"%", "%"+search+"%")
-		argIndex++
+		filters = append(filters, searchFilter(search))
 	}
 
 	if minPrice != "" {
-		whereClause += fmt.Sprintf(" AND price >= $%d", argIndex)
-		args = append(args, minPrice)
-		argIndex++
+		filters = append(filters, productFilter{key: "price", cond: effectivePriceSQL + " >= %[1]s::numeric * %[2]s", args: []interface{}{minPriceValue, rates[currency]}})
 	}
 
 	if maxPrice != "" {
-		whereClause += fmt.Sprintf(" AND price <= $%d", argIndex)
-		args = append(args, maxPrice)
-		argIndex++
+		filters = append(filters, productFilter{key: "price", cond: effectivePriceSQL + " <= %[1]s::numeric * %[2]s", args: []interface{}{maxPriceValue, rates[currency]}})
 	}
 
-	// Безопасная сортировка
+	attributeFilters, ok := parseAttributeFilters(c, h.db)
+	if !ok {
+		return
+	}
+	filters = append(filters, attributeFilters...)
+
+	priceBuckets, ok := parsePriceBuckets(c.Query("price_buckets"))
+	if !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, "price_buckets")
+		return
+	}
+
+	whereClause, args := productWhere(filters, "")
+	argIndex := len(args) + 1
+
+	// Безопасная сортировка; при поиске по умолчанию - по релевантности
 	safeSort := "id"
 	if sort == "created_at" || sort == "updated_at" || sort == "price" || sort == "name" {
 		safeSort = sort
 	}
+	if search != "" && (sort == "relevance" || c.Query("sort") == "") {
+		safeSort = "relevance"
+	}
 
 	safeOrder := "ASC"
 	if order == "desc" {
@@ -183,71 +263,83 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	// Получаем общее количество продуктов
 	var total int
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products p %s", whereClause)
-	log.Printf("DEBUG: Count Query: %s", countQuery)
-	log.Printf("DEBUG: Count Args: %v", args)
-
-	err := h.db.QueryRow(countQuery, args...).Scan(&total)
+	err = h.db.QueryRow(countQuery, args...).Scan(&total)
 	if err != nil {
-		log.Printf("DEBUG: Error counting products: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка подсчета продуктов"})
+		apierror.Internal(c, err)
 		return
 	}
-	log.Printf("DEBUG: Total products found: %d", total)
+
+	// Учитываем запрос в статистике поиска (для подсказок и отчета о запросах без результатов).
+	// С другими фильтрами total отражает не только поиск, поэтому такие выборки не учитываются
+	if search != "" && page == 1 && len(filters) == 1 {
+		if err := recordSearchQuery(h.db, search, total); err != nil {
+			log.Printf("Ошибка записи статистики поиска: %v", err)
+		}
+	}
+
+	// При поиске добавляем подсветку найденных слов и сортировку по релевантности
+	orderBy := safeSort + " " + safeOrder
+	if safeSort == "price" {
+		orderBy = effectivePriceSQL + " " + safeOrder
+	}
+	headlineColumns := "NULL, NULL"
+	if search != "" {
+		args = append(args, search)
+		headlineColumns = searchHeadlineColumns(argIndex)
+		if safeSort == "relevance" {
+			orderBy = searchRank(argIndex) + " DESC, p.id"
+		}
+		argIndex++
+	}
 
 	// Получаем продукты
 	query := fmt.Sprintf(`
-		SELECT p.id, p.name, p.description, p.price, COALESCE(p.category_id, 0), p.stock, COALESCE(p.stock_type, 'piece'), COALESCE(p.image_url, ''), COALESCE(p.sku, ''), COALESCE(p.color, ''), COALESCE(p.size, ''), p.is_active, p.is_featured, p.sort_order, p.created_at, p.updated_at, c.slug as category_slug
+		SELECT p.id, p.name, p.description, p.price, COALESCE(p.category_id, 0), p.stock, COALESCE(p.stock_type, 'piece'), COALESCE(p.image_url, ''), COALESCE(p.sku, ''), COALESCE(p.color, ''), COALESCE(p.size, ''), p.is_active, p.is_featured, p.sort_order, p.created_at, p.updated_at, p.sale_price, p.sale_ends_at, p.currency, c.slug as category_slug, %s
 		FROM products p
 		LEFT JOIN categories c ON p.category_id = c.id
 		%s
-		ORDER BY %s %s
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, safeSort, safeOrder, argIndex, argIndex+1)
-
-	// Логируем SQL запрос для отладки
-	log.Printf("DEBUG: Sort: %s, Order: %s", sort, order)
-	log.Printf("DEBUG: Safe Sort: %s, Safe Order: %s", safeSort, safeOrder)
-	log.Printf("DEBUG: SQL Query: %s", query)
-	log.Printf("DEBUG: Args: %v", args)
-	log.Printf("DEBUG: Final Args: %v", append(args, limit, offset))
+	`, headlineColumns, whereClause, orderBy, argIndex, argIndex+1)
 
 	args = append(args, limit, offset)
 	rows, err := h.db.Query(query, args...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения продуктов"})
+		apierror.Internal(c, err)
 		return
 	}
 	defer rows.Close()
 
 	var products []models.ProductResponse
-	log.Printf("DEBUG: Starting to scan rows...")
 	for rows.Next() {
 		var product models.Product
-		var categorySlug sql.NullString
-		err := rows.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.CategoryID, &product.Stock, &product.StockType, &product.ImageURL, &product.SKU, &product.Color, &product.Size, &product.IsActive, &product.IsFeatured, &product.SortOrder, &product.CreatedAt, &product.UpdatedAt, &categorySlug)
+		var categorySlug, nameHeadline, descriptionHeadline sql.NullString
+		err := rows.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.CategoryID, &product.Stock, &product.StockType, &product.ImageURL, &product.SKU, &product.Color, &product.Size, &product.IsActive, &product.IsFeatured, &product.SortOrder, &product.CreatedAt, &product.UpdatedAt, &product.SalePrice, &product.SaleEndsAt, &product.Currency, &categorySlug, &nameHeadline, &descriptionHeadline)
 		if err != nil {
-			log.Printf("DEBUG: Error scanning row: %v", err)
-			continue
+			apierror.Internal(c, err)
+			return
 		}
-		log.Printf("DEBUG: Scanned product: ID=%d, Name=%s", product.ID, product.Name)
 
 		response := models.ProductResponse{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			CategoryID:  product.CategoryID,
-			Stock:       product.Stock,
-			StockType:   product.StockType,
-			ImageURL:    product.ImageURL,
-			SKU:         product.SKU,
-			Color:       product.Color,
-			Size:        product.Size,
-			IsActive:    product.IsActive,
-			IsFeatured:  product.IsFeatured,
-			SortOrder:   product.SortOrder,
-			CreatedAt:   product.CreatedAt,
-			UpdatedAt:   product.UpdatedAt,
+			ID:             product.ID,
+			Name:           product.Name,
+			Description:    product.Description,
+			Price:          product.Price,
+			EffectivePrice: product.EffectivePrice(),
+			SaleEndsAt:     product.SaleEndsAt,
+			Currency:       product.Currency,
+			CategoryID:     product.CategoryID,
+			Stock:          product.Stock,
+			StockType:      product.StockType,
+			ImageURL:       product.ImageURL,
+			SKU:            product.SKU,
+			Color:          product.Color,
+			Size:           product.Size,
+			IsActive:       product.IsActive,
+			IsFeatured:     product.IsFeatured,
+			SortOrder:      product.SortOrder,
+			CreatedAt:      product.CreatedAt,
+			UpdatedAt:      product.UpdatedAt,
 		}
 
 		// Заполняем slug категории
@@ -255,8 +347,21 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 			response.CategorySlug = categorySlug.String
 		}
 
+		// Подсветка найденных слов
+		if nameHeadline.Valid {
+			response.Highlight = &models.ProductHighlight{
+				Name:        nameHeadline.String,
+				Description: descriptionHeadline.String,
+			}
+		}
+
+		convertProductPrices(&response, currency, rates)
 		products = append(products, response)
 	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
 
 	// Сохраняем все продукты в кэш (если кэш пустой)
 	if h.cache != nil {
@@ -265,10 +370,10 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 		if err != nil {
 			// Кэш пустой, получаем все продукты с категориями и сохраняем
 			allProductsQuery := `
-				SELECT p.id, p.name, p.description, p.price, COALESCE(p.category_id, 0), p.stock, COALESCE(p.stock_type, 'piece'), COALESCE(p.image_url, ''), COALESCE(p.sku, ''), COALESCE(p.color, ''), COALESCE(p.size, ''), p.is_active, p.is_featured, p.sort_order, p.created_at, p.updated_at, c.slug as category_slug
+				SELECT p.id, p.name, p.description, p.price, COALESCE(p.category_id, 0), p.stock, COALESCE(p.stock_type, 'piece'), COALESCE(p.image_url, ''), COALESCE(p.sku, ''), COALESCE(p.color, ''), COALESCE(p.size, ''), p.is_active, p.is_featured, p.sort_order, p.created_at, p.updated_at, p.sale_price, p.sale_ends_at, p.currency, c.slug as category_slug
 				FROM products p
 				LEFT JOIN categories c ON p.category_id = c.id
-				WHERE p.is_active = true
+				WHERE p.is_active = true AND p.deleted_at IS NULL
 				ORDER BY p.id ASC
 			`
 			allRows, err := h.db.Query(allProductsQuery)
@@ -278,25 +383,28 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 				for allRows.Next() {
 					var product models.Product
 					var categorySlug sql.NullString
-					err := allRows.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.CategoryID, &product.Stock, &product.StockType, &product.ImageURL, &product.SKU, &product.Color, &product.Size, &product.IsActive, &product.IsFeatured, &product.SortOrder, &product.CreatedAt, &product.UpdatedAt, &categorySlug)
+					err := allRows.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.CategoryID, &product.Stock, &product.StockType, &product.ImageURL, &product.SKU, &product.Color, &product.Size, &product.IsActive, &product.IsFeatured, &product.SortOrder, &product.CreatedAt, &product.UpdatedAt, &product.SalePrice, &product.SaleEndsAt, &product.Currency, &categorySlug)
 					if err == nil {
 						response := models.ProductResponse{
-							ID:          product.ID,
-							Name:        product.Name,
-							Description: product.Description,
-							Price:       product.Price,
-							CategoryID:  product.CategoryID,
-							Stock:       product.Stock,
-							StockType:   product.StockType,
-							ImageURL:    product.ImageURL,
-							SKU:         product.SKU,
-							Color:       product.Color,
-							Size:        product.Size,
-							IsActive:    product.IsActive,
-							IsFeatured:  product.IsFeatured,
-							SortOrder:   product.SortOrder,
-							CreatedAt:   product.CreatedAt,
-							UpdatedAt:   product.UpdatedAt,
+							ID:             product.ID,
+							Name:           product.Name,
+							Description:    product.Description,
+							Price:          product.Price,
+							EffectivePrice: product.EffectivePrice(),
+							SaleEndsAt:     product.SaleEndsAt,
+							Currency:       product.Currency,
+							CategoryID:     product.CategoryID,
+							Stock:          product.Stock,
+							StockType:      product.StockType,
+							ImageURL:       product.ImageURL,
+							SKU:            product.SKU,
+							Color:          product.Color,
+							Size:           product.Size,
+							IsActive:       product.IsActive,
+							IsFeatured:     product.IsFeatured,
+							SortOrder:      product.SortOrder,
+							CreatedAt:      product.CreatedAt,
+							UpdatedAt:      product.UpdatedAt,
 						}
 						// Добавляем slug категории в response для фильтрации
 						if categorySlug.Valid {
@@ -305,9 +413,10 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 						allProducts = append(allProducts, response)
 					}
 				}
-				// Сохраняем все продукты в кэш
-				h.cache.SetProducts(c.Request.Context(), allProducts)
-				log.Printf("DEBUG: Все продукты сохранены в кэш: %d штук", len(allProducts))
+				// Сохраняем все продукты в кэш, неполный список не кэшируем
+				if allRows.Err() == nil {
+					h.cache.SetProducts(c.Request.Context(), allProducts)
+				}
 			}
 		}
 	}
@@ -319,24 +428,58 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 		Limit:    limit,
 	}
 
+	if withFacets {
+		response.Facets, err = h.productFacets(filters, categoryID, priceBuckets, rates[currency])
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
+// parsePriceParam разбирает границу цены из параметра запроса name; пустое значение границу не задает.
+// При неверном значении отвечает 400 и возвращает false.
+func parsePriceParam(c *gin.Context, name, value string) (float64, bool) {
+	if value == "" {
+		return 0, true
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(price) || math.IsInf(price, 0) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, name)
+		return 0, false
+	}
+	return price, true
+}
+
 // GetProduct получает продукт по ID
 // @Summary Получение продукта по ID
-// @Description Возвращает информацию о продукте по его ID
+// @Description Возвращает информацию о продукте по его ID вместе с характеристиками, активными вариантами и матрицей их атрибутов
 // @Tags products
 // @Produce json
 // @Param id path int true "ID продукта"
+// @Param currency query string false "Валюта цен в ответе (по умолчанию - заголовок Accept-Currency или RUB)" example(USD)
+// @Param Accept-Currency header string false "Валюта цен в ответе" example(USD)
 // @Success 200 {object} models.ProductResponse
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /products/{id} [get]
 func (h *ProductHandler) GetProduct(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID продукта"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	rates, err := loadExchangeRates(h.db)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	currency, ok := requestCurrency(c, rates)
+	if !ok {
 		return
 	}
 
@@ -344,6 +487,7 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	if h.cache != nil {
 		cachedProduct, err := h.cache.GetProduct(c.Request.Context(), id)
 		if err == nil {
+			convertProductPrices(cachedProduct, currency, rates)
 			c.Header("X-Cache", "HIT")
 			c.JSON(http.StatusOK, cachedProduct)
 			return
@@ -354,98 +498,135 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 
 	var product models.Product
 	err = h.db.QueryRow(`
-		SELECT id, name, description, price, COALESCE(category_id, 0), stock, COALESCE(stock_type, 'piece'), COALESCE(image_url, ''), COALESCE(sku, ''), COALESCE(color, ''), COALESCE(size, ''), is_active, is_featured, sort_order, created_at, updated_at
-		FROM products WHERE id = $1 AND is_active = true
-	`, id).Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.CategoryID, &product.Stock, &product.StockType, &product.ImageURL, &product.SKU, &product.Color, &product.Size, &product.IsActive, &product.IsFeatured, &product.SortOrder, &product.CreatedAt, &product.UpdatedAt)
+		SELECT id, name, description, price, COALESCE(category_id, 0), stock, COALESCE(stock_type, 'piece'), COALESCE(image_url, ''), COALESCE(sku, ''), COALESCE(color, ''), COALESCE(size, ''), is_active, is_featured, sort_order, created_at, updated_at, sale_price, sale_ends_at, currency
+		FROM products WHERE id = $1 AND is_active = true AND deleted_at IS NULL
+	`, id).Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.CategoryID, &product.Stock, &product.StockType, &product.ImageURL, &product.SKU, &product.Color, &product.Size, &product.IsActive, &product.IsFeatured, &product.SortOrder, &product.CreatedAt, &product.UpdatedAt, &product.SalePrice, &product.SaleEndsAt, &product.Currency)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Продукт не найден"})
+			apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения продукта"})
+			apierror.Internal(c, err)
 		}
 		return
 	}
 
+	response := models.ProductResponse{
+		ID:             product.ID,
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		EffectivePrice: product.EffectivePrice(),
+		SaleEndsAt:     product.SaleEndsAt,
+		Currency:       product.Currency,
+		CategoryID:     product.CategoryID,
+		Stock:          product.Stock,
+		StockType:      product.StockType,
+		ImageURL:       product.ImageURL,
+		SKU:            product.SKU,
+		Color:          product.Color,
+		Size:           product.Size,
+		IsActive:       product.IsActive,
+		IsFeatured:     product.IsFeatured,
+		SortOrder:      product.SortOrder,
+		CreatedAt:      product.CreatedAt,
+		UpdatedAt:      product.UpdatedAt,
+	}
+
+	// Добавляем матрицу вариантов
+	variants, err := getProductVariants(h.db, id, true)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if len(variants) > 0 {
+		response.Options = variantOptions(variants)
+		response.Variants = variants
+	}
+
+	// Добавляем характеристики
+	attributes, err := getProductAttributes(h.db, id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if len(attributes) > 0 {
+		response.Attributes = attributes
+	}
+
+	// Добавляем галерею
+	images, err := h.getProductImages(h.db, id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if len(images) > 0 {
+		response.Images = images
+	}
+
 	// Сохраняем в кэш
 	if h.cache != nil {
-		productResponse := models.ProductResponse{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			CategoryID:  product.CategoryID,
-			Stock:       product.Stock,
-			StockType:   product.StockType,
-			ImageURL:    product.ImageURL,
-			SKU:         product.SKU,
-			Color:       product.Color,
-			Size:        product.Size,
-			IsActive:    product.IsActive,
-			IsFeatured:  product.IsFeatured,
-			SortOrder:   product.SortOrder,
-			CreatedAt:   product.CreatedAt,
-			UpdatedAt:   product.UpdatedAt,
-		}
-		h.cache.SetProduct(c.Request.Context(), productResponse)
+		h.cache.SetProduct(c.Request.Context(), response)
 	}
 
-	response := models.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		CategoryID:  product.CategoryID,
-		Stock:       product.Stock,
-		StockType:   product.StockType,
-		ImageURL:    product.ImageURL,
-		SKU:         product.SKU,
-		Color:       product.Color,
-		Size:        product.Size,
-		IsActive:    product.IsActive,
-		IsFeatured:  product.IsFeatured,
-		SortOrder:   product.SortOrder,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
-	}
+	convertProductPrices(&response, currency, rates)
 
 	c.JSON(http.StatusOK, response)
 }
 
 // UpdateProduct обновляет продукт
 // @Summary Обновление продукта
-// @Description Обновляет информацию о продукте (требует роль admin)
+// @Description Обновляет информацию о продукте (требует разрешение products:update)
 // @Tags products
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "ID продукта"
 // @Param product body models.ProductUpdateRequest true "Данные для обновления" example({"name":"iPhone 15 Pro Updated","price":899.99,"stock":45,"stock_type":"piece","is_active":true})
 // @Success 200 {object} models.ProductResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
 // @Router /products/{id} [put]
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID продукта"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
 		return
 	}
 
 	var req models.ProductUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные: " + err.Error()})
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	if req.Currency != nil {
+		currency, ok := productCurrency(c, h.db, *req.Currency)
+		if !ok {
+			return
+		}
+		req.Currency = &currency
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
 		return
 	}
+	defer tx.Rollback()
 
-	// Проверяем, что продукт существует
-	var exists bool
-	err = h.db.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", id).Scan(&exists)
-	if err != nil || !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Продукт не найден"})
+	// Блокируем продукт до конца транзакции, чтобы состояние "до" в журнале было точным
+	before, err := scanProduct(tx.QueryRow("SELECT "+productColumns+" FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -472,6 +653,12 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		argIndex++
 	}
 
+	if req.Currency != nil {
+		query += fmt.Sprintf(", currency = $%d", argIndex)
+		args = append(args, *req.Currency)
+		argIndex++
+	}
+
 	if req.CategoryID != nil {
 		query += fmt.Sprintf(", category_id = $%d", argIndex)
 		args = append(args, *req.CategoryID)
@@ -535,21 +722,36 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	query += " WHERE id = $" + strconv.Itoa(argIndex)
 	args = append(args, id)
 
-	_, err = h.db.Exec(query, args...)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления продукта"})
+	if _, err := tx.Exec(query, args...); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
 	// Получаем обновленный продукт
-	var product models.Product
-	err = h.db.QueryRow(`
-		SELECT id, name, description, price, category_id, stock, stock_type, image_url, sku, color, size, is_active, is_featured, sort_order, created_at, updated_at
-		FROM products WHERE id = $1
-	`, id).Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.CategoryID, &product.Stock, &product.StockType, &product.ImageURL, &product.SKU, &product.Color, &product.Size, &product.IsActive, &product.IsFeatured, &product.SortOrder, &product.CreatedAt, &product.UpdatedAt)
-
+	product, err := scanProduct(tx.QueryRow("SELECT "+productColumns+" FROM products WHERE id = $1", id))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения обновленного продукта"})
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Скидка акции зависит от базовой цены и категории
+	if err := refreshProductPrice(c.Request.Context(), tx, product); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordProductRevision(tx, c, models.RevisionActionUpdate, before, product); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditProductUpdate, models.AuditEntityProduct, id, before, product); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -559,64 +761,96 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	}
 
 	response := models.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		CategoryID:  product.CategoryID,
-		Stock:       product.Stock,
-		StockType:   product.StockType,
-		ImageURL:    product.ImageURL,
-		SKU:         product.SKU,
-		Color:       product.Color,
-		Size:        product.Size,
-		IsActive:    product.IsActive,
-		IsFeatured:  product.IsFeatured,
-		SortOrder:   product.SortOrder,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
+		ID:             product.ID,
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		EffectivePrice: product.EffectivePrice(),
+		SaleEndsAt:     product.SaleEndsAt,
+		Currency:       product.Currency,
+		CategoryID:     product.CategoryID,
+		Stock:          product.Stock,
+		StockType:      product.StockType,
+		ImageURL:       product.ImageURL,
+		SKU:            product.SKU,
+		Color:          product.Color,
+		Size:           product.Size,
+		IsActive:       product.IsActive,
+		IsFeatured:     product.IsFeatured,
+		SortOrder:      product.SortOrder,
+		CreatedAt:      product.CreatedAt,
+		UpdatedAt:      product.UpdatedAt,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// DeleteProduct удаляет продукт
+// DeleteProduct удаляет продукт в корзину
 // @Summary Удаление продукта
-// @Description Удаляет продукт из системы (требует роль admin)
+// @Description Перемещает продукт в корзину удаленных (требует разрешение products:delete).
+// @Description Продукт пропадает из каталога, корзин покупателей и избранного, но остается в заказах и отзывах
+// @Description и может быть восстановлен (POST /admin/products/{id}/restore). Из базы его удаляет команда products purge.
 // @Tags products
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "ID продукта"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
 // @Router /products/{id} [delete]
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	// Получаем ID из URL
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID продукта"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
 		return
 	}
 
-	// Проверяем, существует ли продукт
-	var existingProduct models.Product
-	err = h.db.QueryRow("SELECT id FROM products WHERE id = $1", id).Scan(&existingProduct.ID)
+	tx, err := h.db.Begin()
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Продукт не найден"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка проверки продукта"})
+		apierror.Internal(c, err)
 		return
 	}
+	defer tx.Rollback()
 
-	// Удаляем продукт
-	_, err = h.db.Exec("DELETE FROM products WHERE id = $1", id)
+	// Проверяем, существует ли продукт; состояние до удаления сохраняется в журнале
+	before, err := scanProduct(tx.QueryRow("SELECT "+productColumns+" FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка удаления продукта"})
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Помечаем продукт удаленным; строка остается для заказов, отзывов и восстановления
+	product, err := scanProduct(tx.QueryRow("UPDATE products SET deleted_at = $1 WHERE id = $2 RETURNING "+productColumns, time.Now(), id))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Удаленный продукт больше нельзя купить: убираем его из корзин и избранного
+	if _, err := tx.Exec("DELETE FROM cart_items WHERE product_id = $1", id); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if _, err := tx.Exec("DELETE FROM wishlist WHERE product_id = $1", id); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditProductDelete, models.AuditEntityProduct, id, before, product); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
 		return
 	}
 
@@ -627,5 +861,188 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 		c.Header("X-Cache-Invalidation", "failed")
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Продукт успешно удален"})
+	c.JSON(http.StatusOK, gin.H{"message": "Продукт перемещен в корзину"})
+}
+
+// GetDeletedProducts возвращает корзину удаленных продуктов
+// @Summary Удаленные продукты
+// @Description Возвращает продукты в корзине удаленных, последние удаленные - первыми (требует разрешение products:delete)
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество элементов на странице" default(10)
+// @Success 200 {object} models.ProductListResponse
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/products/trash [get]
+func (h *ProductHandler) GetDeletedProducts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	var total int
+	if err := h.db.QueryRow("SELECT COUNT(*) FROM products WHERE deleted_at IS NOT NULL").Scan(&total); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	rows, err := h.db.Query(`
+		SELECT `+productColumns+`
+		FROM products
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $1 OFFSET $2`, limit, (page-1)*limit)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	products := []models.ProductResponse{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		products = append(products, productResponse(product))
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ProductListResponse{
+		Products: products,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	})
+}
+
+// RestoreProduct восстанавливает продукт из корзины
+// @Summary Восстановление продукта
+// @Description Возвращает удаленный продукт в каталог с прежними вариантами, характеристиками и галереей (требует разрешение products:delete).
+// @Description Корзины покупателей и избранное не восстанавливаются.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Success 200 {object} models.ProductResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/products/{id}/restore [post]
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := scanProduct(tx.QueryRow("SELECT "+productColumns+" FROM products WHERE id = $1 FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if before.DeletedAt == nil {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeProductNotDeleted)
+		return
+	}
+
+	product, err := scanProduct(tx.QueryRow("UPDATE products SET deleted_at = NULL WHERE id = $1 RETURNING "+productColumns, id))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditProductRestore, models.AuditEntityProduct, id, before, product); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Продукт снова появляется в списках: сбрасываем их кэш
+	if h.cache != nil {
+		h.cache.InvalidateProductCache(c.Request.Context(), id)
+	}
+
+	c.JSON(http.StatusOK, productResponse(product))
+}
+
+// productResponse ответ с основными полями продукта (без вариантов, характеристик и галереи)
+func productResponse(product *models.Product) models.ProductResponse {
+	return models.ProductResponse{
+		ID:             product.ID,
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		EffectivePrice: product.EffectivePrice(),
+		SaleEndsAt:     product.SaleEndsAt,
+		Currency:       product.Currency,
+		CategoryID:     product.CategoryID,
+		Stock:          product.Stock,
+		StockType:      product.StockType,
+		ImageURL:       product.ImageURL,
+		SKU:            product.SKU,
+		Color:          product.Color,
+		Size:           product.Size,
+		IsActive:       product.IsActive,
+		IsFeatured:     product.IsFeatured,
+		SortOrder:      product.SortOrder,
+		CreatedAt:      product.CreatedAt,
+		UpdatedAt:      product.UpdatedAt,
+		DeletedAt:      product.DeletedAt,
+	}
+}
+
+// effectivePriceSQL цена продукта p с учетом действующей акции в базовой валюте:
+// по ней фильтруются и сортируются продукты с ценами в разных валютах
+const effectivePriceSQL = "COALESCE(p.sale_price, p.price) * (SELECT er.rate FROM exchange_rates er WHERE er.currency = p.currency)"
+
+// productColumns список колонок для выборки продукта функцией scanProduct
+const productColumns = `id, name, COALESCE(description, ''), price, category_id, stock, COALESCE(stock_type, 'piece'),
+	COALESCE(image_url, ''), COALESCE(sku, ''), COALESCE(color, ''), COALESCE(size, ''),
+	is_active, is_featured, sort_order, created_at, updated_at, deleted_at, sale_price, sale_ends_at, currency`
+
+// scanProduct читает продукт из строки результата с колонками productColumns
+func scanProduct(row rowScanner) (*models.Product, error) {
+	var product models.Product
+	err := row.Scan(
+		&product.ID, &product.Name, &product.Description, &product.Price, &product.CategoryID, &product.Stock, &product.StockType,
+		&product.ImageURL, &product.SKU, &product.Color, &product.Size,
+		&product.IsActive, &product.IsFeatured, &product.SortOrder, &product.CreatedAt, &product.UpdatedAt,
+		&product.DeletedAt, &product.SalePrice, &product.SaleEndsAt, &product.Currency,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
 }
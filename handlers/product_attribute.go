@@ -0,0 +1,565 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"api-go/apierror"
+	"api-go/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// attributeFilterPrefix префикс параметров фильтра по характеристикам: attr.<code>, attr.<code>.min, attr.<code>.max
+const attributeFilterPrefix = "attr."
+
+// defaultPriceBuckets границы диапазонов цен для фасета, если price_buckets не указан
+var defaultPriceBuckets = []float64{1000, 5000, 10000, 50000, 100000}
+
+// maxFacetValues ограничение количества значений в фасете характеристики
+const maxFacetValues = 100
+
+// productFilter условие фильтра списка продуктов
+type productFilter struct {
+	key  string        // фасет с тем же ключом считается без этого условия
+	cond string        // условие; %[n]s заменяется номером n-го параметра из args
+	args []interface{} // параметры условия
+}
+
+// attributeFilter значения фильтра по одной характеристике из параметров запроса
+type attributeFilter struct {
+	values   []string
+	min, max string
+}
+
+// productWhere формирует WHERE для списка продуктов из фильтров, кроме фильтра с ключом skip
+func productWhere(filters []productFilter, skip string) (string, []interface{}) {
+	whereClause := "WHERE p.is_active = true AND p.deleted_at IS NULL"
+	args := []interface{}{}
+	for _, f := range filters {
+		if skip != "" && f.key == skip {
+			continue
+		}
+		placeholders := make([]interface{}, len(f.args))
+		for i, arg := range f.args {
+			args = append(args, arg)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		whereClause += " AND " + fmt.Sprintf(f.cond, placeholders...)
+	}
+	return whereClause, args
+}
+
+// hasAttributeFilters сообщает, есть ли в запросе фильтры по характеристикам
+func hasAttributeFilters(query url.Values) bool {
+	for key := range query {
+		if strings.HasPrefix(key, attributeFilterPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAttributeFilters разбирает параметры attr.* в условия фильтра.
+// При неверном параметре отправляет ошибку и возвращает ok = false.
+func parseAttributeFilters(c *gin.Context, db *sql.DB) (filters []productFilter, ok bool) {
+	byCode := map[string]*attributeFilter{}
+	for key, values := range c.Request.URL.Query() {
+		if !strings.HasPrefix(key, attributeFilterPrefix) {
+			continue
+		}
+		code, bound := strings.TrimPrefix(key, attributeFilterPrefix), ""
+		if i := strings.LastIndexByte(code, '.'); i >= 0 {
+			code, bound = code[:i], code[i+1:]
+		}
+
+		f := byCode[code]
+		if f == nil {
+			f = &attributeFilter{}
+			byCode[code] = f
+		}
+		switch bound {
+		case "":
+			// Несколько значений: attr.brand=Apple&attr.brand=Samsung или attr.brand=Apple,Samsung
+			for _, v := range values {
+				for _, part := range strings.Split(v, ",") {
+					if part = strings.TrimSpace(part); part != "" {
+						f.values = append(f.values, part)
+					}
+				}
+			}
+		case "min":
+			f.min = values[0]
+		case "max":
+			f.max = values[0]
+		default:
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, key)
+			return nil, false
+		}
+	}
+	if len(byCode) == 0 {
+		return nil, true
+	}
+
+	codes := make([]string, 0, len(byCode))
+	for code := range byCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	attributes, err := queryAttributes(db, fmt.Sprintf(
+		"SELECT %s FROM attribute_definitions ad WHERE ad.code = ANY($1) AND ad.is_filterable = true", attributeColumns),
+		pq.Array(codes))
+	if err != nil {
+		apierror.Internal(c, err)
+		return nil, false
+	}
+	definitions := make(map[string]models.AttributeDefinition, len(attributes))
+	for _, attribute := range attributes {
+		definitions[attribute.Code] = attribute
+	}
+
+	for _, code := range codes {
+		definition, found := definitions[code]
+		if !found {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeUnknownAttribute, code)
+			return nil, false
+		}
+		filter, badParam := attributeFilterCondition(definition, byCode[code])
+		if badParam != "" {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, badParam)
+			return nil, false
+		}
+		if filter != nil {
+			filters = append(filters, *filter)
+		}
+	}
+	return filters, true
+}
+
+// attributeFilterCondition строит условие фильтра по характеристике с учетом ее типа.
+// Для параметра без значений возвращает nil, для неверного значения - имя параметра в badParam.
+func attributeFilterCondition(definition models.AttributeDefinition, f *attributeFilter) (filter *productFilter, badParam string) {
+	if len(f.values) == 0 && f.min == "" && f.max == "" {
+		// Параметр без значений: attr.brand=
+		return nil, ""
+	}
+
+	param := attributeFilterPrefix + definition.Code
+	filter = &productFilter{
+		key:  "attr:" + definition.Code,
+		cond: "EXISTS (SELECT 1 FROM product_attribute_values pav WHERE pav.product_id = p.id AND pav.attribute_id = %[1]s",
+		args: []interface{}{definition.ID},
+	}
+
+	switch definition.Type {
+	case models.AttributeTypeNumber:
+		if len(f.values) > 0 {
+			numbers := make([]float64, len(f.values))
+			for i, v := range f.values {
+				number, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return nil, param
+				}
+				numbers[i] = number
+			}
+			filter.args = append(filter.args, pq.Array(numbers))
+			filter.cond += fmt.Sprintf(" AND pav.value_number = ANY(%%[%d]s::numeric[])", len(filter.args))
+		}
+		for _, bound := range []struct{ value, param, op string }{{f.min, param + ".min", ">="}, {f.max, param + ".max", "<="}} {
+			if bound.value == "" {
+				continue
+			}
+			number, err := strconv.ParseFloat(bound.value, 64)
+			if err != nil {
+				return nil, bound.param
+			}
+			filter.args = append(filter.args, number)
+			filter.cond += fmt.Sprintf(" AND pav.value_number %s %%[%d]s", bound.op, len(filter.args))
+		}
+	case models.AttributeTypeBoolean:
+		if f.min != "" || f.max != "" || len(f.values) != 1 {
+			return nil, param
+		}
+		value, err := strconv.ParseBool(f.values[0])
+		if err != nil {
+			return nil, param
+		}
+		filter.args = append(filter.args, value)
+		filter.cond += " AND pav.value_boolean = %[2]s"
+	default:
+		if f.min != "" || f.max != "" {
+			return nil, param
+		}
+		filter.args = append(filter.args, pq.Array(f.values))
+		filter.cond += " AND pav.value_text = ANY(%[2]s)"
+	}
+
+	filter.cond += ")"
+	return filter, ""
+}
+
+// parsePriceBuckets разбирает границы диапазонов цен: возрастающие числа через запятую
+func parsePriceBuckets(value string) ([]float64, bool) {
+	if value == "" {
+		return defaultPriceBuckets, true
+	}
+	parts := strings.Split(value, ",")
+	edges := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		edge, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || (len(edges) > 0 && edge <= edges[len(edges)-1]) {
+			return nil, false
+		}
+		edges = append(edges, edge)
+	}
+	return edges, len(edges) > 0 && len(edges) <= 20
+}
+
+// productFacets считает фасеты для списка продуктов. Для характеристики учитываются все фильтры,
+// кроме фильтра по ней самой, чтобы клиент мог показать альтернативные значения.
+func (h *ProductHandler) productFacets(filters []productFilter, categoryID string, priceBuckets []float64, rate float64) (*models.ProductFacets, error) {
+	var attributes []models.AttributeDefinition
+	var err error
+	if id, convErr := strconv.Atoi(categoryID); convErr == nil {
+		attributes, err = getCategoryAttributes(h.db, id, true)
+	} else {
+		attributes, err = queryAttributes(h.db, fmt.Sprintf(
+			"SELECT %s FROM attribute_definitions ad WHERE ad.is_filterable = true ORDER BY ad.sort_order, ad.name", attributeColumns))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	facets := &models.ProductFacets{Attributes: []models.AttributeFacet{}}
+	for _, attribute := range attributes {
+		facet, err := h.attributeFacet(filters, attribute)
+		if err != nil {
+			return nil, err
+		}
+		if len(facet.Values) > 0 {
+			facets.Attributes = append(facets.Attributes, *facet)
+		}
+	}
+
+	facets.Price, err = h.priceFacet(filters, priceBuckets, rate)
+	if err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// attributeFacet считает количество продуктов по значениям характеристики
+func (h *ProductHandler) attributeFacet(filters []productFilter, attribute models.AttributeDefinition) (*models.AttributeFacet, error) {
+	whereClause, args := productWhere(filters, "attr:"+attribute.Code)
+	args = append(args, attribute.ID)
+
+	// Строковые и логические значения - по убыванию количества, числовые - по возрастанию с диапазоном
+	value, bounds, order := "pav.value_text", "NULL, NULL", "2 DESC, 1"
+	switch attribute.Type {
+	case models.AttributeTypeNumber:
+		value, order = "pav.value_number", "1"
+		bounds = "MIN(pav.value_number) OVER (), MAX(pav.value_number) OVER ()"
+	case models.AttributeTypeBoolean:
+		value = "pav.value_boolean"
+	}
+
+	rows, err := h.db.Query(fmt.Sprintf(`
+		SELECT %[1]s::text, COUNT(*), %[2]s
+		FROM products p
+		JOIN product_attribute_values pav ON pav.product_id = p.id AND pav.attribute_id = $%[3]d
+		%[4]s
+		GROUP BY %[1]s
+		ORDER BY %[5]s
+		LIMIT %[6]d`, value, bounds, len(args), whereClause, order, maxFacetValues), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facet := &models.AttributeFacet{
+		Code:   attribute.Code,
+		Name:   attribute.Name,
+		Type:   attribute.Type,
+		Unit:   attribute.Unit,
+		Values: []models.FacetValue{},
+	}
+	for rows.Next() {
+		var v models.FacetValue
+		var min, max sql.NullFloat64
+		if err := rows.Scan(&v.Value, &v.Count, &min, &max); err != nil {
+			return nil, err
+		}
+		if attribute.Type == models.AttributeTypeNumber {
+			v.Value = formatAttributeNumber(v.Value)
+		}
+		if min.Valid && facet.Min == nil {
+			facet.Min, facet.Max = &min.Float64, &max.Float64
+		}
+		facet.Values = append(facet.Values, v)
+	}
+	return facet, rows.Err()
+}
+
+// priceFacet считает количество продуктов в диапазонах цен; границы edges заданы в валюте с курсом rate
+func (h *ProductHandler) priceFacet(filters []productFilter, edges []float64, rate float64) ([]models.PriceBucket, error) {
+	whereClause, args := productWhere(filters, "price")
+	args = append(args, rate, pq.Array(edges))
+
+	rows, err := h.db.Query(fmt.Sprintf(`
+		SELECT width_bucket(%s / $%d, $%d::numeric[]), COUNT(*)
+		FROM products p
+		%s
+		GROUP BY 1`, effectivePriceSQL, len(args)-1, len(args), whereClause), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Диапазон i: [edges[i-1], edges[i]), первый без нижней границы, последний без верхней
+	buckets := make([]models.PriceBucket, len(edges)+1)
+	for i := range buckets {
+		if i > 0 {
+			buckets[i].From = &edges[i-1]
+		}
+		if i < len(edges) {
+			buckets[i].To = &edges[i]
+		}
+	}
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+		if bucket >= 0 && bucket < len(buckets) {
+			buckets[bucket].Count = count
+		}
+	}
+	return buckets, rows.Err()
+}
+
+// formatAttributeNumber убирает незначащие нули из числа, сохраненного как DECIMAL
+func formatAttributeNumber(value string) string {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// SetProductAttributes задает значения характеристик продукта
+// @Summary Характеристики продукта
+// @Description Заменяет значения характеристик продукта. Ключ - код характеристики из набора категории продукта,
+// @Description значение - строка, число или true/false в зависимости от типа; null удаляет значение (требует разрешение products:update)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param attributes body models.ProductAttributesRequest true "Значения характеристик" example({"values":{"brand":"Apple","screen_size":6.1,"nfc":true}})
+// @Success 200 {array} models.ProductAttributeValue
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /products/{id}/attributes [put]
+func (h *ProductHandler) SetProductAttributes(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	var req models.ProductAttributesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	var categoryID sql.NullInt64
+	err = tx.QueryRow("SELECT category_id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", productID).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	codes := make([]string, 0, len(req.Values))
+	for code := range req.Values {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	// Характеристики из запроса и их принадлежность категории продукта
+	rows, err := tx.Query(`
+		SELECT ad.id, ad.code, ad.type,
+		       EXISTS(SELECT 1 FROM category_attributes ca WHERE ca.attribute_id = ad.id AND ca.category_id = $2)
+		FROM attribute_definitions ad WHERE ad.code = ANY($1)`,
+		pq.Array(codes), categoryID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	type definition struct {
+		id         int
+		typ        string
+		inCategory bool
+	}
+	definitions := map[string]definition{}
+	for rows.Next() {
+		var code string
+		var d definition
+		if err := rows.Scan(&d.id, &code, &d.typ, &d.inCategory); err != nil {
+			rows.Close()
+			apierror.Internal(c, err)
+			return
+		}
+		definitions[code] = d
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	before, err := getProductAttributes(tx, productID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if _, err := tx.Exec("DELETE FROM product_attribute_values WHERE product_id = $1", productID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	for _, code := range codes {
+		d, found := definitions[code]
+		if !found {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeUnknownAttribute, code)
+			return
+		}
+		if !d.inCategory {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeAttributeNotInCategory, code)
+			return
+		}
+
+		value := req.Values[code]
+		if value == nil {
+			continue
+		}
+
+		var text, number, boolean interface{}
+		switch v := value.(type) {
+		case string:
+			if d.typ == models.AttributeTypeString && v != "" && len([]rune(v)) <= 255 {
+				text = v
+			}
+		case float64:
+			if d.typ == models.AttributeTypeNumber {
+				number = v
+			}
+		case bool:
+			if d.typ == models.AttributeTypeBoolean {
+				boolean = v
+			}
+		}
+		if text == nil && number == nil && boolean == nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidAttributeValue, code, d.typ)
+			return
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO product_attribute_values (product_id, attribute_id, value_text, value_number, value_boolean)
+			VALUES ($1, $2, $3, $4, $5)`,
+			productID, d.id, text, number, boolean)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+	}
+
+	after, err := getProductAttributes(tx, productID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	err = recordAudit(tx, c, models.AuditProductUpdate, models.AuditEntityProduct, productID,
+		attributesAudit(before), attributesAudit(after))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	h.invalidateProduct(c, productID)
+
+	c.JSON(http.StatusOK, after)
+}
+
+// attributesAudit представление характеристик продукта для журнала: {"attributes": {"код": значение}}
+func attributesAudit(attributes []models.ProductAttributeValue) map[string]interface{} {
+	values := make(map[string]interface{}, len(attributes))
+	for _, attribute := range attributes {
+		values[attribute.Code] = attribute.Value
+	}
+	return map[string]interface{}{"attributes": values}
+}
+
+// getProductAttributes получает значения характеристик продукта
+func getProductAttributes(db dbExecutor, productID int) ([]models.ProductAttributeValue, error) {
+	rows, err := db.Query(`
+		SELECT ad.code, ad.name, ad.type, ad.unit, pav.value_text, pav.value_number, pav.value_boolean
+		FROM product_attribute_values pav
+		JOIN attribute_definitions ad ON ad.id = pav.attribute_id
+		WHERE pav.product_id = $1
+		ORDER BY ad.sort_order, ad.name`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []models.ProductAttributeValue{}
+	for rows.Next() {
+		var attribute models.ProductAttributeValue
+		var text sql.NullString
+		var number sql.NullFloat64
+		var boolean sql.NullBool
+		if err := rows.Scan(&attribute.Code, &attribute.Name, &attribute.Type, &attribute.Unit, &text, &number, &boolean); err != nil {
+			return nil, err
+		}
+		switch {
+		case text.Valid:
+			attribute.Value = text.String
+		case number.Valid:
+			attribute.Value = number.Float64
+		case boolean.Valid:
+			attribute.Value = boolean.Bool
+		}
+		attributes = append(attributes, attribute)
+	}
+	return attributes, rows.Err()
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"api-go/apierror"
+	"api-go/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// GetProductHistory возвращает ревизии и историю цен продукта
+// @Summary История продукта
+// @Description Возвращает ревизии продукта (состояние до каждого изменения, новые первыми) и периоды действия цен.
+// @Description С параметром at в ответ добавляется цена, действовавшая в этот момент. Удаленные продукты тоже доступны.
+// @Description Требует разрешение products:update.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param page query int false "Номер страницы ревизий" default(1)
+// @Param limit query int false "Количество ревизий на странице" default(20)
+// @Param at query string false "Момент, на который нужна цена (RFC 3339 или YYYY-MM-DD)"
+// @Success 200 {object} models.ProductHistoryResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/products/{id}/history [get]
+func (h *ProductHandler) GetProductHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var exists bool
+	if err := h.db.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", id).Scan(&exists); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !exists {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+
+	response := models.ProductHistoryResponse{
+		Revisions: []models.ProductRevision{},
+		Page:      page,
+		Limit:     limit,
+		Prices:    []models.PricePeriod{},
+	}
+
+	if value := c.Query("at"); value != "" {
+		at, err := parseAuditTime(value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, "at")
+			return
+		}
+		var price float64
+		var currency string
+		err = h.db.QueryRow(`
+			SELECT price, currency FROM price_history
+			WHERE product_id = $1 AND valid_from <= $2 AND (valid_to IS NULL OR valid_to > $2)
+			ORDER BY valid_from DESC
+			LIMIT 1`, id, at).Scan(&price, &currency)
+		if err != nil && err != sql.ErrNoRows {
+			apierror.Internal(c, err)
+			return
+		}
+		if err == nil {
+			response.PriceAt = &price
+			response.CurrencyAt = currency
+		}
+	}
+
+	if err := h.db.QueryRow("SELECT COUNT(*) FROM product_revisions WHERE product_id = $1", id).Scan(&response.Total); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	rows, err := h.db.Query(`
+		SELECT `+revisionColumns+`
+		FROM product_revisions
+		WHERE product_id = $1
+		ORDER BY revision DESC
+		LIMIT $2 OFFSET $3`, id, limit, (page-1)*limit)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		response.Revisions = append(response.Revisions, *revision)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	priceRows, err := h.db.Query(`
+		SELECT price, currency, valid_from, valid_to
+		FROM price_history
+		WHERE product_id = $1
+		ORDER BY valid_from DESC, id DESC`, id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer priceRows.Close()
+
+	for priceRows.Next() {
+		var period models.PricePeriod
+		if err := priceRows.Scan(&period.Price, &period.Currency, &period.ValidFrom, &period.ValidTo); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		response.Prices = append(response.Prices, period)
+	}
+	if err := priceRows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RollbackProduct возвращает продукт к состоянию ревизии
+// @Summary Откат продукта к ревизии
+// @Description Восстанавливает поля продукта, включая валюту цены, из ревизии (GET /admin/products/{id}/history). Остаток и основное изображение не откатываются:
+// @Description их меняют заказы и галерея. Откат сохраняется как новая ревизия, поэтому его тоже можно отменить.
+// @Description Требует разрешение products:update.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param request body models.ProductRollbackRequest true "Номер ревизии"
+// @Success 200 {object} models.ProductResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/products/{id}/rollback [post]
+func (h *ProductHandler) RollbackProduct(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	var req models.ProductRollbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := scanProduct(tx.QueryRow("SELECT "+productColumns+" FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	revision, err := scanRevision(tx.QueryRow("SELECT "+revisionColumns+" FROM product_revisions WHERE product_id = $1 AND revision = $2", id, req.Revision))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeRevisionNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Категорию ревизии могли удалить после изменения продукта
+	target := revision.Product
+	if target.CategoryID != nil {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", *target.CategoryID).Scan(&exists); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		if !exists {
+			apierror.Respond(c, http.StatusConflict, apierror.CodeRevisionCategoryMissing)
+			return
+		}
+	}
+
+	// В ревизиях, сохраненных до появления валют, валюты нет; курс валюты ревизии могли удалить
+	if target.Currency == "" {
+		target.Currency = before.Currency
+	}
+	var currencyExists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM exchange_rates WHERE currency = $1)", target.Currency).Scan(&currencyExists); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !currencyExists {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeRevisionCurrencyMissing)
+		return
+	}
+
+	product, err := scanProduct(tx.QueryRow(`
+		UPDATE products SET name = $1, description = $2, price = $3, category_id = $4, stock_type = $5,
+			sku = $6, color = $7, size = $8, is_active = $9, is_featured = $10, sort_order = $11, currency = $12
+		WHERE id = $13
+		RETURNING `+productColumns,
+		target.Name, target.Description, target.Price, target.CategoryID, target.StockType,
+		target.SKU, target.Color, target.Size, target.IsActive, target.IsFeatured, target.SortOrder, target.Currency, id,
+	))
+	if isUniqueViolation(err) {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeRevisionSKUTaken)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := refreshProductPrice(c.Request.Context(), tx, product); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordProductRevision(tx, c, models.RevisionActionRollback, before, product); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditProductRollback, models.AuditEntityProduct, id, before, product); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Инвалидируем кэш продуктов
+	if h.cache != nil {
+		h.cache.InvalidateProductCache(c.Request.Context(), id)
+	}
+
+	c.JSON(http.StatusOK, productResponse(product))
+}
+
+// recordProductRevision сохраняет состояние продукта до изменения и, если изменилась цена,
+// закрывает период прежней цены. Ничего не делает, если продукт не изменился.
+// Вызывается в транзакции, где продукт заблокирован (FOR UPDATE): номер ревизии берется из MAX.
+func recordProductRevision(tx dbExecutor, c *gin.Context, action string, before, after *models.Product) error {
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	data, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+
+	var changedBy sql.NullInt64
+	if id, ok := c.Get("user_id"); ok {
+		changedBy = sql.NullInt64{Int64: int64(id.(int)), Valid: true}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO product_revisions (product_id, revision, action, data, changed_fields, changed_by, changed_by_username)
+		VALUES ($1, (SELECT COALESCE(MAX(revision), 0) + 1 FROM product_revisions WHERE product_id = $1), $2, $3, $4, $5, $6)`,
+		before.ID, action, data, pq.Array(fields), changedBy, c.GetString("username"))
+	if err != nil {
+		return err
+	}
+
+	if before.Price != after.Price || before.Currency != after.Currency {
+		return recordPriceChange(tx, after, before)
+	}
+	return nil
+}
+
+// recordPriceChange закрывает текущий период цены продукта и открывает новый с его текущей ценой и валютой.
+// previous - продукт до изменения (nil при создании): если период его цены не был записан
+// (продукт создан до появления истории цен), он восстанавливается начиная с previous.UpdatedAt.
+func recordPriceChange(tx dbExecutor, product, previous *models.Product) error {
+	result, err := tx.Exec("UPDATE price_history SET valid_to = CURRENT_TIMESTAMP WHERE product_id = $1 AND valid_to IS NULL", product.ID)
+	if err != nil {
+		return err
+	}
+	closed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if closed == 0 && previous != nil {
+		_, err = tx.Exec(`
+			INSERT INTO price_history (product_id, price, currency, valid_from, valid_to)
+			VALUES ($1, $2, $3, LEAST($4, CURRENT_TIMESTAMP), CURRENT_TIMESTAMP)`,
+			product.ID, previous.Price, previous.Currency, previous.UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("INSERT INTO price_history (product_id, price, currency, valid_from) VALUES ($1, $2, $3, CURRENT_TIMESTAMP)",
+		product.ID, product.Price, product.Currency)
+	return err
+}
+
+// revisionColumns список колонок для выборки ревизии функцией scanRevision
+const revisionColumns = `revision, action, data, changed_fields, changed_by, changed_by_username, created_at`
+
+// scanRevision читает ревизию из строки результата с колонками revisionColumns
+func scanRevision(row rowScanner) (*models.ProductRevision, error) {
+	var revision models.ProductRevision
+	var data []byte
+	err := row.Scan(
+		&revision.Revision, &revision.Action, &data, pq.Array(&revision.ChangedFields),
+		&revision.ChangedBy, &revision.ChangedByUsername, &revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &revision.Product); err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
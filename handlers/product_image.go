@@ -0,0 +1,632 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"api-go/apierror"
+	"api-go/imaging"
+	"api-go/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// imageFormOverhead запас на заголовки и поля формы сверх максимального размера файла
+const imageFormOverhead = 64 << 10
+
+// imageColumns список колонок для выборки изображения функцией scanImage
+const imageColumns = `id, product_id, storage_key, content_type, width, height, size_bytes,
+	thumbnails, alt_text, sort_order, created_at`
+
+// imageRecord изображение продукта вместе с ключами его файлов в хранилище
+type imageRecord struct {
+	models.ProductImage
+	storageKey string
+	thumbnails map[string]string // Ключи миниатюр по размерам
+}
+
+// keys возвращает ключи оригинала и всех миниатюр
+func (r *imageRecord) keys() []string {
+	keys := []string{r.storageKey}
+	for _, key := range r.thumbnails {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// productImageForm поля формы загрузки изображения, кроме самого файла
+type productImageForm struct {
+	AltText string `form:"alt_text" binding:"max=255"`
+}
+
+// UploadProductImage загружает изображение продукта
+// @Summary Загрузка изображения продукта
+// @Description Загружает изображение (JPEG, PNG или GIF) в конец галереи продукта и создает миниатюры small (160px), medium (480px) и large (1200px).
+// @Description Формат определяется по содержимому файла. Первое изображение галереи становится основным (image_url продукта).
+// @Description Требует разрешение products:update.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param file formData file true "Изображение"
+// @Param alt_text formData string false "Подпись для людей с нарушениями зрения и поисковиков"
+// @Success 201 {object} models.ProductImage
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 413 {object} apierror.Problem
+// @Failure 415 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /products/{id}/images [post]
+func (h *ProductHandler) UploadProductImage(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	// Тело запроса ограничивается до разбора формы, чтобы большой файл не попал на диск
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxImageSize+imageFormOverhead)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apierror.Respond(c, http.StatusRequestEntityTooLarge, apierror.CodeImageTooLarge, h.maxImageSize>>20)
+			return
+		}
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeImageRequired)
+		return
+	}
+	defer file.Close()
+
+	var form productImageForm
+	if err := c.ShouldBindWith(&form, binding.FormMultipart); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	if header.Size > h.maxImageSize {
+		apierror.Respond(c, http.StatusRequestEntityTooLarge, apierror.CodeImageTooLarge, h.maxImageSize>>20)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, h.maxImageSize+1))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if int64(len(data)) > h.maxImageSize {
+		apierror.Respond(c, http.StatusRequestEntityTooLarge, apierror.CodeImageTooLarge, h.maxImageSize>>20)
+		return
+	}
+
+	img, err := imaging.Decode(data)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedType):
+		apierror.Respond(c, http.StatusUnsupportedMediaType, apierror.CodeImageTypeNotAllowed)
+		return
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeImageTooManyPixels, imaging.MaxPixels/1_000_000)
+		return
+	case err != nil:
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidImage)
+		return
+	}
+
+	// Проверяем продукт до сохранения файлов; в транзакции проверка повторяется под блокировкой
+	var exists bool
+	if err := h.db.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)", productID).Scan(&exists); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !exists {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+
+	// Сохраняем оригинал и миниатюры; если изображение не попадет в БД, файлы удаляются
+	record, err := h.storeImage(c.Request.Context(), productID, img, data)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			h.deleteImageFiles(record.keys())
+		}
+	}()
+
+	thumbnails, err := json.Marshal(record.thumbnails)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	var locked int
+	err = tx.QueryRow("SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", productID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	saved, err := scanImage(tx.QueryRow(`
+		INSERT INTO product_images (product_id, storage_key, content_type, width, height, size_bytes, thumbnails, alt_text, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+			(SELECT COALESCE(MAX(sort_order) + 1, 0) FROM product_images WHERE product_id = $1))
+		RETURNING `+imageColumns,
+		productID, record.storageKey, img.ContentType, img.Width, img.Height, len(data), thumbnails, form.AltText))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	image := h.imageResponse(saved)
+
+	if err := recordAudit(tx, c, models.AuditImageCreate, models.AuditEntityImage, image.ID, nil, image); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.syncPrimaryImage(tx, productID, ""); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	committed = true
+
+	h.invalidateProduct(c, productID)
+
+	c.JSON(http.StatusCreated, image)
+}
+
+// UpdateProductImage изменяет подпись изображения продукта
+// @Summary Изменение изображения продукта
+// @Description Изменяет подпись (alt) изображения; порядок изображений меняется через PUT /products/{id}/images (требует разрешение products:update)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param image_id path int true "ID изображения"
+// @Param image body models.ProductImageUpdateRequest true "Новая подпись"
+// @Success 200 {object} models.ProductImage
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /products/{id}/images/{image_id} [put]
+func (h *ProductHandler) UpdateProductImage(c *gin.Context) {
+	productID, imageID, ok := imageParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.ProductImageUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	record, err := getImage(tx, imageID, "FOR UPDATE")
+	if err == sql.ErrNoRows || (err == nil && record.ProductID != productID) {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeImageNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	before := h.imageResponse(record)
+
+	updated, err := scanImage(tx.QueryRow(
+		"UPDATE product_images SET alt_text = $1 WHERE id = $2 RETURNING "+imageColumns, req.AltText, imageID))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	image := h.imageResponse(updated)
+
+	if err := recordAudit(tx, c, models.AuditImageUpdate, models.AuditEntityImage, imageID, before, image); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	h.invalidateProduct(c, productID)
+
+	c.JSON(http.StatusOK, image)
+}
+
+// ReorderProductImages задает порядок изображений продукта
+// @Summary Порядок изображений продукта
+// @Description Задает порядок галереи: передаются ID всех изображений продукта в нужном порядке, первое становится основным (требует разрешение products:update)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param order body models.ProductImageOrderRequest true "ID изображений в новом порядке"
+// @Success 200 {array} models.ProductImage
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /products/{id}/images [put]
+func (h *ProductHandler) ReorderProductImages(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	var req models.ProductImageOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	var locked int
+	err = tx.QueryRow("SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", productID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	current, err := h.getProductImages(tx, productID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Новый порядок должен быть перестановкой текущих изображений
+	before := make([]int, len(current))
+	known := make(map[int]bool, len(current))
+	for i, image := range current {
+		before[i] = image.ID
+		known[image.ID] = true
+	}
+	if len(req.ImageIDs) != len(current) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidImageOrder)
+		return
+	}
+	for _, id := range req.ImageIDs {
+		if !known[id] {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidImageOrder)
+			return
+		}
+		delete(known, id)
+	}
+
+	for i, id := range req.ImageIDs {
+		if _, err := tx.Exec("UPDATE product_images SET sort_order = $1 WHERE id = $2", i, id); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+	}
+
+	err = recordAudit(tx, c, models.AuditProductUpdate, models.AuditEntityProduct, productID,
+		map[string]interface{}{"images": before}, map[string]interface{}{"images": req.ImageIDs})
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.syncPrimaryImage(tx, productID, ""); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	images, err := h.getProductImages(tx, productID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	h.invalidateProduct(c, productID)
+
+	c.JSON(http.StatusOK, images)
+}
+
+// DeleteProductImage удаляет изображение продукта
+// @Summary Удаление изображения продукта
+// @Description Удаляет изображение и его миниатюры из хранилища; основным становится следующее изображение галереи (требует разрешение products:update)
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param image_id path int true "ID изображения"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /products/{id}/images/{image_id} [delete]
+func (h *ProductHandler) DeleteProductImage(c *gin.Context) {
+	productID, imageID, ok := imageParams(c)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	// Блокируем продукт, чтобы параллельные изменения галереи не разошлись с image_url
+	var locked int
+	err = tx.QueryRow("SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", productID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeImageNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	record, err := getImage(tx, imageID, "FOR UPDATE")
+	if err == sql.ErrNoRows || (err == nil && record.ProductID != productID) {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeImageNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	image := h.imageResponse(record)
+
+	if _, err := tx.Exec("DELETE FROM product_images WHERE id = $1", imageID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditImageDelete, models.AuditEntityImage, imageID, image, nil); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.syncPrimaryImage(tx, productID, image.URL); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Файлы удаляются после фиксации: при ошибке останется лишний файл, а не битая ссылка
+	h.deleteImageFiles(record.keys())
+	h.invalidateProduct(c, productID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Изображение успешно удалено"})
+}
+
+// storeImage сохраняет оригинал и миниатюры в хранилище под случайным именем.
+// При ошибке удаляет уже сохраненные файлы.
+func (h *ProductHandler) storeImage(ctx context.Context, productID int, img *imaging.Image, data []byte) (*imageRecord, error) {
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return nil, err
+	}
+	base := fmt.Sprintf("products/%d/%s", productID, hex.EncodeToString(name))
+
+	record := &imageRecord{
+		storageKey: base + img.Ext,
+		thumbnails: make(map[string]string, len(imaging.ThumbnailSizes)),
+	}
+	if err := h.storage.Put(ctx, record.storageKey, data, img.ContentType); err != nil {
+		return nil, err
+	}
+
+	for _, size := range imaging.ThumbnailSizes {
+		thumb, ok := img.Thumbnail(size.Max)
+		if !ok {
+			continue
+		}
+
+		thumbData, contentType, ext, err := img.Encode(thumb)
+		if err == nil {
+			key := base + "_" + size.Name + ext
+			if err = h.storage.Put(ctx, key, thumbData, contentType); err == nil {
+				record.thumbnails[size.Name] = key
+				continue
+			}
+		}
+
+		h.deleteImageFiles(record.keys())
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// deleteImageFiles удаляет файлы изображения из хранилища. Ошибки только логируются:
+// запись в БД уже удалена или не создана, а лишний файл не мешает работе.
+func (h *ProductHandler) deleteImageFiles(keys []string) {
+	for _, key := range keys {
+		if err := h.storage.Delete(context.Background(), key); err != nil {
+			log.Printf("Ошибка удаления файла %s из хранилища: %v", key, err)
+		}
+	}
+}
+
+// syncPrimaryImage копирует адрес первого изображения галереи в products.image_url,
+// чтобы его видели списки продуктов и клиенты, не знающие о галерее.
+// Если галерея опустела, image_url очищается, только если указывал на удаленное изображение removedURL.
+func (h *ProductHandler) syncPrimaryImage(tx dbExecutor, productID int, removedURL string) error {
+	var key string
+	err := tx.QueryRow(`
+		SELECT storage_key FROM product_images
+		WHERE product_id = $1
+		ORDER BY sort_order, id
+		LIMIT 1`, productID).Scan(&key)
+	if err == sql.ErrNoRows {
+		if removedURL == "" {
+			return nil
+		}
+		_, err = tx.Exec("UPDATE products SET image_url = NULL WHERE id = $1 AND image_url = $2", productID, removedURL)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE products SET image_url = $2 WHERE id = $1 AND image_url IS DISTINCT FROM $2", productID, h.storage.URL(key))
+	return err
+}
+
+// imageResponse заполняет публичные адреса изображения и миниатюр.
+// Размеры, для которых миниатюра не создавалась (изображение меньше), указывают на оригинал.
+func (h *ProductHandler) imageResponse(record *imageRecord) models.ProductImage {
+	image := record.ProductImage
+	image.URL = h.storage.URL(record.storageKey)
+	image.Thumbnails = make(map[string]string, len(imaging.ThumbnailSizes))
+	for _, size := range imaging.ThumbnailSizes {
+		if key, ok := record.thumbnails[size.Name]; ok {
+			image.Thumbnails[size.Name] = h.storage.URL(key)
+		} else {
+			image.Thumbnails[size.Name] = image.URL
+		}
+	}
+	return image
+}
+
+// getProductImages получает галерею продукта в порядке показа
+func (h *ProductHandler) getProductImages(db dbExecutor, productID int) ([]models.ProductImage, error) {
+	records, err := getProductImageRecords(db, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]models.ProductImage, len(records))
+	for i, record := range records {
+		images[i] = h.imageResponse(record)
+	}
+	return images, nil
+}
+
+// getProductImageRecords получает изображения продукта вместе с ключами файлов
+func getProductImageRecords(db dbExecutor, productID int) ([]*imageRecord, error) {
+	rows, err := db.Query("SELECT "+imageColumns+" FROM product_images WHERE product_id = $1 ORDER BY sort_order, id", productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []*imageRecord{}
+	for rows.Next() {
+		record, err := scanImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// getImage получает изображение по ID; lock добавляется в конец запроса (например, FOR UPDATE)
+func getImage(db dbExecutor, imageID int, lock ...string) (*imageRecord, error) {
+	query := "SELECT " + imageColumns + " FROM product_images WHERE id = $1"
+	for _, clause := range lock {
+		query += " " + clause
+	}
+	return scanImage(db.QueryRow(query, imageID))
+}
+
+// scanImage читает изображение из строки результата с колонками imageColumns
+func scanImage(row rowScanner) (*imageRecord, error) {
+	var record imageRecord
+	var thumbnails []byte
+	err := row.Scan(
+		&record.ID, &record.ProductID, &record.storageKey, &record.ContentType, &record.Width, &record.Height, &record.SizeBytes,
+		&thumbnails, &record.AltText, &record.SortOrder, &record.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(thumbnails, &record.thumbnails); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// imageParams разбирает ID продукта и изображения из пути.
+// При неверном значении отправляет ошибку и возвращает ok = false.
+func imageParams(c *gin.Context) (productID, imageID int, ok bool) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return 0, 0, false
+	}
+
+	imageID, err = strconv.Atoi(c.Param("image_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidImageID)
+		return 0, 0, false
+	}
+
+	return productID, imageID, true
+}
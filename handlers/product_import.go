@@ -0,0 +1,966 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-go/apierror"
+	"api-go/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Ограничения импорта продуктов
+const (
+	importMaxFileSize   = 50 << 20
+	importMaxRows       = 100000
+	importSyncRows      = 1000 // Файлы с большим числом строк обрабатываются в фоне
+	importMaxErrors     = 1000 // Сколько строк с ошибками сохраняется в задаче
+	importProgressEvery = 200  // Как часто фоновая задача сохраняет прогресс, в строках
+	exportFlushEvery    = 500  // Как часто выгрузка отправляет накопленные строки клиенту
+)
+
+// productFileColumns колонки файла импорта и выгрузки в порядке выгрузки
+var productFileColumns = []string{
+	"sku", "name", "description", "price", "currency", "category_id", "category_slug", "stock", "stock_type",
+	"image_url", "color", "size", "is_active", "is_featured", "sort_order",
+}
+
+// importRow разобранная строка файла импорта
+type importRow struct {
+	line   int
+	data   models.ProductImportRow
+	errors []apierror.FieldError // Ошибки разбора; строка с ними не импортируется
+}
+
+// importOutcome результат импорта строки
+type importOutcome int
+
+const (
+	importCreated importOutcome = iota
+	importUpdated
+	importUnchanged
+	importRejected
+)
+
+// importFileError ошибка файла импорта целиком: файл не обрабатывается
+type importFileError struct {
+	status int
+	code   apierror.Code
+	args   []interface{}
+}
+
+func (e *importFileError) Error() string {
+	return string(e.code)
+}
+
+// ImportProducts импортирует продукты из CSV или JSON Lines
+// @Summary Импорт продуктов
+// @Description Создает и изменяет продукты из файла CSV (первая строка - названия колонок) или JSON Lines (объект на строку).
+// @Description Продукт ищется по SKU: если его нет, он создается (нужны name и price), иначе меняются только поля, заданные в строке.
+// @Description Колонки: sku, name, description, price, currency, category_id, category_slug, stock, stock_type, image_url, color, size, is_active, is_featured, sort_order.
+// @Description Строки с ошибками пропускаются и перечисляются в errors задачи. При dry_run=true каталог не меняется, а в задаче считается, сколько продуктов было бы создано и изменено.
+// @Description Файл до 1000 строк обрабатывается сразу (200), больший - в фоне (202), статус задачи - GET /admin/products/import/{id}.
+// @Description Требует разрешение products:import.
+// @Tags products
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param file formData file false "Файл импорта (или передайте файл телом запроса)"
+// @Param format query string false "Формат файла; по умолчанию определяется по расширению или Content-Type" Enums(csv, jsonl)
+// @Param dry_run query bool false "Только проверить файл, не меняя каталог"
+// @Success 200 {object} models.ProductImportJob
+// @Success 202 {object} models.ProductImportJob
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 413 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/products/import [post]
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	lang := apierror.Language(c)
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	// Файл передается полем формы или телом запроса
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, importMaxFileSize)
+	var (
+		body        io.Reader = c.Request.Body
+		fileName    string
+		contentType = c.ContentType()
+	)
+	if strings.HasPrefix(contentType, "multipart/") {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				apierror.Respond(c, http.StatusRequestEntityTooLarge, apierror.CodeImportFileTooLarge, importMaxFileSize>>20)
+				return
+			}
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeImportFileRequired)
+			return
+		}
+		defer file.Close()
+		body, fileName, contentType = file, filepath.Base(header.Filename), header.Header.Get("Content-Type")
+	}
+
+	format, ok := importFormat(c.Query("format"), fileName, contentType)
+	if !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidFileFormat)
+		return
+	}
+
+	var rows []importRow
+	var err error
+	if format == models.FileFormatCSV {
+		rows, err = parseCSVImport(body, lang)
+	} else {
+		rows, err = parseJSONLImport(body, lang)
+	}
+	var fileErr *importFileError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &fileErr):
+		apierror.Respond(c, fileErr.status, fileErr.code, fileErr.args...)
+		return
+	case errors.As(err, &tooLarge):
+		apierror.Respond(c, http.StatusRequestEntityTooLarge, apierror.CodeImportFileTooLarge, importMaxFileSize>>20)
+		return
+	case err != nil:
+		apierror.Internal(c, err)
+		return
+	}
+	if len(rows) == 0 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeImportEmpty)
+		return
+	}
+
+	var createdBy *int
+	if id, ok := c.Get("user_id"); ok {
+		userID := id.(int)
+		createdBy = &userID
+	}
+
+	job := &models.ProductImportJob{
+		Status:    models.ImportStatusPending,
+		Format:    format,
+		DryRun:    dryRun,
+		FileName:  truncateRunes(fileName, 255),
+		TotalRows: len(rows),
+		Errors:    []models.ProductImportRowError{},
+		CreatedBy: createdBy,
+	}
+	err = h.db.QueryRow(`
+		INSERT INTO product_import_jobs (status, format, dry_run, file_name, total_rows, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		job.Status, job.Format, job.DryRun, job.FileName, job.TotalRows, job.CreatedBy,
+	).Scan(&job.ID, &job.CreatedAt)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Большой файл обрабатывается в фоне; контекст запроса копируется для журнала действий
+	if len(rows) > importSyncRows {
+		accepted := *job
+		go h.runImport(c.Copy(), job, rows, lang)
+
+		c.Header("Location", fmt.Sprintf("/api/v1/admin/products/import/%d", job.ID))
+		c.JSON(http.StatusAccepted, accepted)
+		return
+	}
+
+	h.runImport(c, job, rows, lang)
+	c.JSON(http.StatusOK, job)
+}
+
+// GetImportJob возвращает состояние задачи импорта
+// @Summary Задача импорта продуктов
+// @Description Возвращает прогресс и результат задачи импорта (требует разрешение products:import)
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID задачи"
+// @Success 200 {object} models.ProductImportJob
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/products/import/{id} [get]
+func (h *ProductHandler) GetImportJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidImportJobID)
+		return
+	}
+
+	var job models.ProductImportJob
+	var errorsJSON []byte
+	var jobError sql.NullString
+	err = h.db.QueryRow(`
+		SELECT id, status, format, dry_run, file_name, total_rows, processed_rows,
+			created_count, updated_count, unchanged_count, failed_count, errors, error,
+			created_by, created_at, started_at, finished_at
+		FROM product_import_jobs WHERE id = $1`, id).Scan(
+		&job.ID, &job.Status, &job.Format, &job.DryRun, &job.FileName, &job.TotalRows, &job.ProcessedRows,
+		&job.Created, &job.Updated, &job.Unchanged, &job.Failed, &errorsJSON, &jobError,
+		&job.CreatedBy, &job.CreatedAt, &job.StartedAt, &job.FinishedAt,
+	)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeImportJobNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	job.Error = jobError.String
+	if err := json.Unmarshal(errorsJSON, &job.Errors); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ExportProducts выгружает каталог продуктов в CSV или JSON Lines
+// @Summary Выгрузка продуктов
+// @Description Выгружает все продукты, включая неактивные (но не удаленные), в формате файла импорта: выгрузку можно отредактировать и загрузить обратно.
+// @Description Строки передаются по мере чтения из базы. Требует разрешение products:export.
+// @Tags products
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param format query string false "Формат файла" Enums(csv, jsonl) default(csv)
+// @Param category_id query int false "Только продукты категории"
+// @Param is_active query bool false "Только активные (true) или неактивные (false) продукты"
+// @Success 200 {file} file
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/products/export [get]
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", models.FileFormatCSV)
+	if format != models.FileFormatCSV && format != models.FileFormatJSONL {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidFileFormat)
+		return
+	}
+
+	whereClause := "WHERE deleted_at IS NULL"
+	args := []interface{}{}
+	argIndex := 1
+
+	if value := c.Query("category_id"); value != "" {
+		categoryID, err := strconv.Atoi(value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, "category_id")
+			return
+		}
+		whereClause += fmt.Sprintf(" AND category_id = $%d", argIndex)
+		args = append(args, categoryID)
+		argIndex++
+	}
+	if value := c.Query("is_active"); value != "" {
+		isActive, err := strconv.ParseBool(value)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, "is_active")
+			return
+		}
+		whereClause += fmt.Sprintf(" AND is_active = $%d", argIndex)
+		args = append(args, isActive)
+		argIndex++
+	}
+
+	slugs, err := categorySlugs(h.db)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	rows, err := h.db.Query("SELECT "+productColumns+" FROM products "+whereClause+" ORDER BY id", args...)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("products_%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	if format == models.FileFormatCSV {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	csvWriter := csv.NewWriter(c.Writer)
+	encoder := json.NewEncoder(c.Writer)
+	encoder.SetEscapeHTML(false)
+	if format == models.FileFormatCSV {
+		csvWriter.Write(productFileColumns)
+	}
+
+	// Заголовки уже отправлены: при ошибке чтения выгрузка обрывается
+	for count := 1; rows.Next(); count++ {
+		product, err := scanProduct(rows)
+		if err != nil {
+			c.Error(err)
+			break
+		}
+
+		row := productFileRow(product, slugs)
+		if format == models.FileFormatCSV {
+			csvWriter.Write(productCSVRecord(row))
+		} else if err := encoder.Encode(row); err != nil {
+			c.Error(err)
+			break
+		}
+
+		if count%exportFlushEvery == 0 {
+			csvWriter.Flush()
+			c.Writer.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		c.Error(err)
+	}
+	csvWriter.Flush()
+}
+
+// runImport обрабатывает строки файла и сохраняет результат в задаче.
+// Каждая строка применяется в своей транзакции, поэтому ошибка в одной не отменяет остальные.
+func (h *ProductHandler) runImport(c *gin.Context, job *models.ProductImportJob, rows []importRow, lang string) {
+	job.Status = models.ImportStatusRunning
+	now := time.Now()
+	job.StartedAt = &now
+	if _, err := h.db.Exec("UPDATE product_import_jobs SET status = $1, started_at = $2 WHERE id = $3", job.Status, now, job.ID); err != nil {
+		log.Printf("Ошибка сохранения задачи импорта %d: %v", job.ID, err)
+	}
+
+	categories, err := categorySlugs(h.db)
+	if err != nil {
+		h.failImport(job, err)
+		return
+	}
+	categoryIDs := make(map[string]int, len(categories))
+	for id, slug := range categories {
+		categoryIDs[slug] = id
+	}
+
+	rates, err := loadExchangeRates(h.db)
+	if err != nil {
+		h.failImport(job, err)
+		return
+	}
+
+	seen := make(map[string]int, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		row.data.SKU = strings.TrimSpace(row.data.SKU)
+
+		fieldErrors := row.errors
+		if len(fieldErrors) == 0 {
+			if err := binding.Validator.ValidateStruct(&row.data); err != nil {
+				fieldErrors = apierror.FieldErrors(lang, err)
+			}
+		}
+		if len(fieldErrors) == 0 {
+			if line, ok := seen[row.data.SKU]; ok {
+				fieldErrors = append(fieldErrors, apierror.NewFieldError(lang, "sku", "duplicate", strconv.Itoa(line)))
+			}
+			seen[row.data.SKU] = row.line
+		}
+
+		if len(fieldErrors) == 0 && row.data.Currency != nil {
+			currency := strings.ToUpper(*row.data.Currency)
+			if _, ok := rates[currency]; !ok {
+				fieldErrors = append(fieldErrors, apierror.NewFieldError(lang, "currency", "not_found", ""))
+			}
+			row.data.Currency = &currency
+		}
+
+		var categoryID *int
+		if len(fieldErrors) == 0 {
+			categoryID, fieldErrors = resolveImportCategory(&row.data, categories, categoryIDs, lang)
+		}
+
+		outcome := importRejected
+		if len(fieldErrors) == 0 {
+			outcome, fieldErrors, err = h.importProduct(c, &row.data, categoryID, job.DryRun, lang)
+			if err != nil {
+				h.failImport(job, fmt.Errorf("строка %d: %w", row.line, err))
+				return
+			}
+		}
+
+		switch outcome {
+		case importCreated:
+			job.Created++
+		case importUpdated:
+			job.Updated++
+		case importUnchanged:
+			job.Unchanged++
+		default:
+			job.Failed++
+			if len(job.Errors) < importMaxErrors {
+				job.Errors = append(job.Errors, models.ProductImportRowError{
+					Row:    row.line,
+					SKU:    row.data.SKU,
+					Errors: importFieldErrors(fieldErrors),
+				})
+			}
+		}
+		job.ProcessedRows = i + 1
+
+		if job.ProcessedRows%importProgressEvery == 0 {
+			h.saveImport(job)
+		}
+	}
+
+	job.Status = models.ImportStatusCompleted
+	h.finishImport(job)
+}
+
+// importProduct создает или изменяет продукт по строке файла. При dryRun только определяет результат.
+// Ошибки данных возвращаются как ошибки полей, err - только ошибки базы данных.
+func (h *ProductHandler) importProduct(c *gin.Context, row *models.ProductImportRow, categoryID *int, dryRun bool, lang string) (importOutcome, []apierror.FieldError, error) {
+	var db dbExecutor = h.db
+	lock := ""
+	if !dryRun {
+		tx, err := h.db.Begin()
+		if err != nil {
+			return importRejected, nil, err
+		}
+		defer tx.Rollback()
+		db, lock = tx, " FOR UPDATE"
+	}
+
+	existing, err := scanProduct(db.QueryRow("SELECT "+productColumns+" FROM products WHERE sku = $1"+lock, row.SKU))
+	if err != nil && err != sql.ErrNoRows {
+		return importRejected, nil, err
+	}
+
+	// SKU удаленного продукта занят: продукт нужно сначала восстановить из корзины
+	if existing != nil && existing.DeletedAt != nil {
+		return importRejected, []apierror.FieldError{apierror.NewFieldError(lang, "sku", "deleted", "")}, nil
+	}
+
+	if existing == nil {
+		var fieldErrors []apierror.FieldError
+		if row.Name == nil {
+			fieldErrors = append(fieldErrors, apierror.NewFieldError(lang, "name", "required", ""))
+		}
+		if row.Price == nil {
+			fieldErrors = append(fieldErrors, apierror.NewFieldError(lang, "price", "required", ""))
+		}
+		if len(fieldErrors) > 0 {
+			return importRejected, fieldErrors, nil
+		}
+		if dryRun {
+			return importCreated, nil, nil
+		}
+
+		product := models.Product{SKU: row.SKU, StockType: "piece", IsActive: true, Currency: models.BaseCurrency}
+		applyImportRow(&product, row, categoryID)
+
+		created, err := scanProduct(db.QueryRow(`
+			INSERT INTO products (name, description, price, category_id, stock, stock_type, image_url, sku, color, size, is_active, is_featured, sort_order, currency)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			RETURNING `+productColumns,
+			product.Name, product.Description, product.Price, product.CategoryID, product.Stock, product.StockType, product.ImageURL,
+			product.SKU, product.Color, product.Size, product.IsActive, product.IsFeatured, product.SortOrder, product.Currency,
+		))
+		if err != nil {
+			return importRejected, nil, err
+		}
+		if err := recordPriceChange(db, created, nil); err != nil {
+			return importRejected, nil, err
+		}
+		if err := refreshProductPrice(context.Background(), db.(*sql.Tx), created); err != nil {
+			return importRejected, nil, err
+		}
+		if err := recordAudit(db, c, models.AuditProductCreate, models.AuditEntityProduct, created.ID, nil, created); err != nil {
+			return importRejected, nil, err
+		}
+		return importCreated, nil, db.(*sql.Tx).Commit()
+	}
+
+	product := *existing
+	applyImportRow(&product, row, categoryID)
+	changes, err := auditDiff(existing, &product)
+	if err != nil {
+		return importRejected, nil, err
+	}
+	if len(changes) == 0 {
+		return importUnchanged, nil, nil
+	}
+	if dryRun {
+		return importUpdated, nil, nil
+	}
+
+	updated, err := scanProduct(db.QueryRow(`
+		UPDATE products SET name = $1, description = $2, price = $3, category_id = $4, stock = $5, stock_type = $6,
+			image_url = $7, color = $8, size = $9, is_active = $10, is_featured = $11, sort_order = $12, currency = $13
+		WHERE id = $14
+		RETURNING `+productColumns,
+		product.Name, product.Description, product.Price, product.CategoryID, product.Stock, product.StockType,
+		product.ImageURL, product.Color, product.Size, product.IsActive, product.IsFeatured, product.SortOrder, product.Currency, product.ID,
+	))
+	if err != nil {
+		return importRejected, nil, err
+	}
+	if err := refreshProductPrice(context.Background(), db.(*sql.Tx), updated); err != nil {
+		return importRejected, nil, err
+	}
+	if err := recordProductRevision(db, c, models.RevisionActionImport, existing, updated); err != nil {
+		return importRejected, nil, err
+	}
+	if err := recordAudit(db, c, models.AuditProductUpdate, models.AuditEntityProduct, updated.ID, existing, updated); err != nil {
+		return importRejected, nil, err
+	}
+	return importUpdated, nil, db.(*sql.Tx).Commit()
+}
+
+// saveImport сохраняет прогресс задачи импорта
+func (h *ProductHandler) saveImport(job *models.ProductImportJob) {
+	errorsJSON, err := json.Marshal(job.Errors)
+	if err == nil {
+		_, err = h.db.Exec(`
+			UPDATE product_import_jobs SET status = $1, processed_rows = $2, created_count = $3, updated_count = $4,
+				unchanged_count = $5, failed_count = $6, errors = $7, error = NULLIF($8, ''), finished_at = $9
+			WHERE id = $10`,
+			job.Status, job.ProcessedRows, job.Created, job.Updated, job.Unchanged, job.Failed, errorsJSON, job.Error, job.FinishedAt, job.ID)
+	}
+	if err != nil {
+		log.Printf("Ошибка сохранения задачи импорта %d: %v", job.ID, err)
+	}
+}
+
+// finishImport сохраняет итог задачи и сбрасывает кэш, если каталог изменился
+func (h *ProductHandler) finishImport(job *models.ProductImportJob) {
+	now := time.Now()
+	job.FinishedAt = &now
+	h.saveImport(job)
+
+	if !job.DryRun && job.Created+job.Updated > 0 && h.cache != nil {
+		h.cache.InvalidateAllProductCache(context.Background())
+	}
+}
+
+// failImport завершает задачу с ошибкой; уже примененные строки остаются в каталоге
+func (h *ProductHandler) failImport(job *models.ProductImportJob, err error) {
+	log.Printf("Ошибка импорта продуктов (задача %d): %v", job.ID, err)
+	job.Status = models.ImportStatusFailed
+	job.Error = fmt.Sprintf("Внутренняя ошибка после %d обработанных строк", job.ProcessedRows)
+	h.finishImport(job)
+}
+
+// applyImportRow переносит в продукт поля, заданные в строке импорта
+func applyImportRow(product *models.Product, row *models.ProductImportRow, categoryID *int) {
+	if row.Name != nil {
+		product.Name = *row.Name
+	}
+	if row.Description != nil {
+		product.Description = *row.Description
+	}
+	if row.Price != nil {
+		product.Price = *row.Price
+	}
+	if row.Currency != nil {
+		product.Currency = *row.Currency
+	}
+	if categoryID != nil {
+		product.CategoryID = categoryID
+	}
+	if row.Stock != nil {
+		product.Stock = *row.Stock
+	}
+	if row.StockType != nil {
+		product.StockType = *row.StockType
+	}
+	if row.ImageURL != nil {
+		product.ImageURL = *row.ImageURL
+	}
+	if row.Color != nil {
+		product.Color = *row.Color
+	}
+	if row.Size != nil {
+		product.Size = *row.Size
+	}
+	if row.IsActive != nil {
+		product.IsActive = *row.IsActive
+	}
+	if row.IsFeatured != nil {
+		product.IsFeatured = *row.IsFeatured
+	}
+	if row.SortOrder != nil {
+		product.SortOrder = *row.SortOrder
+	}
+}
+
+// resolveImportCategory находит категорию строки по category_id или category_slug;
+// если заданы оба, они должны указывать на одну категорию
+func resolveImportCategory(row *models.ProductImportRow, slugs map[int]string, ids map[string]int, lang string) (*int, []apierror.FieldError) {
+	if row.CategoryID != nil {
+		if _, ok := slugs[*row.CategoryID]; !ok {
+			return nil, []apierror.FieldError{apierror.NewFieldError(lang, "category_id", "not_found", "")}
+		}
+	}
+	if row.CategorySlug == nil {
+		return row.CategoryID, nil
+	}
+
+	id, ok := ids[*row.CategorySlug]
+	if !ok {
+		return nil, []apierror.FieldError{apierror.NewFieldError(lang, "category_slug", "not_found", "")}
+	}
+	if row.CategoryID != nil && *row.CategoryID != id {
+		return nil, []apierror.FieldError{apierror.NewFieldError(lang, "category_slug", "invalid", "")}
+	}
+	return &id, nil
+}
+
+// categorySlugs возвращает адреса (slug) всех категорий по их ID
+func categorySlugs(db dbExecutor) (map[int]string, error) {
+	rows, err := db.Query("SELECT id, slug FROM categories")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slugs := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var slug string
+		if err := rows.Scan(&id, &slug); err != nil {
+			return nil, err
+		}
+		slugs[id] = slug
+	}
+	return slugs, rows.Err()
+}
+
+// importFormat определяет формат файла: по параметру format, затем по расширению и Content-Type
+func importFormat(param, fileName, contentType string) (string, bool) {
+	switch param {
+	case models.FileFormatCSV, models.FileFormatJSONL:
+		return param, true
+	case "":
+	default:
+		return "", false
+	}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return models.FileFormatCSV, true
+	case ".jsonl", ".ndjson":
+		return models.FileFormatJSONL, true
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return models.FileFormatCSV, true
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return models.FileFormatJSONL, true
+	}
+	return "", false
+}
+
+// parseCSVImport читает строки CSV файла. Первая строка - названия колонок из productFileColumns,
+// обязательна только sku; пустая ячейка означает, что поле не задано.
+func parseCSVImport(r io.Reader, lang string) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, &importFileError{http.StatusBadRequest, apierror.CodeImportInvalidHeader, nil}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(productFileColumns))
+	for _, column := range productFileColumns {
+		known[column] = true
+	}
+	columns := make([]string, len(header))
+	present := make(map[string]bool, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // BOM, который добавляют табличные редакторы
+		}
+		column := strings.ToLower(strings.TrimSpace(name))
+		if !known[column] {
+			return nil, &importFileError{http.StatusBadRequest, apierror.CodeImportUnknownColumn, []interface{}{name}}
+		}
+		if present[column] {
+			return nil, &importFileError{http.StatusBadRequest, apierror.CodeImportDuplicateColumn, []interface{}{column}}
+		}
+		present[column] = true
+		columns[i] = column
+	}
+	if !present["sku"] {
+		return nil, &importFileError{http.StatusBadRequest, apierror.CodeImportMissingColumn, []interface{}{"sku"}}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(rows) >= importMaxRows {
+			return nil, &importFileError{http.StatusBadRequest, apierror.CodeImportTooManyRows, []interface{}{importMaxRows}}
+		}
+		if errors.As(err, &parseErr) {
+			rows = append(rows, importRow{
+				line:   parseErr.StartLine,
+				errors: []apierror.FieldError{apierror.NewFieldError(lang, "", "syntax", "CSV")},
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importRow{line: line}
+		if len(record) != len(columns) {
+			row.errors = append(row.errors, apierror.NewFieldError(lang, "", "syntax", "CSV"))
+			rows = append(rows, row)
+			continue
+		}
+		for i, column := range columns {
+			value := csvUnescape(strings.TrimSpace(record[i]))
+			if fieldErr := setImportField(&row.data, column, value, lang); fieldErr != nil {
+				row.errors = append(row.errors, *fieldErr)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseJSONLImport читает строки JSON Lines: по объекту ProductImportRow на строку, пустые строки пропускаются
+func parseJSONLImport(r io.Reader, lang string) ([]importRow, error) {
+	reader := bufio.NewReader(r)
+
+	var rows []importRow
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if len(bytes.TrimSpace(data)) > 0 {
+			if len(rows) >= importMaxRows {
+				return nil, &importFileError{http.StatusBadRequest, apierror.CodeImportTooManyRows, []interface{}{importMaxRows}}
+			}
+
+			row := importRow{line: line}
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.DisallowUnknownFields()
+			if decodeErr := decoder.Decode(&row.data); decodeErr != nil {
+				row.errors = jsonlFieldErrors(decodeErr, lang)
+			}
+			rows = append(rows, row)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return rows, nil
+}
+
+// jsonlFieldErrors переводит ошибку разбора строки JSON Lines в ошибки полей
+func jsonlFieldErrors(err error, lang string) []apierror.FieldError {
+	if fieldErrors := apierror.FieldErrors(lang, err); fieldErrors != nil {
+		return fieldErrors
+	}
+	// encoding/json не экспортирует тип ошибки неизвестного поля
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return []apierror.FieldError{apierror.NewFieldError(lang, strings.Trim(field, `"`), "unknown", "")}
+	}
+	return []apierror.FieldError{apierror.NewFieldError(lang, "", "syntax", "JSON")}
+}
+
+// setImportField записывает значение ячейки CSV в поле строки импорта
+func setImportField(row *models.ProductImportRow, column, value, lang string) *apierror.FieldError {
+	if value == "" {
+		return nil
+	}
+
+	switch column {
+	case "sku":
+		row.SKU = value
+	case "name":
+		row.Name = &value
+	case "description":
+		row.Description = &value
+	case "currency":
+		row.Currency = &value
+	case "category_slug":
+		row.CategorySlug = &value
+	case "stock_type":
+		row.StockType = &value
+	case "image_url":
+		row.ImageURL = &value
+	case "color":
+		row.Color = &value
+	case "size":
+		row.Size = &value
+	case "price":
+		// Табличные редакторы с русской локалью пишут дробную часть через запятую
+		price, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			fieldErr := apierror.NewFieldError(lang, column, "type", "number")
+			return &fieldErr
+		}
+		row.Price = &price
+	case "category_id", "stock", "sort_order":
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			fieldErr := apierror.NewFieldError(lang, column, "type", "integer")
+			return &fieldErr
+		}
+		switch column {
+		case "category_id":
+			row.CategoryID = &number
+		case "stock":
+			row.Stock = &number
+		default:
+			row.SortOrder = &number
+		}
+	case "is_active", "is_featured":
+		flag, ok := parseImportBool(value)
+		if !ok {
+			fieldErr := apierror.NewFieldError(lang, column, "type", "boolean")
+			return &fieldErr
+		}
+		if column == "is_active" {
+			row.IsActive = &flag
+		} else {
+			row.IsFeatured = &flag
+		}
+	}
+	return nil
+}
+
+// parseImportBool разбирает логическое значение ячейки CSV
+func parseImportBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "true", "1", "yes", "да":
+		return true, true
+	case "false", "0", "no", "нет":
+		return false, true
+	}
+	return false, false
+}
+
+// productFileRow строка выгрузки продукта со всеми полями
+func productFileRow(product *models.Product, slugs map[int]string) models.ProductImportRow {
+	row := models.ProductImportRow{
+		SKU:         product.SKU,
+		Name:        &product.Name,
+		Description: &product.Description,
+		Price:       &product.Price,
+		Currency:    &product.Currency,
+		CategoryID:  product.CategoryID,
+		Stock:       &product.Stock,
+		StockType:   &product.StockType,
+		ImageURL:    &product.ImageURL,
+		Color:       &product.Color,
+		Size:        &product.Size,
+		IsActive:    &product.IsActive,
+		IsFeatured:  &product.IsFeatured,
+		SortOrder:   &product.SortOrder,
+	}
+	if product.CategoryID != nil {
+		if slug, ok := slugs[*product.CategoryID]; ok {
+			row.CategorySlug = &slug
+		}
+	}
+	return row
+}
+
+// productCSVRecord строка CSV в порядке productFileColumns
+func productCSVRecord(row models.ProductImportRow) []string {
+	categorySlug := ""
+	if row.CategorySlug != nil {
+		categorySlug = *row.CategorySlug
+	}
+	return []string{
+		csvSafe(row.SKU),
+		csvSafe(*row.Name),
+		csvSafe(*row.Description),
+		strconv.FormatFloat(*row.Price, 'f', -1, 64),
+		csvSafe(*row.Currency),
+		formatOptionalInt(row.CategoryID),
+		csvSafe(categorySlug),
+		strconv.Itoa(*row.Stock),
+		csvSafe(*row.StockType),
+		csvSafe(*row.ImageURL),
+		csvSafe(*row.Color),
+		csvSafe(*row.Size),
+		strconv.FormatBool(*row.IsActive),
+		strconv.FormatBool(*row.IsFeatured),
+		strconv.Itoa(*row.SortOrder),
+	}
+}
+
+// csvUnescape убирает апостроф, которым csvSafe защищает значения от выполнения формул
+func csvUnescape(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// importFieldErrors переводит ошибки полей в формат отчета об импорте
+func importFieldErrors(fieldErrors []apierror.FieldError) []models.ImportFieldError {
+	result := make([]models.ImportFieldError, len(fieldErrors))
+	for i, fe := range fieldErrors {
+		result[i] = models.ImportFieldError{Field: fe.Field, Rule: fe.Rule, Message: fe.Message}
+	}
+	return result
+}
+
+// truncateRunes обрезает строку до max символов
+func truncateRunes(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
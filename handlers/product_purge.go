@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"api-go/models"
+	"api-go/storage"
+)
+
+// purgeableProducts условие для удаленных продуктов, на которые ничего не ссылается:
+// продукты из заказов и отзывов остаются в корзине навсегда
+const purgeableProducts = `p.deleted_at < $1
+	AND NOT EXISTS (SELECT 1 FROM order_items oi WHERE oi.product_id = p.id)
+	AND NOT EXISTS (SELECT 1 FROM reviews r WHERE r.product_id = p.id)
+	AND NOT EXISTS (SELECT 1 FROM cart_items ci WHERE ci.product_id = p.id)
+	AND NOT EXISTS (SELECT 1 FROM wishlist w WHERE w.product_id = p.id)`
+
+// PurgeDeletedProducts окончательно удаляет продукты, удаленные раньше deletedBefore,
+// на которые не ссылаются заказы, отзывы, корзины и избранное, вместе с файлами изображений.
+// Каждый продукт удаляется в своей транзакции и записывается в журнал действий.
+// При dryRun только возвращает количество таких продуктов.
+func PurgeDeletedProducts(ctx context.Context, db *sql.DB, store storage.Storage, deletedBefore time.Time, dryRun bool) (int, error) {
+	if dryRun {
+		var count int
+		err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products p WHERE "+purgeableProducts, deletedBefore).Scan(&count)
+		return count, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT p.id FROM products p WHERE "+purgeableProducts+" ORDER BY p.id", deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		keys, ok, err := purgeProduct(ctx, db, id, deletedBefore)
+		if err != nil {
+			return purged, err
+		}
+		if !ok {
+			continue
+		}
+		purged++
+
+		// Файлы удаляются после фиксации: если удаление не удалось, в хранилище останется мусор, а не битые ссылки
+		for _, key := range keys {
+			if err := store.Delete(context.Background(), key); err != nil {
+				log.Printf("Ошибка удаления файла %s из хранилища: %v", key, err)
+			}
+		}
+	}
+	return purged, nil
+}
+
+// purgeProduct удаляет продукт, если он все еще подходит под purgeableProducts.
+// Возвращает ключи файлов его изображений; варианты, характеристики и записи галереи удаляются каскадно.
+func purgeProduct(ctx context.Context, db *sql.DB, id int, deletedBefore time.Time) (keys []string, ok bool, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	// Проверка повторяется под блокировкой: продукт могли восстановить после выборки
+	product, err := scanProduct(tx.QueryRowContext(ctx,
+		"SELECT "+productColumns+" FROM products p WHERE p.id = $2 AND "+purgeableProducts+" FOR UPDATE", deletedBefore, id))
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	images, err := getProductImageRecords(tx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, image := range images {
+		keys = append(keys, image.keys()...)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM products WHERE id = $1", id); err != nil {
+		return nil, false, err
+	}
+
+	// Команда запускается без пользователя: в журнале остается только состояние продукта
+	changes, err := auditDiff(product, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, false, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_log (action, entity_type, entity_id, changes)
+		VALUES ($1, $2, $3, $4)`,
+		models.AuditProductPurge, models.AuditEntityProduct, id, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return keys, true, nil
+}
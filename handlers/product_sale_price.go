@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"api-go/apierror"
+	"api-go/models"
+	"api-go/pricing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// salePriceColumns список колонок для выборки запланированной цены функцией scanSalePrice
+const salePriceColumns = `id, product_id, sale_price, starts_at, ends_at, created_by, created_at`
+
+// GetProductSalePrices возвращает запланированные цены продукта
+// @Summary Запланированные цены продукта
+// @Description Возвращает прошедшие, действующие и будущие цены продукта, новые первыми (требует разрешение products:update)
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Success 200 {array} models.ProductSalePrice
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/products/{id}/prices [get]
+func (h *ProductHandler) GetProductSalePrices(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	var exists bool
+	if err := h.db.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)", id).Scan(&exists); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !exists {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+
+	rows, err := h.db.Query("SELECT "+salePriceColumns+" FROM product_sale_prices WHERE product_id = $1 ORDER BY starts_at DESC, id DESC", id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	prices := []models.ProductSalePrice{}
+	for rows.Next() {
+		price, err := scanSalePrice(rows)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		prices = append(prices, *price)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prices)
+}
+
+// CreateProductSalePrice планирует цену продукта на период
+// @Summary Планирование цены продукта
+// @Description Задает цену продукта на период [starts_at, ends_at); без ends_at цена действует до удаления.
+// @Description Цена применяется автоматически в начале периода и снимается в конце; при пересечении с другими ценами
+// @Description и акциями на категорию действует наименьшая. Требует разрешение products:update.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param request body models.ProductSalePriceCreateRequest true "Цена и период" example({"sale_price":899.99,"starts_at":"2026-11-27T00:00:00Z","ends_at":"2026-12-01T00:00:00Z"})
+// @Success 201 {object} models.ProductSalePrice
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/products/{id}/prices [post]
+func (h *ProductHandler) CreateProductSalePrice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	var req models.ProductSalePriceCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	startsAt := req.StartsAt.UTC()
+	if req.EndsAt != nil {
+		endsAt := req.EndsAt.UTC()
+		if !endsAt.After(startsAt) {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidSalePeriod)
+			return
+		}
+		req.EndsAt = &endsAt
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	var basePrice float64
+	err = tx.QueryRow("SELECT price FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id).Scan(&basePrice)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if req.SalePrice >= basePrice {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeSalePriceTooHigh)
+		return
+	}
+
+	var createdBy sql.NullInt64
+	if userID, ok := c.Get("user_id"); ok {
+		createdBy = sql.NullInt64{Int64: int64(userID.(int)), Valid: true}
+	}
+
+	price, err := scanSalePrice(tx.QueryRow(`
+		INSERT INTO product_sale_prices (product_id, sale_price, starts_at, ends_at, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+salePriceColumns,
+		id, req.SalePrice, startsAt, req.EndsAt, createdBy,
+	))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditSalePriceCreate, models.AuditEntitySalePrice, price.ID, nil, price); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if !h.commitPriceChange(c, tx, id) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, price)
+}
+
+// DeleteProductSalePrice удаляет запланированную цену продукта
+// @Summary Удаление запланированной цены
+// @Description Удаляет запланированную цену; если она действует, продукт сразу возвращается к базовой цене
+// @Description или к следующему действующему предложению. Требует разрешение products:update.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param price_id path int true "ID запланированной цены"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/products/{id}/prices/{price_id} [delete]
+func (h *ProductHandler) DeleteProductSalePrice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+	priceID, err := strconv.Atoi(c.Param("price_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidSalePriceID)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	price, err := scanSalePrice(tx.QueryRow("DELETE FROM product_sale_prices WHERE id = $1 AND product_id = $2 RETURNING "+salePriceColumns, priceID, id))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeSalePriceNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditSalePriceDelete, models.AuditEntitySalePrice, price.ID, price, nil); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if !h.commitPriceChange(c, tx, id) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Запланированная цена удалена"})
+}
+
+// commitPriceChange пересчитывает действующую цену продукта, фиксирует транзакцию и сбрасывает кэш продукта.
+// При ошибке отправляет ответ и возвращает false.
+func (h *ProductHandler) commitPriceChange(c *gin.Context, tx *sql.Tx, productID int) bool {
+	changed, err := pricing.Refresh(c.Request.Context(), tx, productID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+
+	if len(changed) > 0 && h.cache != nil {
+		h.cache.InvalidateProductCache(c.Request.Context(), productID)
+	}
+	return true
+}
+
+// refreshProductPrice пересчитывает в транзакции действующую цену продукта после изменения его цены
+// или категории (скидка акции считается от базовой цены) и переносит ее в product
+func refreshProductPrice(ctx context.Context, tx *sql.Tx, product *models.Product) error {
+	if _, err := pricing.Refresh(ctx, tx, product.ID); err != nil {
+		return err
+	}
+	return tx.QueryRowContext(ctx, "SELECT sale_price, sale_ends_at FROM products WHERE id = $1", product.ID).
+		Scan(&product.SalePrice, &product.SaleEndsAt)
+}
+
+// scanSalePrice читает запланированную цену из строки результата с колонками salePriceColumns
+func scanSalePrice(row rowScanner) (*models.ProductSalePrice, error) {
+	var price models.ProductSalePrice
+	err := row.Scan(&price.ID, &price.ProductID, &price.SalePrice, &price.StartsAt, &price.EndsAt, &price.CreatedBy, &price.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
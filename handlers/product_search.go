@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Поиск с опечатками (триграммы по названию) включается для коротких запросов:
+// не больше typoSearchMaxWords слов и не короче typoSearchMinLength символов
+const (
+	typoSearchMaxWords  = 2
+	typoSearchMinLength = 3
+)
+
+// searchTSQuery поисковый запрос в русской и английской конфигурациях; %[1]s - номер параметра с текстом.
+// websearch_to_tsquery понимает кавычки для фраз, OR и минус для исключения слов.
+const searchTSQuery = `(websearch_to_tsquery('russian', %[1]s) || websearch_to_tsquery('english', %[1]s))`
+
+// Параметры подсветки найденных слов в названии и фрагментах описания
+const (
+	searchHeadlineName        = `HighlightAll=true, StartSel=<mark>, StopSel=</mark>`
+	searchHeadlineDescription = `StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=" … "`
+)
+
+// searchFilter условие полнотекстового поиска для списка продуктов
+func searchFilter(search string) productFilter {
+	cond := "(p.search_vector @@ " + searchTSQuery
+	if isTypoTolerantSearch(search) {
+		// word_similarity выше pg_trgm.word_similarity_threshold, использует триграммный индекс по названию
+		cond += " OR %[1]s <%% p.name"
+	}
+	cond += ")"
+	return productFilter{key: "search", cond: cond, args: []interface{}{search}}
+}
+
+// isTypoTolerantSearch сообщает, искать ли с учетом опечаток
+func isTypoTolerantSearch(search string) bool {
+	return len(strings.Fields(search)) <= typoSearchMaxWords && utf8.RuneCountInString(strings.TrimSpace(search)) >= typoSearchMinLength
+}
+
+// searchRank выражение релевантности продукта; param - номер параметра с текстом запроса
+func searchRank(param int) string {
+	placeholder := fmt.Sprintf("$%d", param)
+	return fmt.Sprintf("ts_rank_cd(p.search_vector, %s) + word_similarity(%s, p.name)",
+		fmt.Sprintf(searchTSQuery, placeholder), placeholder)
+}
+
+// searchHeadlineColumns колонки с подсвеченными названием и фрагментом описания; param - номер параметра с текстом запроса.
+// Текст экранируется до подсветки: в ответе HTML-разметка только <mark>.
+func searchHeadlineColumns(param int) string {
+	query := fmt.Sprintf(searchTSQuery, fmt.Sprintf("$%d", param))
+	return fmt.Sprintf("ts_headline('russian', %[4]s, %[1]s, '%[2]s'), ts_headline('russian', %[5]s, %[1]s, '%[3]s')",
+		query, searchHeadlineName, searchHeadlineDescription,
+		htmlEscapeSQL("p.name"), htmlEscapeSQL("COALESCE(p.description, '')"))
+}
+
+// htmlEscapeSQL оборачивает SQL-выражение с текстом в экранирование спецсимволов HTML
+func htmlEscapeSQL(expr string) string {
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"'", "&#39;"}} {
+		expr = fmt.Sprintf("replace(%s, '%s', '%s')", expr, strings.ReplaceAll(r[0], "'", "''"), r[1])
+	}
+	return expr
+}
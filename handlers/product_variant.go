@@ -0,0 +1,539 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"api-go/apierror"
+	"api-go/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// variantColumns список колонок для выборки варианта вместе с ценой и валютой продукта.
+// Акция продукта распространяется на варианты в той же пропорции, что и на цену продукта.
+const variantColumns = `v.id, v.product_id, v.sku, COALESCE(v.price, p.price), v.price,
+	ROUND(COALESCE(v.price, p.price) * COALESCE(p.sale_price / NULLIF(p.price, 0), 1), 2), v.stock,
+	v.attributes, v.is_active, v.sort_order, v.created_at, v.updated_at, p.currency`
+
+// Ошибки выбора позиции для корзины и заказа
+var (
+	errProductUnavailable = errors.New("продукт не найден или неактивен")
+	errVariantRequired    = errors.New("у продукта есть варианты, вариант не указан")
+	errVariantUnavailable = errors.New("вариант не найден или неактивен")
+)
+
+// purchasable продукт или его вариант, который можно положить в корзину и заказать
+type purchasable struct {
+	price    float64
+	currency string
+	stock    int
+	variant  *models.ProductVariant
+}
+
+// GetProductVariants возвращает все варианты продукта, включая неактивные
+// @Summary Варианты продукта
+// @Description Возвращает все варианты продукта, включая неактивные (требует разрешение products:update)
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Success 200 {array} models.ProductVariant
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /products/{id}/variants [get]
+func (h *ProductHandler) GetProductVariants(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	var exists bool
+	if err := h.db.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)", productID).Scan(&exists); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !exists {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+
+	variants, err := getProductVariants(h.db, productID, false)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, variants)
+}
+
+// CreateProductVariant создает вариант продукта
+// @Summary Создание варианта продукта
+// @Description Создает вариант продукта со своим SKU, остатком и значениями атрибутов (требует разрешение products:update)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param variant body models.ProductVariantCreateRequest true "Данные варианта" example({"sku":"TSHIRT-BLACK-M","stock":12,"attributes":{"color":"Черный","size":"M"}})
+// @Success 201 {object} models.ProductVariant
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /products/{id}/variants [post]
+func (h *ProductHandler) CreateProductVariant(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return
+	}
+
+	var req models.ProductVariantCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	attributes, err := json.Marshal(req.Attributes)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)", productID).Scan(&exists); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !exists {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound)
+		return
+	}
+
+	var variantID int
+	err = tx.QueryRow(`
+		INSERT INTO product_variants (product_id, sku, price, stock, attributes, is_active, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT DO NOTHING
+		RETURNING id`,
+		productID, req.SKU, req.Price, req.Stock, attributes, isActive, req.SortOrder,
+	).Scan(&variantID)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeVariantConflict)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	variant, err := getVariant(tx, variantID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditVariantCreate, models.AuditEntityVariant, variantID, nil, variant); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	h.invalidateProduct(c, productID)
+
+	c.JSON(http.StatusCreated, variant)
+}
+
+// UpdateProductVariant изменяет вариант продукта
+// @Summary Изменение варианта продукта
+// @Description Изменяет SKU, цену, остаток или атрибуты варианта (требует разрешение products:update)
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param variant_id path int true "ID варианта"
+// @Param variant body models.ProductVariantUpdateRequest true "Данные для обновления" example({"price":17.99,"stock":10})
+// @Success 200 {object} models.ProductVariant
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /products/{id}/variants/{variant_id} [put]
+func (h *ProductHandler) UpdateProductVariant(c *gin.Context) {
+	productID, variantID, ok := variantParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.ProductVariantUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := getVariant(tx, variantID, "FOR UPDATE OF v")
+	if err == sql.ErrNoRows || (err == nil && before.ProductID != productID) {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeVariantNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Формируем SQL запрос для обновления
+	query := "UPDATE product_variants SET updated_at = $1"
+	args := []interface{}{time.Now()}
+	argIndex := 2
+
+	if req.SKU != nil {
+		query += fmt.Sprintf(", sku = $%d", argIndex)
+		args = append(args, *req.SKU)
+		argIndex++
+	}
+
+	if req.ResetPrice {
+		query += ", price = NULL"
+	} else if req.Price != nil {
+		query += fmt.Sprintf(", price = $%d", argIndex)
+		args = append(args, *req.Price)
+		argIndex++
+	}
+
+	if req.Stock != nil {
+		query += fmt.Sprintf(", stock = $%d", argIndex)
+		args = append(args, *req.Stock)
+		argIndex++
+	}
+
+	if req.Attributes != nil {
+		attributes, err := json.Marshal(req.Attributes)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		query += fmt.Sprintf(", attributes = $%d", argIndex)
+		args = append(args, attributes)
+		argIndex++
+	}
+
+	if req.IsActive != nil {
+		query += fmt.Sprintf(", is_active = $%d", argIndex)
+		args = append(args, *req.IsActive)
+		argIndex++
+	}
+
+	if req.SortOrder != nil {
+		query += fmt.Sprintf(", sort_order = $%d", argIndex)
+		args = append(args, *req.SortOrder)
+		argIndex++
+	}
+
+	query += " WHERE id = $" + strconv.Itoa(argIndex)
+	args = append(args, variantID)
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		if isUniqueViolation(err) {
+			apierror.Respond(c, http.StatusConflict, apierror.CodeVariantConflict)
+			return
+		}
+		apierror.Internal(c, err)
+		return
+	}
+
+	variant, err := getVariant(tx, variantID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditVariantUpdate, models.AuditEntityVariant, variantID, before, variant); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	h.invalidateProduct(c, productID)
+
+	c.JSON(http.StatusOK, variant)
+}
+
+// DeleteProductVariant удаляет вариант продукта
+// @Summary Удаление варианта продукта
+// @Description Удаляет вариант и убирает его из корзин; в оформленных заказах остаются SKU и атрибуты варианта (требует разрешение products:update)
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "ID продукта"
+// @Param variant_id path int true "ID варианта"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /products/{id}/variants/{variant_id} [delete]
+func (h *ProductHandler) DeleteProductVariant(c *gin.Context) {
+	productID, variantID, ok := variantParams(c)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	variant, err := getVariant(tx, variantID, "FOR UPDATE OF v")
+	if err == sql.ErrNoRows || (err == nil && variant.ProductID != productID) {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeVariantNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if _, err := tx.Exec("DELETE FROM product_variants WHERE id = $1", variantID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := recordAudit(tx, c, models.AuditVariantDelete, models.AuditEntityVariant, variantID, variant, nil); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	h.invalidateProduct(c, productID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Вариант успешно удален"})
+}
+
+// invalidateProduct сбрасывает кэш карточки продукта после изменения его вариантов
+func (h *ProductHandler) invalidateProduct(c *gin.Context, productID int) {
+	if h.cache != nil {
+		h.cache.InvalidateProductCache(c.Request.Context(), productID)
+	}
+}
+
+// variantParams разбирает ID продукта и варианта из пути.
+// При неверном значении отправляет ошибку и возвращает ok = false.
+func variantParams(c *gin.Context) (productID, variantID int, ok bool) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidProductID)
+		return 0, 0, false
+	}
+
+	variantID, err = strconv.Atoi(c.Param("variant_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidVariantID)
+		return 0, 0, false
+	}
+
+	return productID, variantID, true
+}
+
+// getVariant получает вариант по ID. lock - необязательная блокировка строки (FOR UPDATE OF v)
+func getVariant(db dbExecutor, variantID int, lock ...string) (*models.ProductVariant, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM product_variants v JOIN products p ON p.id = v.product_id
+		WHERE v.id = $1`, variantColumns)
+	for _, l := range lock {
+		query += " " + l
+	}
+	return scanVariant(db.QueryRow(query, variantID))
+}
+
+// getProductVariants получает варианты продукта в порядке отображения
+func getProductVariants(db *sql.DB, productID int, activeOnly bool) ([]models.ProductVariant, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM product_variants v JOIN products p ON p.id = v.product_id
+		WHERE v.product_id = $1`, variantColumns)
+	if activeOnly {
+		query += " AND v.is_active = true"
+	}
+	query += " ORDER BY v.sort_order, v.id"
+
+	rows, err := db.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []models.ProductVariant{}
+	for rows.Next() {
+		variant, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, *variant)
+	}
+	return variants, rows.Err()
+}
+
+// scanVariant читает вариант из строки результата с колонками variantColumns
+func scanVariant(row rowScanner) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	var priceOverride sql.NullFloat64
+	var attributes []byte
+	err := row.Scan(
+		&variant.ID, &variant.ProductID, &variant.SKU, &variant.Price, &priceOverride, &variant.EffectivePrice, &variant.Stock,
+		&attributes, &variant.IsActive, &variant.SortOrder, &variant.CreatedAt, &variant.UpdatedAt, &variant.Currency,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if priceOverride.Valid {
+		variant.PriceOverride = &priceOverride.Float64
+	}
+	if err := json.Unmarshal(attributes, &variant.Attributes); err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// variantOptions строит матрицу вариантов: атрибуты по алфавиту,
+// значения в порядке отображения вариантов
+func variantOptions(variants []models.ProductVariant) []models.VariantOption {
+	values := map[string][]string{}
+	seen := map[string]map[string]bool{}
+	for _, variant := range variants {
+		for name, value := range variant.Attributes {
+			if seen[name] == nil {
+				seen[name] = map[string]bool{}
+			}
+			if !seen[name][value] {
+				seen[name][value] = true
+				values[name] = append(values[name], value)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	options := make([]models.VariantOption, 0, len(names))
+	for _, name := range names {
+		options = append(options, models.VariantOption{Name: name, Values: values[name]})
+	}
+	return options
+}
+
+// resolvePurchasable определяет цену и остаток позиции корзины или заказа:
+// варианта, если он указан, иначе продукта. У продукта с активными вариантами вариант обязателен.
+func resolvePurchasable(db dbExecutor, productID int, variantID *int) (*purchasable, error) {
+	if variantID != nil {
+		variant, err := scanVariant(db.QueryRow(fmt.Sprintf(`
+			SELECT %s
+			FROM product_variants v JOIN products p ON p.id = v.product_id
+			WHERE v.id = $1 AND v.product_id = $2 AND v.is_active = true AND p.is_active = true AND p.deleted_at IS NULL`,
+			variantColumns), *variantID, productID))
+		if err == sql.ErrNoRows {
+			return nil, errVariantUnavailable
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &purchasable{price: variant.EffectivePrice, currency: variant.Currency, stock: variant.Stock, variant: variant}, nil
+	}
+
+	var item purchasable
+	var hasVariants bool
+	err := db.QueryRow(`
+		SELECT COALESCE(p.sale_price, p.price), p.currency, p.stock,
+		       EXISTS(SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.is_active = true)
+		FROM products p WHERE p.id = $1 AND p.is_active = true AND p.deleted_at IS NULL`, productID,
+	).Scan(&item.price, &item.currency, &item.stock, &hasVariants)
+	if err == sql.ErrNoRows {
+		return nil, errProductUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hasVariants {
+		return nil, errVariantRequired
+	}
+	return &item, nil
+}
+
+// respondPurchasableError отправляет ошибку resolvePurchasable для позиции корзины
+func respondPurchasableError(c *gin.Context, err error) {
+	switch err {
+	case errProductUnavailable:
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeProductUnavailable)
+	case errVariantRequired:
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeVariantRequired)
+	case errVariantUnavailable:
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeVariantUnavailable)
+	default:
+		apierror.Internal(c, err)
+	}
+}
+
+// isUniqueViolation сообщает, нарушено ли ограничение уникальности
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
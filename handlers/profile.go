@@ -0,0 +1,484 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api-go/apierror"
+	"api-go/config"
+	"api-go/jwtkeys"
+	"api-go/mailer"
+	"api-go/models"
+	"api-go/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileHandler обрабатывает запросы пользователя к собственной учетной записи
+type ProfileHandler struct {
+	db     *sql.DB
+	cfg    *config.Config
+	mailer mailer.Mailer
+	keys   *jwtkeys.KeySet
+}
+
+// NewProfileHandler создает новый экземпляр ProfileHandler
+func NewProfileHandler(db *sql.DB, cfg *config.Config, m mailer.Mailer, keys *jwtkeys.KeySet) *ProfileHandler {
+	return &ProfileHandler{
+		db:     db,
+		cfg:    cfg,
+		mailer: m,
+		keys:   keys,
+	}
+}
+
+// GetMe возвращает профиль текущего пользователя
+// @Summary Профиль текущего пользователя
+// @Description Возвращает данные аутентифицированного пользователя и разрешения его роли
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.UserResponse
+// @Failure 401 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Router /me [get]
+func (h *ProfileHandler) GetMe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	user, err := getUserByID(h.db, userID.(int))
+	if err != nil {
+		respondUserLookupError(c, err)
+		return
+	}
+
+	response := user.ToResponse()
+	if permissions, ok := c.Get("permissions"); ok {
+		response.Permissions, _ = permissions.([]string)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateMe обновляет профиль текущего пользователя
+// @Summary Обновление профиля
+// @Description Обновляет имя пользователя, имя, фамилию и телефон
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body models.ProfileUpdateRequest true "Данные профиля"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /me [put]
+func (h *ProfileHandler) UpdateMe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	var req models.ProfileUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	// Формируем SQL запрос для обновления
+	query := "UPDATE users SET updated_at = $1"
+	args := []interface{}{time.Now()}
+	argIndex := 2
+
+	if req.Username != nil {
+		query += fmt.Sprintf(", username = $%d", argIndex)
+		args = append(args, *req.Username)
+		argIndex++
+	}
+
+	if req.FirstName != nil {
+		query += fmt.Sprintf(", first_name = $%d", argIndex)
+		args = append(args, *req.FirstName)
+		argIndex++
+	}
+
+	if req.LastName != nil {
+		query += fmt.Sprintf(", last_name = $%d", argIndex)
+		args = append(args, *req.LastName)
+		argIndex++
+	}
+
+	if req.Phone != nil {
+		query += fmt.Sprintf(", phone = $%d", argIndex)
+		args = append(args, *req.Phone)
+		argIndex++
+	}
+
+	query += " WHERE id = $" + strconv.Itoa(argIndex)
+	args = append(args, userID)
+
+	if _, err := h.db.Exec(query, args...); err != nil {
+		if isUniqueViolation(err) {
+			apierror.Respond(c, http.StatusConflict, apierror.CodeUsernameTaken)
+			return
+		}
+		apierror.Internal(c, err)
+		return
+	}
+
+	user, err := getUserByID(h.db, userID.(int))
+	if err != nil {
+		respondUserLookupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// ChangePassword меняет пароль текущего пользователя
+// @Summary Смена пароля
+// @Description Меняет пароль после проверки текущего. Все остальные сессии становятся недействительными, в ответе возвращается новый токен. Неверный текущий пароль учитывается в блокировке входа
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param passwords body models.ChangePasswordRequest true "Текущий и новый пароль"
+// @Success 200 {object} models.ChangePasswordResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 423 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /me/change-password [post]
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	if respondIfLocked(c, h.db, h.cfg.Lockout, userID.(int)) {
+		return
+	}
+
+	var passwordHash string
+	if err := h.db.QueryRow("SELECT password FROM users WHERE id = $1", userID).Scan(&passwordHash); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if !utils.CheckPasswordHash(req.CurrentPassword, passwordHash) {
+		h.registerFailedAttempt(userID.(int))
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidCurrentPassword)
+		return
+	}
+
+	if req.CurrentPassword == req.NewPassword {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodePasswordUnchanged)
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Увеличение token_version отзывает все выданные токены
+	var username, role string
+	var tokenVersion int
+	err = h.db.QueryRow(`
+		UPDATE users
+		SET password = $1, must_change_password = false, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING username, role, token_version`,
+		hashedPassword, userID,
+	).Scan(&username, &role, &tokenVersion)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Выдаем новый токен для текущей сессии
+	token, err := utils.GenerateToken(h.keys, userID.(int), username, role, tokenVersion, h.cfg.JWT.ExpiryHours)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ChangePasswordResponse{
+		Message: "Пароль успешно изменен",
+		Token:   token,
+	})
+}
+
+// ChangeEmail запрашивает смену email
+// @Summary Смена email
+// @Description Отправляет письмо со ссылкой подтверждения на новый адрес. Email меняется только после подтверждения. Неверный пароль учитывается в блокировке входа
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param email body models.ChangeEmailRequest true "Новый email и текущий пароль"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 423 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /me/change-email [post]
+func (h *ProfileHandler) ChangeEmail(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	var req models.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	if respondIfLocked(c, h.db, h.cfg.Lockout, userID.(int)) {
+		return
+	}
+
+	var passwordHash string
+	if err := h.db.QueryRow("SELECT password FROM users WHERE id = $1", userID).Scan(&passwordHash); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if !utils.CheckPasswordHash(req.Password, passwordHash) {
+		h.registerFailedAttempt(userID.(int))
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidPassword)
+		return
+	}
+
+	// Проверяем, что email свободен
+	var taken bool
+	err := h.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", req.NewEmail).Scan(&taken)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if taken {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeEmailTaken)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE users SET pending_email = $1 WHERE id = $2", req.NewEmail, userID); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	token, err := createUserToken(tx, userID.(int), models.TokenPurposeEmailChange, req.NewEmail, emailChangeTokenTTL)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	msg := emailChangeMessage(h.cfg, req.NewEmail, token)
+	if err := h.mailer.Send(c.Request.Context(), msg); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Письмо с подтверждением отправлено на новый email"})
+}
+
+// registerFailedAttempt учитывает неверный пароль так же, как неудачную попытку входа
+func (h *ProfileHandler) registerFailedAttempt(userID int) {
+	if err := registerFailedLogin(h.db, h.cfg.Lockout, userID); err != nil {
+		log.Printf("Ошибка учета неудачной попытки: %v", err)
+	}
+}
+
+// ConfirmEmailChange подтверждает смену email по токену из письма
+// @Summary Подтверждение смены email
+// @Description Применяет новый email по одноразовому токену из письма
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param token body models.TokenRequest true "Токен из письма"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
+// @Router /auth/confirm-email-change [post]
+func (h *ProfileHandler) ConfirmEmailChange(c *gin.Context) {
+	var req models.TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	userID, newEmail, err := consumeUserToken(tx, models.TokenPurposeEmailChange, req.Token)
+	if err == errInvalidToken {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidLink)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Email мог быть занят, пока письмо шло
+	var taken bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND id <> $2)", newEmail, userID).Scan(&taken); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if taken {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeEmailTaken)
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET email = $1, pending_email = NULL, email_verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2`, newEmail, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email успешно изменен"})
+}
+
+// ResendVerification повторно отправляет письмо для подтверждения email
+// @Summary Повторное подтверждение email
+// @Description Отправляет новое письмо с токеном подтверждения email. Предыдущие токены становятся недействительными
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} map[string]string
+// @Failure 401 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /me/resend-verification [post]
+func (h *ProfileHandler) ResendVerification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	user, err := getUserByID(h.db, userID.(int))
+	if err != nil {
+		respondUserLookupError(c, err)
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeEmailAlreadyVerified)
+		return
+	}
+
+	token, err := createUserToken(h.db, user.ID, models.TokenPurposeEmailVerify, user.Email, emailVerifyTokenTTL)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := h.mailer.Send(c.Request.Context(), emailVerifyMessage(h.cfg, user.Email, token)); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Письмо с подтверждением отправлено"})
+}
+
+// GetSessions возвращает последние попытки входа в учетную запись
+// @Summary Последние входы
+// @Description Возвращает последние попытки входа текущего пользователя: IP, user-agent и результат
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Количество записей (по умолчанию 20, максимум 100)"
+// @Success 200 {object} models.LoginEventListResponse
+// @Failure 401 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /me/sessions [get]
+func (h *ProfileHandler) GetSessions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	rows, err := h.db.Query(`
+		SELECT id, COALESCE(ip_address, ''), COALESCE(user_agent, ''), outcome, created_at
+		FROM login_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, userID, limit)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	events := []models.LoginEvent{}
+	for rows.Next() {
+		var event models.LoginEvent
+		if err := rows.Scan(&event.ID, &event.IPAddress, &event.UserAgent, &event.Outcome, &event.CreatedAt); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LoginEventListResponse{Events: events})
+}
@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"api-go/apierror"
+	"api-go/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// roleColumns список колонок для выборки роли вместе с разрешениями
+const roleColumns = `r.id, r.name, COALESCE(r.description, ''), r.is_system,
+	ARRAY(
+		SELECT p.name FROM role_permissions rp JOIN permissions p ON p.id = rp.permission_id
+		WHERE rp.role_id = r.id ORDER BY p.name
+	),
+	r.created_at, r.updated_at`
+
+// errUnknownPermission возвращается, если в запросе указано несуществующее разрешение
+var errUnknownPermission = errors.New("неизвестное разрешение")
+
+// RoleHandler обрабатывает запросы администратора для управления ролями
+type RoleHandler struct {
+	db *sql.DB
+}
+
+// NewRoleHandler создает новый экземпляр RoleHandler
+func NewRoleHandler(db *sql.DB) *RoleHandler {
+	return &RoleHandler{
+		db: db,
+	}
+}
+
+// GetRoles возвращает список ролей
+// @Summary Список ролей
+// @Description Возвращает роли с их разрешениями (требует разрешение roles:manage)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Role
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/roles [get]
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	rows, err := h.db.Query(fmt.Sprintf("SELECT %s FROM roles r ORDER BY r.id", roleColumns))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	roles := []models.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		roles = append(roles, *role)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetPermissions возвращает список всех разрешений
+// @Summary Список разрешений
+// @Description Возвращает все разрешения, которые можно назначить ролям (требует разрешение roles:manage)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Permission
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/permissions [get]
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	rows, err := h.db.Query("SELECT id, name, COALESCE(description, '') FROM permissions ORDER BY name")
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	permissions := []models.Permission{}
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		permissions = append(permissions, p)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// CreateRole создает новую роль
+// @Summary Создание роли
+// @Description Создает роль с набором разрешений. Выдать можно только разрешения, которые есть у вас (требует разрешение roles:manage)
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role body models.RoleCreateRequest true "Данные роли"
+// @Success 201 {object} models.Role
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req models.RoleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	req.Name = strings.ToLower(strings.TrimSpace(req.Name))
+
+	if permission, ok := missingPermission(c, req.Permissions); !ok {
+		apierror.Respond(c, http.StatusForbidden, apierror.CodePermissionNotHeld, permission)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	var roleID int
+	err = tx.QueryRow(`
+		INSERT INTO roles (name, description) VALUES ($1, $2)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id`, req.Name, req.Description,
+	).Scan(&roleID)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeRoleExists)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := setRolePermissions(tx, roleID, req.Permissions); err != nil {
+		respondRolePermissionsError(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	h.respondWithRole(c, http.StatusCreated, req.Name)
+}
+
+// UpdateRole изменяет описание и разрешения роли
+// @Summary Изменение роли
+// @Description Изменяет описание роли и заменяет набор ее разрешений. Разрешения роли admin изменить нельзя.
+// @Description Нельзя изменить собственную роль, роль с разрешениями, которых нет у вас, и выдать такие разрешения (требует разрешение roles:manage)
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Название роли"
+// @Param role body models.RoleUpdateRequest true "Данные роли"
+// @Success 200 {object} models.Role
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/roles/{name} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	name := c.Param("name")
+
+	var req models.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	// Администратор всегда имеет все разрешения, иначе можно потерять доступ к управлению
+	if name == models.RoleAdmin && req.Permissions != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeAdminRoleProtected)
+		return
+	}
+
+	// Собственную роль менять нельзя, иначе можно расширить свои права
+	if role, _ := c.Get("role"); role == name {
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeCannotEditOwnRole)
+		return
+	}
+
+	if permission, ok := missingPermission(c, req.Permissions); !ok {
+		apierror.Respond(c, http.StatusForbidden, apierror.CodePermissionNotHeld, permission)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	// Изменять можно только роль, все разрешения которой есть у вас
+	role, err := scanRole(tx.QueryRow(fmt.Sprintf("SELECT %s FROM roles r WHERE r.name = $1 FOR UPDATE", roleColumns), name))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeRoleNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if permission, ok := missingPermission(c, role.Permissions); !ok {
+		apierror.Respond(c, http.StatusForbidden, apierror.CodePermissionNotHeld, permission)
+		return
+	}
+
+	roleID := role.ID
+	_, err = tx.Exec(`
+		UPDATE roles SET description = COALESCE($1, description), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2`, req.Description, roleID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if req.Permissions != nil {
+		if err := setRolePermissions(tx, roleID, req.Permissions); err != nil {
+			respondRolePermissionsError(c, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	h.respondWithRole(c, http.StatusOK, name)
+}
+
+// DeleteRole удаляет роль
+// @Summary Удаление роли
+// @Description Удаляет роль, если она не системная и не назначена пользователям (требует разрешение roles:manage)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Название роли"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/roles/{name} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	name := c.Param("name")
+
+	var isSystem bool
+	var usersCount int
+	err := h.db.QueryRow(`
+		SELECT r.is_system, (SELECT COUNT(*) FROM users u WHERE u.role = r.name)
+		FROM roles r WHERE r.name = $1`, name,
+	).Scan(&isSystem, &usersCount)
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeRoleNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if isSystem {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeSystemRoleProtected)
+		return
+	}
+	if usersCount > 0 {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeRoleInUse, usersCount)
+		return
+	}
+
+	if _, err := h.db.Exec("DELETE FROM roles WHERE name = $1", name); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Роль удалена"})
+}
+
+// respondWithRole загружает роль и отправляет ее в ответе
+func (h *RoleHandler) respondWithRole(c *gin.Context, status int, name string) {
+	role, err := scanRole(h.db.QueryRow(fmt.Sprintf("SELECT %s FROM roles r WHERE r.name = $1", roleColumns), name))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(status, role)
+}
+
+// setRolePermissions заменяет разрешения роли
+func setRolePermissions(db dbExecutor, roleID int, permissions []string) error {
+	if _, err := db.Exec("DELETE FROM role_permissions WHERE role_id = $1", roleID); err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, id FROM permissions WHERE name = ANY($2)`,
+		roleID, pq.Array(permissions))
+	if err != nil {
+		return err
+	}
+
+	// Каждое разрешение из запроса должно существовать
+	unique := make(map[string]struct{}, len(permissions))
+	for _, p := range permissions {
+		unique[p] = struct{}{}
+	}
+	if inserted, _ := result.RowsAffected(); int(inserted) != len(unique) {
+		return errUnknownPermission
+	}
+
+	return nil
+}
+
+// missingPermission возвращает первое из разрешений, которого нет у текущего пользователя
+// (у ключа API - в пределах разрешений ключа); ok = true, если есть все
+func missingPermission(c *gin.Context, permissions []string) (permission string, ok bool) {
+	granted, _ := c.Get("permissions")
+	grantedPermissions, _ := granted.([]string)
+	for _, permission := range permissions {
+		if !containsString(grantedPermissions, permission) {
+			return permission, false
+		}
+	}
+	return "", true
+}
+
+// respondRolePermissionsError отправляет ответ на ошибку назначения разрешений
+func respondRolePermissionsError(c *gin.Context, err error) {
+	if errors.Is(err, errUnknownPermission) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeUnknownPermission)
+		return
+	}
+	apierror.Internal(c, err)
+}
+
+// scanRole читает роль из строки результата
+func scanRole(row rowScanner) (*models.Role, error) {
+	var role models.Role
+	err := row.Scan(
+		&role.ID, &role.Name, &role.Description, &role.IsSystem,
+		pq.Array(&role.Permissions), &role.CreatedAt, &role.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
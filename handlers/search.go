@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"api-go/apierror"
+	"api-go/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ограничения подсказок поиска
+const (
+	suggestMinLength = 2   // подсказки показываются с этой длины запроса
+	suggestMaxLength = 100 // более длинный запрос обрезается
+	suggestLimit     = 5   // не больше подсказок каждого вида
+	// Популярный запрос попадает в подсказки, если его искали не меньше этого числа раз:
+	// иначе любой разовый запрос сразу показывался бы всем пользователям
+	suggestMinSearchCount = 5
+)
+
+// searchQueryStatColumns список колонок для выборки статистики запроса функцией scanSearchQueryStat
+const searchQueryStatColumns = `query, search_count, zero_result_count, last_result_count, is_blocked, first_searched_at, last_searched_at`
+
+// searchQueryMaxLength длина сохраняемого в статистике запроса (search_queries.query)
+const searchQueryMaxLength = 200
+
+// likeEscaper экранирует спецсимволы шаблона LIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchHandler обрабатывает запросы подсказок поиска и статистики поисковых запросов
+type SearchHandler struct {
+	db *sql.DB
+}
+
+// NewSearchHandler создает новый экземпляр SearchHandler
+func NewSearchHandler(db *sql.DB) *SearchHandler {
+	return &SearchHandler{
+		db: db,
+	}
+}
+
+// Suggest возвращает подсказки по началу поискового запроса
+// @Summary Подсказки поиска
+// @Description Возвращает продукты, категории и популярные запросы, начинающиеся с введенного текста или похожие на него (с учетом опечаток).
+// @Description Популярные запросы - те, что искали не меньше 5 раз, которые что-то находили и не заблокированы администратором.
+// @Description Запрос короче 2 символов возвращает пустые подсказки.
+// @Tags search
+// @Produce json
+// @Param q query string true "Начало запроса" example(айф)
+// @Param currency query string false "Валюта цен продуктов (ISO 4217), по умолчанию базовая"
+// @Param Accept-Currency header string false "Валюта цен, если не указан параметр currency"
+// @Success 200 {object} models.SearchSuggestions
+// @Failure 400 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /search/suggest [get]
+func (h *SearchHandler) Suggest(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if utf8.RuneCountInString(query) > suggestMaxLength {
+		query = string([]rune(query)[:suggestMaxLength])
+	}
+
+	suggestions := models.SearchSuggestions{
+		Query:      query,
+		Products:   []models.ProductSuggestion{},
+		Categories: []models.CategorySuggestion{},
+		Queries:    []string{},
+	}
+
+	// Подсказки одинаковы для всех пользователей и быстро устаревают только при изменении каталога
+	c.Header("Cache-Control", "public, max-age=60")
+
+	if utf8.RuneCountInString(query) < suggestMinLength {
+		c.JSON(http.StatusOK, suggestions)
+		return
+	}
+	prefix := likeEscaper.Replace(query) + "%"
+
+	rates, err := loadExchangeRates(h.db)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	currency, ok := requestCurrency(c, rates)
+	if !ok {
+		return
+	}
+
+	// Продукты: сначала начинающиеся с запроса, затем похожие по словам названия
+	rows, err := h.db.Query(`
+		SELECT id, name, price, currency, COALESCE(image_url, '')
+		FROM products
+		WHERE is_active = true AND deleted_at IS NULL AND (name ILIKE $2 OR $1 <% name)
+		ORDER BY name ILIKE $2 DESC, word_similarity($1, name) DESC, is_featured DESC, id
+		LIMIT $3`, query, prefix, suggestLimit)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	for rows.Next() {
+		var product models.ProductSuggestion
+		if err := rows.Scan(&product.ID, &product.Name, &product.Price, &product.Currency, &product.ImageURL); err != nil {
+			rows.Close()
+			apierror.Internal(c, err)
+			return
+		}
+		product.Price = convertPrice(product.Price, product.Currency, currency, rates)
+		product.Currency = currency
+		suggestions.Products = append(suggestions.Products, product)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Категории
+	rows, err = h.db.Query(`
+		SELECT id, name, slug
+		FROM categories
+		WHERE is_active = true AND (name ILIKE $2 OR $1 <% name)
+		ORDER BY name ILIKE $2 DESC, word_similarity($1, name) DESC, sort_order, id
+		LIMIT $3`, query, prefix, suggestLimit)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	for rows.Next() {
+		var category models.CategorySuggestion
+		if err := rows.Scan(&category.ID, &category.Name, &category.Slug); err != nil {
+			rows.Close()
+			apierror.Internal(c, err)
+			return
+		}
+		suggestions.Categories = append(suggestions.Categories, category)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Популярные запросы, которые что-то находили и не заблокированы
+	rows, err = h.db.Query(`
+		SELECT query
+		FROM search_queries
+		WHERE query LIKE $1 AND last_result_count > 0 AND search_count >= $3 AND NOT is_blocked
+		ORDER BY search_count DESC, query
+		LIMIT $2`, likeEscaper.Replace(normalizeSearchQuery(query))+"%", suggestLimit, suggestMinSearchCount)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var popular string
+		if err := rows.Scan(&popular); err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		suggestions.Queries = append(suggestions.Queries, popular)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// GetZeroResultQueries возвращает поисковые запросы, которые не нашли ни одного продукта
+// @Summary Запросы без результатов
+// @Description Возвращает поисковые запросы, последний раз не нашедшие ни одного продукта, самые частые первыми. Учитываются только выборки без других фильтров (требует разрешение search:read)
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество запросов на странице" default(50)
+// @Success 200 {object} models.SearchQueryStatListResponse
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/search/zero-results [get]
+func (h *SearchHandler) GetZeroResultQueries(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+	offset := (page - 1) * limit
+
+	var total int
+	if err := h.db.QueryRow("SELECT COUNT(*) FROM search_queries WHERE last_result_count = 0").Scan(&total); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	rows, err := h.db.Query(`
+		SELECT `+searchQueryStatColumns+`
+		FROM search_queries
+		WHERE last_result_count = 0
+		ORDER BY zero_result_count DESC, last_searched_at DESC
+		LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	queries := []models.SearchQueryStat{}
+	for rows.Next() {
+		q, err := scanSearchQueryStat(rows)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		queries = append(queries, *q)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SearchQueryStatListResponse{
+		Queries: queries,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	})
+}
+
+// GetBlockedQueries возвращает запросы, заблокированные в подсказках
+// @Summary Заблокированные запросы
+// @Description Возвращает поисковые запросы, которые не показываются в подсказках, по алфавиту (требует разрешение search:manage)
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {array} models.SearchQueryStat
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/search/blocked-queries [get]
+func (h *SearchHandler) GetBlockedQueries(c *gin.Context) {
+	rows, err := h.db.Query("SELECT " + searchQueryStatColumns + " FROM search_queries WHERE is_blocked ORDER BY query")
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	queries := []models.SearchQueryStat{}
+	for rows.Next() {
+		q, err := scanSearchQueryStat(rows)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		queries = append(queries, *q)
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, queries)
+}
+
+// BlockQuery блокирует поисковый запрос в подсказках
+// @Summary Блокировка запроса
+// @Description Запрещает показывать запрос в подсказках поиска. Запрос приводится к нижнему регистру; его можно заблокировать заранее, до первого поиска (требует разрешение search:manage)
+// @Tags search
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param query body models.SearchQueryBlockRequest true "Запрос"
+// @Success 200 {object} models.SearchQueryStat
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/search/blocked-queries [post]
+func (h *SearchHandler) BlockQuery(c *gin.Context) {
+	var req models.SearchQueryBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	query := normalizeSearchQuery(req.Query)
+	if query == "" {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeValidationFailed)
+		return
+	}
+
+	q, err := scanSearchQueryStat(h.db.QueryRow(`
+		INSERT INTO search_queries (query, is_blocked) VALUES ($1, true)
+		ON CONFLICT (query) DO UPDATE SET is_blocked = true
+		RETURNING `+searchQueryStatColumns, query))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, q)
+}
+
+// UnblockQuery снимает блокировку поискового запроса
+// @Summary Разблокировка запроса
+// @Description Снова разрешает показывать запрос в подсказках поиска (требует разрешение search:manage)
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param query query string true "Запрос"
+// @Success 200 {object} models.SearchQueryStat
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/search/blocked-queries [delete]
+func (h *SearchHandler) UnblockQuery(c *gin.Context) {
+	q, err := scanSearchQueryStat(h.db.QueryRow(`
+		UPDATE search_queries SET is_blocked = false
+		WHERE query = $1 AND is_blocked
+		RETURNING `+searchQueryStatColumns, normalizeSearchQuery(c.Query("query"))))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeSearchQueryNotBlocked)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, q)
+}
+
+// scanSearchQueryStat читает статистику запроса из строки результата с колонками searchQueryStatColumns
+func scanSearchQueryStat(row rowScanner) (*models.SearchQueryStat, error) {
+	var q models.SearchQueryStat
+	err := row.Scan(&q.Query, &q.SearchCount, &q.ZeroResultCount, &q.LastResultCount, &q.IsBlocked, &q.FirstSearchedAt, &q.LastSearchedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// recordSearchQuery учитывает поисковый запрос и количество найденных продуктов в статистике
+func recordSearchQuery(db dbExecutor, search string, results int) error {
+	query := normalizeSearchQuery(search)
+	if query == "" {
+		return nil
+	}
+
+	zero := 0
+	if results == 0 {
+		zero = 1
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO search_queries (query, search_count, zero_result_count, last_result_count)
+		VALUES ($1, 1, $2, $3)
+		ON CONFLICT (query) DO UPDATE SET
+			search_count = search_queries.search_count + 1,
+			zero_result_count = search_queries.zero_result_count + EXCLUDED.zero_result_count,
+			last_result_count = EXCLUDED.last_result_count,
+			last_searched_at = CURRENT_TIMESTAMP`,
+		query, zero, results)
+	return err
+}
+
+// normalizeSearchQuery приводит запрос к нижнему регистру, схлопывает пробелы и ограничивает длину
+func normalizeSearchQuery(search string) string {
+	query := strings.Join(strings.Fields(strings.ToLower(search)), " ")
+	if utf8.RuneCountInString(query) > searchQueryMaxLength {
+		query = string([]rune(query)[:searchQueryMaxLength])
+	}
+	return query
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"api-go/utils"
+)
+
+// errInvalidToken возвращается для неизвестного, использованного или просроченного токена
+var errInvalidToken = errors.New("токен недействителен или истек")
+
+// dbExecutor общий интерфейс для *sql.DB и *sql.Tx
+type dbExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// createUserToken создает одноразовый токен с указанным назначением.
+// Ранее выданные неиспользованные токены того же назначения гасятся.
+// Возвращает значение токена для отправки пользователю; в БД хранится только хеш.
+func createUserToken(db dbExecutor, userID int, purpose, data string, ttl time.Duration) (string, error) {
+	token, hash, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.Exec(`
+		UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		userID, purpose)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO user_tokens (user_id, purpose, token_hash, data, expires_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP + $5 * INTERVAL '1 second')`,
+		userID, purpose, hash, data, int(ttl.Seconds()))
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeUserToken проверяет токен и помечает его использованным.
+// Возвращает ID пользователя и данные токена.
+func consumeUserToken(db dbExecutor, purpose, token string) (int, string, error) {
+	var userID int
+	var data string
+	err := db.QueryRow(`
+		UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id, COALESCE(data, '')`,
+		utils.HashToken(token), purpose,
+	).Scan(&userID, &data)
+	if err == sql.ErrNoRows {
+		return 0, "", errInvalidToken
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	return userID, data, nil
+}
+
+// lookupUserToken проверяет токен, не помечая его использованным
+func lookupUserToken(db dbExecutor, purpose, token string) (int, string, error) {
+	var userID int
+	var data string
+	err := db.QueryRow(`
+		SELECT user_id, COALESCE(data, '') FROM user_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP`,
+		utils.HashToken(token), purpose,
+	).Scan(&userID, &data)
+	if err == sql.ErrNoRows {
+		return 0, "", errInvalidToken
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	return userID, data, nil
+}
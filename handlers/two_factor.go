@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"api-go/apierror"
+	"api-go/config"
+	"api-go/models"
+	"api-go/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recoveryCodeCount количество кодов восстановления, выдаваемых при включении 2FA
+const recoveryCodeCount = 10
+
+// TwoFactorHandler обрабатывает настройку двухфакторной аутентификации
+type TwoFactorHandler struct {
+	db  *sql.DB
+	cfg *config.Config
+}
+
+// NewTwoFactorHandler создает новый экземпляр TwoFactorHandler
+func NewTwoFactorHandler(db *sql.DB, cfg *config.Config) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// Setup создает секрет TOTP для подключения приложения-аутентификатора
+// @Summary Настройка 2FA
+// @Description Создает новый секрет TOTP и возвращает otpauth:// URI для QR-кода. 2FA включается после подтверждения кодом
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorSetupRequest true "Текущий пароль"
+// @Success 200 {object} models.TwoFactorSetupResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /me/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	var req models.TwoFactorSetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	var email, passwordHash string
+	var enabled bool
+	err := h.db.QueryRow("SELECT email, password, totp_enabled FROM users WHERE id = $1", userID).
+		Scan(&email, &passwordHash, &enabled)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if !utils.CheckPasswordHash(req.Password, passwordHash) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidPassword)
+		return
+	}
+
+	if enabled {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeTwoFactorAlreadyEnabled)
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	_, err = h.db.Exec(`
+		UPDATE users SET totp_secret = $1, totp_last_counter = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2`, secret, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPProvisioningURI(h.cfg.TwoFactor.Issuer, email, secret),
+	})
+}
+
+// Enable включает 2FA после проверки первого кода из приложения
+// @Summary Включение 2FA
+// @Description Проверяет код из приложения-аутентификатора, включает 2FA и возвращает коды восстановления (показываются один раз)
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorCodeRequest true "Код из приложения"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 409 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /me/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	var secret sql.NullString
+	var enabled bool
+	err := h.db.QueryRow("SELECT totp_secret, totp_enabled FROM users WHERE id = $1", userID).Scan(&secret, &enabled)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if enabled {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeTwoFactorAlreadyEnabled)
+		return
+	}
+	if !secret.Valid {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeTwoFactorSetupRequired)
+		return
+	}
+
+	counter, ok := utils.ValidateTOTP(secret.String, req.Code, time.Now())
+	if !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidTwoFactorCode)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE users SET totp_enabled = true, totp_last_counter = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2`, counter, userID)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(tx, userID.(int))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable отключает 2FA
+// @Summary Отключение 2FA
+// @Description Отключает двухфакторную аутентификацию после проверки пароля и кода. Неверные пароль и код учитываются в блокировке входа
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorDisableRequest true "Пароль и код"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 423 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /me/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	var req models.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	if respondIfLocked(c, h.db, h.cfg.Lockout, userID.(int)) {
+		return
+	}
+
+	var passwordHash string
+	if err := h.db.QueryRow("SELECT password FROM users WHERE id = $1", userID).Scan(&passwordHash); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if !utils.CheckPasswordHash(req.Password, passwordHash) {
+		h.registerFailedAttempt(userID.(int))
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidPassword)
+		return
+	}
+
+	ok, err := verifyTwoFactorCode(h.db, userID.(int), req.Code)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !ok {
+		h.registerFailedAttempt(userID.(int))
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidTwoFactorCode)
+		return
+	}
+
+	if err := resetTwoFactor(h.db, userID.(int)); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Двухфакторная аутентификация отключена"})
+}
+
+// RegenerateRecoveryCodes выдает новый набор кодов восстановления
+// @Summary Новые коды восстановления
+// @Description Заменяет коды восстановления новыми после проверки кода 2FA. Старые коды перестают действовать.
+// @Description Неверные коды учитываются в блокировке входа, как на втором шаге входа
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorCodeRequest true "Код из приложения"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 401 {object} apierror.Problem
+// @Failure 423 {object} apierror.Problem
+// @Failure 429 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /me/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthenticated)
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	var enabled bool
+	if err := h.db.QueryRow("SELECT totp_enabled FROM users WHERE id = $1", userID).Scan(&enabled); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !enabled {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeTwoFactorNotEnabled)
+		return
+	}
+
+	if respondIfLocked(c, h.db, h.cfg.Lockout, userID.(int)) {
+		return
+	}
+
+	ok, err := verifyTwoFactorCode(h.db, userID.(int), req.Code)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if !ok {
+		h.registerFailedAttempt(userID.(int))
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidTwoFactorCode)
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(tx, userID.(int))
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// registerFailedAttempt учитывает неверный пароль или код так же, как неудачную попытку входа
+func (h *TwoFactorHandler) registerFailedAttempt(userID int) {
+	if err := registerFailedLogin(h.db, h.cfg.Lockout, userID); err != nil {
+		log.Printf("Ошибка учета неудачной попытки: %v", err)
+	}
+}
+
+// verifyTwoFactorCode проверяет код TOTP или неиспользованный код восстановления.
+// Принятый код TOTP и код восстановления повторно не принимаются.
+func verifyTwoFactorCode(db dbExecutor, userID int, code string) (bool, error) {
+	var secret sql.NullString
+	var enabled bool
+	err := db.QueryRow("SELECT totp_secret, totp_enabled FROM users WHERE id = $1", userID).Scan(&secret, &enabled)
+	if err != nil {
+		return false, err
+	}
+	if !enabled || !secret.Valid {
+		return false, nil
+	}
+
+	if counter, ok := utils.ValidateTOTP(secret.String, code, time.Now()); ok {
+		result, err := db.Exec(`
+			UPDATE users SET totp_last_counter = $1
+			WHERE id = $2 AND (totp_last_counter IS NULL OR totp_last_counter < $1)`,
+			counter, userID)
+		if err != nil {
+			return false, err
+		}
+		affected, _ := result.RowsAffected()
+		return affected > 0, nil
+	}
+
+	result, err := db.Exec(`
+		UPDATE user_recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// replaceRecoveryCodes заменяет коды восстановления пользователя новыми.
+// Возвращает коды в открытом виде; в БД сохраняются только хеши.
+func replaceRecoveryCodes(db dbExecutor, userID int) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec("DELETE FROM user_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return nil, err
+	}
+
+	for _, code := range codes {
+		_, err := db.Exec(
+			"INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)",
+			userID, utils.HashToken(code))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+// resetTwoFactor отключает 2FA и удаляет коды восстановления
+func resetTwoFactor(db *sql.DB, userID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled = false, totp_last_counter = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM user_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
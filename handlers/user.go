@@ -0,0 +1,550 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"api-go/apierror"
+	"api-go/models"
+	"api-go/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// userColumns список колонок для выборки пользователя
+const userColumns = `id, username, email, role, COALESCE(first_name, ''), COALESCE(last_name, ''), COALESCE(phone, ''), COALESCE(pending_email, ''), email_verified_at, COALESCE(is_active, true), token_version, must_change_password, last_login_at, failed_login_attempts, locked_until, totp_enabled, created_at, updated_at`
+
+// UserHandler обрабатывает запросы администратора для управления пользователями
+type UserHandler struct {
+	db *sql.DB
+}
+
+// NewUserHandler создает новый экземпляр UserHandler
+func NewUserHandler(db *sql.DB) *UserHandler {
+	return &UserHandler{
+		db: db,
+	}
+}
+
+// GetUsers получает список пользователей с поиском и фильтрацией
+// @Summary Список пользователей
+// @Description Возвращает список пользователей с поиском по email и имени (требует разрешение users:read)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество элементов на странице" default(20)
+// @Param search query string false "Поиск по email или имени пользователя"
+// @Param role query string false "Фильтр по роли"
+// @Param is_active query bool false "Фильтр по активности"
+// @Success 200 {object} models.UserListResponse
+// @Failure 401 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/users [get]
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	// Формируем SQL запрос
+	whereClause := "WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if search := c.Query("search"); search != "" {
+		whereClause += fmt.Sprintf(" AND (email ILIKE $%d OR username ILIKE $%d)", argIndex, argIndex)
+		args = append(args, "%"+search+"%")
+		argIndex++
+	}
+
+	if role := c.Query("role"); role != "" {
+		whereClause += fmt.Sprintf(" AND role = $%d", argIndex)
+		args = append(args, role)
+		argIndex++
+	}
+
+	if isActive := c.Query("is_active"); isActive != "" {
+		active, err := strconv.ParseBool(isActive)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidQueryParam, "is_active")
+			return
+		}
+		whereClause += fmt.Sprintf(" AND COALESCE(is_active, true) = $%d", argIndex)
+		args = append(args, active)
+		argIndex++
+	}
+
+	// Получаем общее количество пользователей
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM users %s", whereClause)
+	if err := h.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	// Получаем пользователей
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM users %s
+		ORDER BY id ASC
+		LIMIT $%d OFFSET $%d
+	`, userColumns, whereClause, argIndex, argIndex+1)
+
+	args = append(args, limit, offset)
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer rows.Close()
+
+	users := []models.UserResponse{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			continue
+		}
+		users = append(users, user.ToResponse())
+	}
+	if err := rows.Err(); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.UserListResponse{
+		Users: users,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	})
+}
+
+// GetUser получает пользователя по ID
+// @Summary Получение пользователя по ID
+// @Description Возвращает информацию о пользователе (требует разрешение users:read)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Router /admin/users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidUserID)
+		return
+	}
+
+	user, err := h.getUserByID(id)
+	if err != nil {
+		respondUserLookupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// UpdateUserRole изменяет роль пользователя
+// @Summary Изменение роли пользователя
+// @Description Назначает пользователю роль. И текущая, и новая роль не должны иметь разрешений, которых нет у вас (требует разрешение users:manage)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Param role body models.UserRoleUpdateRequest true "Новая роль"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/users/{id}/role [put]
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidUserID)
+		return
+	}
+
+	var req models.UserRoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondValidation(c, err)
+		return
+	}
+
+	// Нельзя менять собственную роль
+	if currentUserID, _ := c.Get("user_id"); currentUserID == id {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeCannotChangeOwnRole)
+		return
+	}
+
+	if !authorizeUserAction(c, h.db, id) {
+		return
+	}
+
+	// Назначить можно только роль, все разрешения которой есть у самого администратора
+	var rolePermissions []string
+	err = h.db.QueryRow(`
+		SELECT ARRAY(
+			SELECT p.name FROM role_permissions rp JOIN permissions p ON p.id = rp.permission_id
+			WHERE rp.role_id = r.id
+		)
+		FROM roles r WHERE r.name = $1`, req.Role,
+	).Scan(pq.Array(&rolePermissions))
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeRoleNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	if permission, ok := missingPermission(c, rolePermissions); !ok {
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeRolePermissionNotHeld, permission)
+		return
+	}
+
+	result, err := h.db.Exec("UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", req.Role, id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound)
+		return
+	}
+
+	h.respondWithUser(c, id)
+}
+
+// DeactivateUser отключает учетную запись пользователя
+// @Summary Отключение пользователя
+// @Description Отключает учетную запись и отзывает все сессии пользователя. Нельзя отключить пользователя с разрешениями, которых нет у вас (требует разрешение users:manage)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/users/{id}/deactivate [post]
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidUserID)
+		return
+	}
+
+	if currentUserID, _ := c.Get("user_id"); currentUserID == id {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeCannotDeactivateSelf)
+		return
+	}
+
+	if !authorizeUserAction(c, h.db, id) {
+		return
+	}
+
+	result, err := h.db.Exec(`
+		UPDATE users SET is_active = false, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound)
+		return
+	}
+
+	h.respondWithUser(c, id)
+}
+
+// ActivateUser включает учетную запись пользователя
+// @Summary Включение пользователя
+// @Description Включает ранее отключенную учетную запись. Недоступно для своей учетной записи и пользователя с разрешениями, которых нет у вас (требует разрешение users:manage)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/users/{id}/activate [post]
+func (h *UserHandler) ActivateUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidUserID)
+		return
+	}
+
+	if currentUserID, _ := c.Get("user_id"); currentUserID == id {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeCannotManageSelf)
+		return
+	}
+
+	if !authorizeUserAction(c, h.db, id) {
+		return
+	}
+
+	result, err := h.db.Exec("UPDATE users SET is_active = true, updated_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound)
+		return
+	}
+
+	h.respondWithUser(c, id)
+}
+
+// UnlockUser снимает блокировку входа после неудачных попыток
+// @Summary Разблокировка входа
+// @Description Сбрасывает счетчик неудачных попыток входа и снимает временную блокировку.
+// @Description Недоступно для своей учетной записи и пользователя с разрешениями, которых нет у вас (требует разрешение users:manage)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidUserID)
+		return
+	}
+
+	if currentUserID, _ := c.Get("user_id"); currentUserID == id {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeCannotManageSelf)
+		return
+	}
+
+	if !authorizeUserAction(c, h.db, id) {
+		return
+	}
+
+	result, err := h.db.Exec(`
+		UPDATE users SET failed_login_attempts = 0, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound)
+		return
+	}
+
+	h.respondWithUser(c, id)
+}
+
+// ResetTwoFactor отключает 2FA пользователя, потерявшего устройство и коды восстановления
+// @Summary Сброс 2FA
+// @Description Отключает двухфакторную аутентификацию пользователя и удаляет коды восстановления.
+// @Description Недоступно для своей учетной записи и пользователя с разрешениями, которых нет у вас (требует разрешение users:manage)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/users/{id}/2fa/reset [post]
+func (h *UserHandler) ResetTwoFactor(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidUserID)
+		return
+	}
+
+	if currentUserID, _ := c.Get("user_id"); currentUserID == id {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeCannotManageSelf)
+		return
+	}
+
+	if !authorizeUserAction(c, h.db, id) {
+		return
+	}
+
+	if err := resetTwoFactor(h.db, id); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	h.respondWithUser(c, id)
+}
+
+// ForcePasswordReset принудительно сбрасывает пароль пользователя
+// @Summary Принудительный сброс пароля
+// @Description Устанавливает временный пароль, отзывает все сессии и требует смены пароля при входе.
+// @Description Недоступно для своей учетной записи и пользователя с разрешениями, которых нет у вас (требует разрешение users:manage)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} models.PasswordResetForcedResponse
+// @Failure 400 {object} apierror.Problem
+// @Failure 403 {object} apierror.Problem
+// @Failure 404 {object} apierror.Problem
+// @Failure 500 {object} apierror.Problem
+// @Router /admin/users/{id}/force-password-reset [post]
+func (h *UserHandler) ForcePasswordReset(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidUserID)
+		return
+	}
+
+	if currentUserID, _ := c.Get("user_id"); currentUserID == id {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeCannotManageSelf)
+		return
+	}
+
+	if !authorizeUserAction(c, h.db, id) {
+		return
+	}
+
+	temporaryPassword, err := utils.GenerateRandomPassword()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(temporaryPassword)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	result, err := h.db.Exec(`
+		UPDATE users
+		SET password = $1, must_change_password = true, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2`, hashedPassword, id)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PasswordResetForcedResponse{
+		Message:           "Пароль сброшен. Передайте временный пароль пользователю",
+		TemporaryPassword: temporaryPassword,
+	})
+}
+
+// Вспомогательные методы
+
+// authorizeUserAction проверяет, что все разрешения роли пользователя есть у текущего пользователя:
+// иначе можно перехватить учетную запись с большими правами. При отказе отправляет ответ и возвращает false.
+func authorizeUserAction(c *gin.Context, db dbExecutor, id int) bool {
+	var userPermissions []string
+	err := db.QueryRow(`
+		SELECT ARRAY(
+			SELECT p.name FROM roles r
+			JOIN role_permissions rp ON rp.role_id = r.id
+			JOIN permissions p ON p.id = rp.permission_id
+			WHERE r.name = u.role
+		)
+		FROM users u WHERE u.id = $1`, id,
+	).Scan(pq.Array(&userPermissions))
+	if err != nil {
+		respondUserLookupError(c, err)
+		return false
+	}
+
+	if permission, ok := missingPermission(c, userPermissions); !ok {
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeUserPermissionNotHeld, permission)
+		return false
+	}
+	return true
+}
+
+// getUserByID получает пользователя по ID
+func (h *UserHandler) getUserByID(id int) (*models.User, error) {
+	return getUserByID(h.db, id)
+}
+
+// respondWithUser отправляет актуальные данные пользователя
+func (h *UserHandler) respondWithUser(c *gin.Context, id int) {
+	user, err := h.getUserByID(id)
+	if err != nil {
+		respondUserLookupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// getUserByID получает пользователя по ID
+func getUserByID(db *sql.DB, id int) (*models.User, error) {
+	row := db.QueryRow(fmt.Sprintf("SELECT %s FROM users WHERE id = $1", userColumns), id)
+	return scanUser(row)
+}
+
+// respondUserLookupError отправляет ответ на ошибку поиска пользователя
+func respondUserLookupError(c *gin.Context, err error) {
+	if err == sql.ErrNoRows {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound)
+		return
+	}
+	apierror.Internal(c, err)
+}
+
+// rowScanner общий интерфейс для *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser считывает пользователя из строки результата, выбранной с userColumns
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID, &user.Username, &user.Email, &user.Role,
+		&user.FirstName, &user.LastName, &user.Phone, &user.PendingEmail, &user.EmailVerifiedAt, &user.IsActive,
+		&user.TokenVersion, &user.MustChangePassword, &user.LastLoginAt,
+		&user.FailedLoginCount, &user.LockedUntil, &user.TOTPEnabled,
+		&user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// containsString проверяет наличие строки в списке
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
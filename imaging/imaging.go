@@ -0,0 +1,186 @@
+// Package imaging проверяет загружаемые изображения и создает их миниатюры
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	_ "image/gif" // Декодер GIF для image.Decode
+)
+
+// MaxPixels ограничивает размер изображения в пикселях, чтобы небольшой файл
+// с огромными размерами не занял всю память при декодировании
+const MaxPixels = 50_000_000
+
+// jpegQuality качество миниатюр в формате JPEG
+const jpegQuality = 85
+
+// Ошибки проверки изображения
+var (
+	ErrUnsupportedType = errors.New("неподдерживаемый формат изображения")
+	ErrInvalidImage    = errors.New("файл поврежден или не является изображением")
+	ErrTooManyPixels   = errors.New("слишком большое разрешение изображения")
+)
+
+// Форматы изображений, которые принимаются при загрузке, и их расширения
+var formats = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Size размер миниатюры: большая сторона не длиннее Max пикселей
+type Size struct {
+	Name string
+	Max  int
+}
+
+// ThumbnailSizes размеры миниатюр, создаваемых для каждого изображения
+var ThumbnailSizes = []Size{
+	{Name: "small", Max: 160},
+	{Name: "medium", Max: 480},
+	{Name: "large", Max: 1200},
+}
+
+// Image декодированное изображение
+type Image struct {
+	image.Image
+	ContentType string // image/jpeg, image/png или image/gif
+	Ext         string // Расширение файла с точкой
+	Width       int
+	Height      int
+}
+
+// Decode определяет формат по содержимому (а не по имени файла или заголовкам клиента)
+// и декодирует изображение. У анимированного GIF используется первый кадр.
+func Decode(data []byte) (*Image, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := formats[contentType]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	return &Image{
+		Image:       img,
+		ContentType: contentType,
+		Ext:         ext,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}, nil
+}
+
+// Thumbnail уменьшает изображение так, чтобы большая сторона стала max пикселей.
+// Возвращает ok = false, если изображение и так не больше max: увеличивать его незачем.
+func (img *Image) Thumbnail(max int) (thumb image.Image, ok bool) {
+	if img.Width <= max && img.Height <= max {
+		return nil, false
+	}
+
+	width, height := max, max
+	if img.Width >= img.Height {
+		height = (img.Height*max + img.Width/2) / img.Width
+	} else {
+		width = (img.Width*max + img.Height/2) / img.Height
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	return resize(toRGBA(img.Image), width, height), true
+}
+
+// Encode кодирует миниатюру в формат исходного изображения: JPEG для JPEG, PNG для PNG и GIF
+// (GIF с ограниченной палитрой заметно теряет качество при уменьшении)
+func (img *Image) Encode(thumb image.Image) (data []byte, contentType, ext string, err error) {
+	var buf bytes.Buffer
+	if img.ContentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: jpegQuality})
+		contentType, ext = "image/jpeg", ".jpg"
+	} else {
+		err = png.Encode(&buf, thumb)
+		contentType, ext = "image/png", ".png"
+	}
+	if err != nil {
+		return nil, "", "", fmt.Errorf("ошибка кодирования миниатюры: %w", err)
+	}
+	return buf.Bytes(), contentType, ext, nil
+}
+
+// toRGBA приводит изображение к *image.RGBA (с предумноженной альфой),
+// чтобы усреднение прозрачных пикселей не давало темных ореолов
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok {
+		return rgba
+	}
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+	return dst
+}
+
+// resize уменьшает изображение усреднением по площади: каждый пиксель результата -
+// среднее всех пикселей исходника, которые на него приходятся
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for dy := 0; dy < height; dy++ {
+		y0, y1 := dy*srcHeight/height, (dy+1)*srcHeight/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for dx := 0; dx < width; dx++ {
+			x0, x1 := dx*srcWidth/width, (dx+1)*srcWidth/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a uint64
+			for y := y0; y < y1; y++ {
+				offset := src.PixOffset(bounds.Min.X+x0, bounds.Min.Y+y)
+				for x := x0; x < x1; x++ {
+					r += uint64(src.Pix[offset])
+					g += uint64(src.Pix[offset+1])
+					b += uint64(src.Pix[offset+2])
+					a += uint64(src.Pix[offset+3])
+					offset += 4
+				}
+			}
+
+			n := uint64((y1 - y0) * (x1 - x0))
+			offset := dst.PixOffset(dx, dy)
+			dst.Pix[offset] = uint8((r + n/2) / n)
+			dst.Pix[offset+1] = uint8((g + n/2) / n)
+			dst.Pix[offset+2] = uint8((b + n/2) / n)
+			dst.Pix[offset+3] = uint8((a + n/2) / n)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,186 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage создает непрозрачное изображение заданного размера
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// encodeTestImage кодирует изображение в формат format: png, jpeg или gif
+func encodeTestImage(t *testing.T, format string, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, testImage(width, height))
+	case "jpeg":
+		err = jpeg.Encode(&buf, testImage(width, height), nil)
+	case "gif":
+		err = gif.Encode(&buf, testImage(width, height), nil)
+	default:
+		t.Fatalf("неизвестный формат %s", format)
+	}
+	if err != nil {
+		t.Fatalf("ошибка кодирования %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// pngWithSize возвращает PNG, в заголовке которого указан размер width x height.
+// Данные изображения не меняются, поэтому декодировать его целиком нельзя.
+func pngWithSize(t *testing.T, width, height uint32) []byte {
+	t.Helper()
+
+	data := encodeTestImage(t, "png", 1, 1)
+	// Сигнатура (8 байт), длина и тип чанка IHDR (8 байт), затем ширина и высота
+	binary.BigEndian.PutUint32(data[16:20], width)
+	binary.BigEndian.PutUint32(data[20:24], height)
+	// CRC считается по типу и данным чанка IHDR (4 + 13 байт)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestDecode(t *testing.T) {
+	pngData := encodeTestImage(t, "png", 40, 30)
+
+	tests := []struct {
+		name            string
+		data            []byte
+		wantErr         error
+		wantContentType string
+		wantExt         string
+		wantWidth       int
+		wantHeight      int
+	}{
+		{name: "png", data: pngData, wantContentType: "image/png", wantExt: ".png", wantWidth: 40, wantHeight: 30},
+		{name: "jpeg", data: encodeTestImage(t, "jpeg", 64, 48), wantContentType: "image/jpeg", wantExt: ".jpg", wantWidth: 64, wantHeight: 48},
+		{name: "gif", data: encodeTestImage(t, "gif", 20, 50), wantContentType: "image/gif", wantExt: ".gif", wantWidth: 20, wantHeight: 50},
+		{name: "текст", data: []byte("not an image"), wantErr: ErrUnsupportedType},
+		{name: "svg", data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), wantErr: ErrUnsupportedType},
+		{name: "пустой файл", data: nil, wantErr: ErrUnsupportedType},
+		{name: "обрезанный png", data: pngData[:40], wantErr: ErrInvalidImage},
+		{name: "png нулевого размера", data: pngWithSize(t, 0, 10), wantErr: ErrInvalidImage},
+		{name: "слишком много пикселей", data: pngWithSize(t, 10000, 10000), wantErr: ErrTooManyPixels},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Decode(tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if img.ContentType != tt.wantContentType || img.Ext != tt.wantExt {
+				t.Errorf("Decode() type = %s %s, want %s %s", img.ContentType, img.Ext, tt.wantContentType, tt.wantExt)
+			}
+			if img.Width != tt.wantWidth || img.Height != tt.wantHeight {
+				t.Errorf("Decode() size = %dx%d, want %dx%d", img.Width, img.Height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name       string
+		width      int
+		height     int
+		max        int
+		wantOK     bool
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "меньше max", width: 100, height: 50, max: 160, wantOK: false},
+		{name: "равно max", width: 160, height: 160, max: 160, wantOK: false},
+		{name: "альбомная", width: 800, height: 600, max: 160, wantOK: true, wantWidth: 160, wantHeight: 120},
+		{name: "портретная", width: 600, height: 800, max: 160, wantOK: true, wantWidth: 120, wantHeight: 160},
+		{name: "квадратная", width: 500, height: 500, max: 480, wantOK: true, wantWidth: 480, wantHeight: 480},
+		{name: "округление", width: 333, height: 200, max: 160, wantOK: true, wantWidth: 160, wantHeight: 96},
+		{name: "одна сторона больше max", width: 200, height: 100, max: 160, wantOK: true, wantWidth: 160, wantHeight: 80},
+		{name: "узкая полоса не короче пикселя", width: 1000, height: 2, max: 160, wantOK: true, wantWidth: 160, wantHeight: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := &Image{Image: testImage(tt.width, tt.height), ContentType: "image/png", Ext: ".png", Width: tt.width, Height: tt.height}
+
+			thumb, ok := img.Thumbnail(tt.max)
+			if ok != tt.wantOK {
+				t.Fatalf("Thumbnail() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if size := thumb.Bounds().Size(); size.X != tt.wantWidth || size.Y != tt.wantHeight {
+				t.Errorf("Thumbnail() size = %dx%d, want %dx%d", size.X, size.Y, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		format          string
+		wantContentType string
+		wantExt         string
+	}{
+		{format: "jpeg", wantContentType: "image/jpeg", wantExt: ".jpg"},
+		{format: "png", wantContentType: "image/png", wantExt: ".png"},
+		{format: "gif", wantContentType: "image/png", wantExt: ".png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			img, err := Decode(encodeTestImage(t, tt.format, 320, 200))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			thumb, ok := img.Thumbnail(ThumbnailSizes[0].Max)
+			if !ok {
+				t.Fatal("Thumbnail() ok = false")
+			}
+
+			data, contentType, ext, err := img.Encode(thumb)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if contentType != tt.wantContentType || ext != tt.wantExt {
+				t.Errorf("Encode() type = %s %s, want %s %s", contentType, ext, tt.wantContentType, tt.wantExt)
+			}
+
+			// Миниатюра снова проходит проверку загрузки и сохраняет размер
+			decoded, err := Decode(data)
+			if err != nil {
+				t.Fatalf("Decode(миниатюра) error = %v", err)
+			}
+			if decoded.Width != 160 || decoded.Height != 100 {
+				t.Errorf("миниатюра %dx%d, want 160x100", decoded.Width, decoded.Height)
+			}
+		})
+	}
+}
@@ -1,16 +1,51 @@
 -- Инициализация базы данных для Products API с интернет-магазином
 
+-- Расширения: триграммы для поиска с опечатками
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Создание таблиц ролей и разрешений
+CREATE TABLE IF NOT EXISTS roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(20) UNIQUE NOT NULL,
+    description TEXT,
+    is_system BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
 -- Создание таблицы пользователей
 CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
     username VARCHAR(50) UNIQUE NOT NULL,
     email VARCHAR(100) UNIQUE NOT NULL,
     password VARCHAR(255) NOT NULL,
-    role VARCHAR(20) DEFAULT 'user',
+    role VARCHAR(20) DEFAULT 'user' CONSTRAINT fk_users_role REFERENCES roles(name) ON UPDATE CASCADE,
     first_name VARCHAR(50),
     last_name VARCHAR(50),
     phone VARCHAR(20),
     is_active BOOLEAN DEFAULT true,
+    token_version INTEGER NOT NULL DEFAULT 0,
+    must_change_password BOOLEAN NOT NULL DEFAULT false,
+    last_login_at TIMESTAMP,
+    pending_email VARCHAR(100),
+    email_verified_at TIMESTAMP,
+    failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    totp_secret VARCHAR(64),
+    totp_enabled BOOLEAN NOT NULL DEFAULT false,
+    totp_last_counter BIGINT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -29,12 +64,21 @@ CREATE TABLE IF NOT EXISTS categories (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Создание таблицы курсов валют (rate - стоимость единицы валюты в базовой валюте RUB)
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency CHAR(3) PRIMARY KEY CHECK (currency ~ '^[A-Z]{3}$'),
+    rate DECIMAL(18,8) NOT NULL CHECK (rate > 0),
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Создание таблицы продуктов
 CREATE TABLE IF NOT EXISTS products (
     id SERIAL PRIMARY KEY,
     name VARCHAR(200) NOT NULL,
     description TEXT,
     price DECIMAL(10,2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'RUB' REFERENCES exchange_rates(currency),
     category_id INTEGER REFERENCES categories(id),
     stock INTEGER DEFAULT 0,
     stock_type VARCHAR(50) DEFAULT 'piece',
@@ -46,9 +90,130 @@ CREATE TABLE IF NOT EXISTS products (
     is_featured BOOLEAN DEFAULT false,
     sort_order INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    sale_price DECIMAL(10,2),
+    sale_ends_at TIMESTAMP,
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(sku, '')), 'A') ||
+        setweight(to_tsvector('russian', COALESCE(description, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+    ) STORED
+);
+
+-- Создание таблицы вариантов продуктов (свой SKU, цена и остаток)
+CREATE TABLE IF NOT EXISTS product_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku VARCHAR(100) UNIQUE NOT NULL,
+    price DECIMAL(10,2),
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    attributes JSONB NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Создание таблицы характеристик продуктов (бренд, материал, диагональ и др.)
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('string', 'number', 'boolean')),
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    is_filterable BOOLEAN NOT NULL DEFAULT true,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Создание таблицы набора характеристик категорий
+CREATE TABLE IF NOT EXISTS category_attributes (
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES attribute_definitions(id) ON DELETE CASCADE,
+    PRIMARY KEY (category_id, attribute_id)
+);
+
+-- Создание таблицы значений характеристик продуктов
+CREATE TABLE IF NOT EXISTS product_attribute_values (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES attribute_definitions(id) ON DELETE CASCADE,
+    value_text VARCHAR(255),
+    value_number DECIMAL(14,4),
+    value_boolean BOOLEAN,
+    PRIMARY KEY (product_id, attribute_id),
+    CHECK (num_nonnulls(value_text, value_number, value_boolean) = 1)
+);
+
+-- Создание таблицы изображений продуктов
+CREATE TABLE IF NOT EXISTS product_images (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    storage_key VARCHAR(255) UNIQUE NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    thumbnails JSONB NOT NULL DEFAULT '{}',
+    alt_text VARCHAR(255) NOT NULL DEFAULT '',
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Создание таблицы ревизий продуктов (состояние до каждого изменения)
+CREATE TABLE IF NOT EXISTS product_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('update', 'import', 'rollback')),
+    data JSONB NOT NULL,
+    changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    changed_by_username VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, revision)
+);
+
+-- Создание таблицы истории цен (периоды действия цены; у текущей valid_to IS NULL)
+CREATE TABLE IF NOT EXISTS price_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price DECIMAL(10,2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    valid_from TIMESTAMP NOT NULL,
+    valid_to TIMESTAMP
+);
+
+-- Создание таблицы запланированных цен продуктов (при пересечении действует наименьшая)
+CREATE TABLE IF NOT EXISTS product_sale_prices (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sale_price DECIMAL(10,2) NOT NULL CHECK (sale_price > 0),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+-- Создание таблицы акций (скидка в процентах на категорию)
+CREATE TABLE IF NOT EXISTS sale_campaigns (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    discount_percent DECIMAL(5,2) NOT NULL CHECK (discount_percent > 0 AND discount_percent < 100),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
 -- Создание таблицы заказов
 CREATE TABLE IF NOT EXISTS orders (
     id SERIAL PRIMARY KEY,
@@ -62,6 +227,8 @@ CREATE TABLE IF NOT EXISTS orders (
     payment_method VARCHAR(50) NOT NULL,
     payment_status VARCHAR(20) DEFAULT 'pending',
     notes TEXT,
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    exchange_rate DECIMAL(18,8) NOT NULL DEFAULT 1,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -71,6 +238,9 @@ CREATE TABLE IF NOT EXISTS order_items (
     id SERIAL PRIMARY KEY,
     order_id INTEGER REFERENCES orders(id) NOT NULL,
     product_id INTEGER REFERENCES products(id) NOT NULL,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE SET NULL,
+    variant_sku VARCHAR(100),
+    variant_attributes JSONB,
     quantity INTEGER NOT NULL,
     price DECIMAL(10,2) NOT NULL,
     discount DECIMAL(10,2) DEFAULT 0,
@@ -83,11 +253,12 @@ CREATE TABLE IF NOT EXISTS cart_items (
     id SERIAL PRIMARY KEY,
     user_id INTEGER REFERENCES users(id) NOT NULL,
     product_id INTEGER REFERENCES products(id) NOT NULL,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE CASCADE,
     quantity INTEGER NOT NULL DEFAULT 1,
     price DECIMAL(10,2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    UNIQUE(user_id, product_id)
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 -- Создание таблицы отзывов
@@ -140,18 +311,160 @@ CREATE TABLE IF NOT EXISTS order_history (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Создание таблицы одноразовых токенов пользователей (хранится только хеш)
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    data TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Создание таблицы журнала входов
+CREATE TABLE IF NOT EXISTS login_events (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(100) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    outcome VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Создание таблицы кодов восстановления 2FA (хранится только хеш)
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Создание таблицы API ключей (хранится только хеш)
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) UNIQUE NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Создание таблицы учетных записей у провайдеров OIDC
+CREATE TABLE IF NOT EXISTS user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+-- Создание таблицы незавершенных входов через OIDC
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Создание таблицы статистики поисковых запросов
+CREATE TABLE IF NOT EXISTS search_queries (
+    query VARCHAR(200) PRIMARY KEY,
+    search_count INTEGER NOT NULL DEFAULT 0,
+    zero_result_count INTEGER NOT NULL DEFAULT 0,
+    last_result_count INTEGER NOT NULL DEFAULT 0,
+    is_blocked BOOLEAN NOT NULL DEFAULT false,
+    first_searched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_searched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Создание таблицы задач импорта продуктов
+CREATE TABLE IF NOT EXISTS product_import_jobs (
+    id SERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'jsonl')),
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    created_count INTEGER NOT NULL DEFAULT 0,
+    updated_count INTEGER NOT NULL DEFAULT 0,
+    unchanged_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    errors JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+-- Создание таблицы журнала действий администраторов
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    actor_username VARCHAR(50) NOT NULL DEFAULT '',
+    api_key_id INTEGER REFERENCES api_keys(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    ip_address VARCHAR(45),
+    request_id VARCHAR(128),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Создание индексов для оптимизации
 CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id);
 CREATE INDEX IF NOT EXISTS idx_products_is_active ON products(is_active);
 CREATE INDEX IF NOT EXISTS idx_products_price ON products(price);
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products(deleted_at) WHERE deleted_at IS NOT NULL;
 CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders(user_id);
 CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status);
 CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
 CREATE INDEX IF NOT EXISTS idx_cart_items_user_id ON cart_items(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_items_user_product_variant ON cart_items(user_id, product_id, COALESCE(variant_id, 0));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_attributes ON product_variants(product_id, attributes);
+CREATE INDEX IF NOT EXISTS idx_category_attributes_attribute_id ON category_attributes(attribute_id);
+CREATE INDEX IF NOT EXISTS idx_product_attribute_values_text ON product_attribute_values(attribute_id, value_text);
+CREATE INDEX IF NOT EXISTS idx_product_attribute_values_number ON product_attribute_values(attribute_id, value_number);
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON product_images(product_id, sort_order);
 CREATE INDEX IF NOT EXISTS idx_reviews_product_id ON reviews(product_id);
 CREATE INDEX IF NOT EXISTS idx_reviews_rating ON reviews(rating);
 CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
 CREATE INDEX IF NOT EXISTS idx_categories_slug ON categories(slug);
+CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_search_queries_prefix ON search_queries(query text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_product_import_jobs_created_at ON product_import_jobs(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_price_history_product_id ON price_history(product_id, valid_from);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_history_current ON price_history(product_id) WHERE valid_to IS NULL;
+CREATE INDEX IF NOT EXISTS idx_product_sale_prices_product_id ON product_sale_prices(product_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_sale_campaigns_category_id ON sale_campaigns(category_id);
+CREATE INDEX IF NOT EXISTS idx_search_queries_zero_results ON search_queries(zero_result_count DESC) WHERE last_result_count = 0;
+CREATE INDEX IF NOT EXISTS idx_search_queries_blocked ON search_queries(query) WHERE is_blocked = true;
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_purpose ON user_tokens(user_id, purpose);
+CREATE INDEX IF NOT EXISTS idx_login_events_user_created ON login_events(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at DESC);
 
 -- Создание триггеров для автоматического обновления updated_at
 CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -164,18 +477,57 @@ $$ language 'plpgsql';
 
 CREATE TRIGGER update_users_updated_at BEFORE UPDATE ON users FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_products_updated_at BEFORE UPDATE ON products FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_product_variants_updated_at BEFORE UPDATE ON product_variants FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_attribute_definitions_updated_at BEFORE UPDATE ON attribute_definitions FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_categories_updated_at BEFORE UPDATE ON categories FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_orders_updated_at BEFORE UPDATE ON orders FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_cart_items_updated_at BEFORE UPDATE ON cart_items FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_reviews_updated_at BEFORE UPDATE ON reviews FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_coupons_updated_at BEFORE UPDATE ON coupons FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_sale_campaigns_updated_at BEFORE UPDATE ON sale_campaigns FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
 -- Вставка тестовых данных
 
--- Тестовый администратор
-INSERT INTO users (username, email, password, role, first_name, last_name) VALUES 
-('admin', 'admin@test.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'admin', 'Admin', 'User')
-ON CONFLICT (username) DO NOTHING;
+-- Роли и разрешения
+INSERT INTO roles (name, description, is_system) VALUES
+('user', 'Покупатель', true),
+('admin', 'Администратор, все разрешения', true),
+('warehouse', 'Сотрудник склада: просмотр заказов и изменение их статуса', false)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+('products:create', 'Создание продуктов'),
+('products:update', 'Изменение продуктов'),
+('products:delete', 'Удаление продуктов'),
+('orders:read_all', 'Просмотр всех заказов'),
+('orders:update', 'Изменение заказов и их статуса'),
+('users:read', 'Просмотр пользователей'),
+('users:manage', 'Управление пользователями: роли, блокировка, сброс пароля и 2FA'),
+('roles:manage', 'Управление ролями и их разрешениями'),
+('cache:manage', 'Сброс кэша'),
+('api_keys:manage', 'Выпуск и отзыв API ключей'),
+('audit:read', 'Просмотр и выгрузка журнала действий'),
+('attributes:manage', 'Управление характеристиками продуктов и их набором в категориях'),
+('search:read', 'Просмотр статистики поисковых запросов'),
+('search:manage', 'Блокировка поисковых запросов в подсказках'),
+('products:import', 'Импорт продуктов из файла'),
+('products:export', 'Выгрузка каталога продуктов'),
+('campaigns:manage', 'Управление акциями на категории'),
+('currencies:manage', 'Управление курсами валют')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN ('orders:read_all', 'orders:update')
+WHERE r.name = 'warehouse'
+ON CONFLICT DO NOTHING;
+
+-- Администратор не создается с известным паролем.
+-- Создайте его командой: api-go create-admin --email admin@example.com
 
 -- Тестовый пользователь
 INSERT INTO users (username, email, password, role, first_name, last_name) VALUES 
@@ -198,6 +550,10 @@ INSERT INTO categories (name, description, slug, parent_id, sort_order) VALUES
 ('Женская одежда', 'Одежда для женщин', 'womens-clothing', 2, 2)
 ON CONFLICT (slug) DO NOTHING;
 
+-- Базовая валюта
+INSERT INTO exchange_rates (currency, rate) VALUES ('RUB', 1)
+ON CONFLICT (currency) DO NOTHING;
+
 -- Продукты
 INSERT INTO products (name, description, price, category_id, stock, sku, is_featured) VALUES 
 ('iPhone 15 Pro', 'Новейший смартфон Apple с мощным процессором', 99999.99, 5, 50, 'IPHONE15PRO', true),
@@ -208,11 +564,21 @@ INSERT INTO products (name, description, price, category_id, stock, sku, is_feat
 ('Футбольный мяч', 'Профессиональный футбольный мяч', 3999.99, 4, 30, 'BALL-FOOTBALL', false)
 ON CONFLICT (sku) DO NOTHING;
 
+-- Начальные цены в истории цен
+INSERT INTO price_history (product_id, price, valid_from)
+SELECT p.id, p.price, p.created_at
+FROM products p
+WHERE NOT EXISTS (SELECT 1 FROM price_history ph WHERE ph.product_id = p.id);
+
 -- Тестовые отзывы
-INSERT INTO reviews (user_id, product_id, rating, title, comment, is_verified) VALUES 
-(2, 1, 5, 'Отличный телефон!', 'Очень доволен покупкой. Камера работает великолепно, батарея держит долго.', true),
-(2, 2, 4, 'Хороший ноутбук', 'Мощный и легкий. Единственный минус - высокая цена.', true),
-(2, 3, 5, 'Качественная футболка', 'Материал приятный, размер соответствует.', true)
+INSERT INTO reviews (user_id, product_id, rating, title, comment, is_verified)
+SELECT u.id, v.product_id, v.rating, v.title, v.comment, true
+FROM users u, (VALUES
+(1, 5, 'Отличный телефон!', 'Очень доволен покупкой. Камера работает великолепно, батарея держит долго.'),
+(2, 4, 'Хороший ноутбук', 'Мощный и легкий. Единственный минус - высокая цена.'),
+(3, 5, 'Качественная футболка', 'Материал приятный, размер соответствует.')
+) AS v(product_id, rating, title, comment)
+WHERE u.email = 'user@test.com'
 ON CONFLICT DO NOTHING;
 
 -- Тестовые купоны
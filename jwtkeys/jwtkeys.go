@@ -0,0 +1,339 @@
+// Package jwtkeys управляет ключами подписи JWT: общим секретом HS256
+// или набором асимметричных ключей RS256/EdDSA с ротацией и публикацией в JWKS
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"api-go/config"
+)
+
+// hmacKeyID идентификатор ключа для режима HS256
+const hmacKeyID = "hs256"
+
+// keyIDLayout формат идентификатора ключа: время создания, чтобы ключи сортировались по возрасту
+const keyIDLayout = "20060102T150405Z"
+
+// ActivationDelay время между созданием ключа и началом подписи им.
+// За это время ключ попадает в кэши JWKS других сервисов.
+const ActivationDelay = 10 * time.Minute
+
+// ErrNoSigningKey возвращается, если в каталоге нет ключа для настроенного алгоритма
+var ErrNoSigningKey = errors.New("нет ключа подписи JWT: выполните команду keys rotate")
+
+// Key ключ подписи или проверки JWT
+type Key struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+
+	// private закрытый ключ (crypto.Signer) или секрет HS256 ([]byte)
+	private interface{}
+	// public открытый ключ; для HS256 совпадает с секретом
+	public interface{}
+}
+
+// SigningKey возвращает значение для подписи токена
+func (k *Key) SigningKey() interface{} {
+	return k.private
+}
+
+// VerificationKey возвращает значение для проверки подписи токена
+func (k *Key) VerificationKey() interface{} {
+	return k.public
+}
+
+// KeySet набор ключей: ключ подписи и все ключи, токены которых еще принимаются
+type KeySet struct {
+	algorithm string
+	dir       string
+
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	signing *Key
+}
+
+// Load загружает ключи согласно конфигурации.
+// Для HS256 используется секрет, для RS256/EdDSA - закрытые ключи из каталога.
+func Load(cfg config.JWTConfig) (*KeySet, error) {
+	s := &KeySet{
+		algorithm: cfg.Algorithm,
+		dir:       cfg.KeysDir,
+	}
+
+	if cfg.Algorithm == config.JWTAlgorithmHS256 {
+		key := &Key{
+			ID:        hmacKeyID,
+			Algorithm: config.JWTAlgorithmHS256,
+			private:   []byte(cfg.Secret),
+			public:    []byte(cfg.Secret),
+		}
+		s.keys = map[string]*Key{key.ID: key}
+		s.signing = key
+		return s, nil
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Asymmetric сообщает, используются ли асимметричные ключи
+func (s *KeySet) Asymmetric() bool {
+	return s.algorithm != config.JWTAlgorithmHS256
+}
+
+// Reload перечитывает каталог ключей. Для HS256 ничего не делает.
+func (s *KeySet) Reload() error {
+	if !s.Asymmetric() {
+		return nil
+	}
+
+	keys, err := readKeys(s.dir)
+	if err != nil {
+		return err
+	}
+
+	signing := selectSigningKey(keys, s.algorithm, time.Now())
+	if signing == nil {
+		return ErrNoSigningKey
+	}
+
+	byID := make(map[string]*Key, len(keys))
+	for _, key := range keys {
+		byID[key.ID] = key
+	}
+
+	s.mu.Lock()
+	s.keys = byID
+	s.signing = signing
+	s.mu.Unlock()
+
+	return nil
+}
+
+// selectSigningKey выбирает самый новый активированный ключ алгоритма algorithm.
+// Если активированных ключей нет (первый запуск), используется самый новый.
+func selectSigningKey(keys []*Key, algorithm string, now time.Time) *Key {
+	var newest, active *Key
+	for _, key := range keys {
+		if key.Algorithm != algorithm {
+			continue
+		}
+		newest = key
+		if !key.CreatedAt.After(now.Add(-ActivationDelay)) {
+			active = key
+		}
+	}
+	if active != nil {
+		return active
+	}
+	return newest
+}
+
+// Signing возвращает текущий ключ подписи
+func (s *KeySet) Signing() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.signing
+}
+
+// Lookup возвращает ключ проверки по идентификатору
+func (s *KeySet) Lookup(kid string) (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Токены HS256, выданные до появления kid, не содержат его
+	if kid == "" && !s.Asymmetric() {
+		return s.signing, true
+	}
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// Keys возвращает все ключи, от старых к новым
+func (s *KeySet) Keys() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sortKeys(keys)
+	return keys
+}
+
+// Generate создает новый ключ алгоритма algorithm в каталоге dir.
+// Ключ сразу публикуется в JWKS и становится ключом подписи через ActivationDelay.
+func Generate(dir, algorithm string) (*Key, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case config.JWTAlgorithmRS256:
+		key, err := rsa.GenerateKey(randReader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case config.JWTAlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(randReader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("алгоритм %s не использует ключи", algorithm)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога ключей: %w", err)
+	}
+
+	now := time.Now().UTC()
+	id := now.Format(keyIDLayout)
+	path := filepath.Join(dir, id+".pem")
+
+	// O_EXCL: не перезаписываем ключ, созданный в ту же секунду
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка записи ключа: %w", err)
+	}
+	defer f.Close()
+
+	if err := pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		return nil, fmt.Errorf("ошибка записи ключа: %w", err)
+	}
+
+	return &Key{
+		ID:        id,
+		Algorithm: algorithm,
+		CreatedAt: now,
+		private:   private,
+		public:    private.Public(),
+	}, nil
+}
+
+// Prune удаляет ключи, замененные более новыми раньше, чем maxTokenAge назад:
+// все подписанные ими токены уже истекли. Самый новый ключ не удаляется.
+func Prune(dir string, maxTokenAge time.Duration) ([]string, error) {
+	keys, err := readKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for i := 0; i < len(keys)-1; i++ {
+		// Ключ перестает подписывать, когда активируется следующий
+		supersededAt := keys[i+1].CreatedAt.Add(ActivationDelay)
+		if time.Since(supersededAt) <= maxTokenAge {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(dir, keys[i].ID+".pem")); err != nil {
+			return removed, fmt.Errorf("ошибка удаления ключа %s: %w", keys[i].ID, err)
+		}
+		removed = append(removed, keys[i].ID)
+	}
+
+	return removed, nil
+}
+
+// List возвращает ключи каталога dir, от старых к новым
+func List(dir string) ([]*Key, error) {
+	keys, err := readKeys(dir)
+	if errors.Is(err, ErrNoSigningKey) {
+		return nil, nil
+	}
+	return keys, err
+}
+
+// readKeys читает все ключи каталога, от старых к новым
+func readKeys(dir string) ([]*Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoSigningKey
+		}
+		return nil, fmt.Errorf("ошибка чтения каталога ключей: %w", err)
+	}
+
+	var keys []*Key
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pem") {
+			continue
+		}
+
+		key, err := readKey(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	sortKeys(keys)
+	return keys, nil
+}
+
+// readKey читает закрытый ключ PKCS#8 из PEM файла
+func readKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения ключа %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("ключ %s: ожидается PEM", path)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("ключ %s: %w", path, err)
+	}
+
+	id := strings.TrimSuffix(filepath.Base(path), ".pem")
+	createdAt, err := time.Parse(keyIDLayout, id)
+	if err != nil {
+		return nil, fmt.Errorf("ключ %s: имя файла должно иметь вид %s.pem", path, keyIDLayout)
+	}
+
+	key := &Key{ID: id, CreatedAt: createdAt}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = config.JWTAlgorithmRS256
+		key.private, key.public = private, &private.PublicKey
+	case ed25519.PrivateKey:
+		key.Algorithm = config.JWTAlgorithmEdDSA
+		key.private, key.public = private, private.Public()
+	default:
+		return nil, fmt.Errorf("ключ %s: неподдерживаемый тип %T", path, parsed)
+	}
+
+	return key, nil
+}
+
+// sortKeys упорядочивает ключи от старых к новым
+func sortKeys(keys []*Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+}
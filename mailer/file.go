@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// unsafeFileChars заменяются в имени файла письма
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+// FileMailer сохраняет письма в каталог в формате .eml (для локального тестирования)
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer создает новый FileMailer
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+// Send сохраняет письмо в отдельный файл
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("ошибка создания каталога писем: %w", err)
+	}
+
+	name := fmt.Sprintf("%s_%s.eml",
+		time.Now().Format("20060102T150405.000000000"),
+		unsafeFileChars.ReplaceAllString(msg.To, "_"))
+
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, buildMessage(m.from, msg), 0o600); err != nil {
+		return fmt.Errorf("ошибка записи письма: %w", err)
+	}
+
+	return nil
+}
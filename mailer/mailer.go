@@ -0,0 +1,50 @@
+// Package mailer отвечает за отправку писем пользователям
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"api-go/config"
+)
+
+// Message представляет письмо
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer отправляет письма
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New создает Mailer по настройкам конфигурации
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case config.MailDriverLog, "":
+		return NewLogMailer(), nil
+	case config.MailDriverFile:
+		return NewFileMailer(cfg.Dir, cfg.From), nil
+	case config.MailDriverSMTP:
+		return NewSMTPMailer(cfg), nil
+	default:
+		return nil, fmt.Errorf("неизвестный способ отправки писем: %s", cfg.Driver)
+	}
+}
+
+// LogMailer выводит письма в лог вместо отправки (для локальной разработки)
+type LogMailer struct{}
+
+// NewLogMailer создает новый LogMailer
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send записывает письмо в лог
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("Письмо для %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -1,69 +1,270 @@
 package middleware
 
 import (
+	"crypto/subtle"
+	"database/sql"
+	"log"
 	"net/http"
 	"strings"
 
+	"api-go/apierror"
 	"api-go/config"
+	"api-go/jwtkeys"
+	"api-go/models"
 	"api-go/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
-// AuthMiddleware проверяет JWT токен в заголовке Authorization
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+// AuthMiddleware проверяет JWT токен в заголовке Authorization или API ключ в заголовке X-API-Key
+// и актуальное состояние пользователя в базе (активность, отзыв сессий, роль и разрешения)
+func AuthMiddleware(keys *jwtkeys.KeySet, db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Интеграции аутентифицируются API ключом
+		if apiKey := c.GetHeader(models.APIKeyHeader); apiKey != "" {
+			authenticateAPIKey(c, db, apiKey)
+			return
+		}
+
 		// Получаем заголовок Authorization
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Заголовок Authorization отсутствует"})
-			c.Abort()
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeMissingCredentials)
 			return
 		}
 
 		// Проверяем формат "Bearer <token>"
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный формат токена. Используйте: Bearer <token>"})
-			c.Abort()
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidAuthorizationHeader)
 			return
 		}
 
 		tokenString := tokenParts[1]
 
 		// Валидируем токен
-		claims, err := utils.ValidateToken(tokenString, cfg.JWT.Secret)
+		claims, err := utils.ValidateToken(tokenString, keys)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Недействительный токен: " + err.Error()})
-			c.Abort()
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidToken)
+			return
+		}
+
+		// Проверяем, что пользователь активен и токен не отозван
+		// Разрешения определяются по текущей роли, поэтому изменения ролей действуют сразу
+		var role string
+		var isActive, mustChangePassword, twoFactorEnabled bool
+		var tokenVersion int
+		var permissions []string
+		err = db.QueryRow(`
+			SELECT u.role, COALESCE(u.is_active, true), u.token_version, u.must_change_password, u.totp_enabled,
+			       ARRAY(
+			           SELECT p.name FROM role_permissions rp
+			           JOIN roles r ON r.id = rp.role_id
+			           JOIN permissions p ON p.id = rp.permission_id
+			           WHERE r.name = u.role
+			       )
+			FROM users u WHERE u.id = $1`,
+			claims.UserID,
+		).Scan(&role, &isActive, &tokenVersion, &mustChangePassword, &twoFactorEnabled, pq.Array(&permissions))
+		if err != nil {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUserNotFound)
+			return
+		}
+
+		if !isActive {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeAccountDisabled)
+			return
+		}
+
+		if tokenVersion != claims.TokenVersion {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeSessionRevoked)
 			return
 		}
 
 		// Сохраняем информацию о пользователе в контексте
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		c.Set("role", role)
+		c.Set("must_change_password", mustChangePassword)
+		c.Set("two_factor_enabled", twoFactorEnabled)
+		c.Set("permissions", permissions)
+
+		c.Next()
+	}
+}
+
+// authenticateAPIKey проверяет API ключ. Запрос выполняется от имени владельца ключа
+// с разрешениями ключа, которые есть и у текущей роли владельца.
+func authenticateAPIKey(c *gin.Context, db *sql.DB, apiKey string) {
+	prefix, ok := utils.APIKeyPrefix(apiKey)
+	if !ok {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidAPIKey)
+		return
+	}
+
+	var keyID, userID int
+	var keyHash, username, role string
+	var isActive, twoFactorEnabled bool
+	var keyPermissions, rolePermissions []string
+	err := db.QueryRow(`
+		SELECT k.id, k.key_hash, k.permissions, u.id, u.username, u.role, COALESCE(u.is_active, true), u.totp_enabled,
+		       ARRAY(
+		           SELECT p.name FROM role_permissions rp
+		           JOIN roles r ON r.id = rp.role_id
+		           JOIN permissions p ON p.id = rp.permission_id
+		           WHERE r.name = u.role
+		       )
+		FROM api_keys k JOIN users u ON u.id = k.user_id
+		WHERE k.prefix = $1 AND k.revoked_at IS NULL
+		  AND (k.expires_at IS NULL OR k.expires_at > CURRENT_TIMESTAMP)`,
+		prefix,
+	).Scan(&keyID, &keyHash, pq.Array(&keyPermissions), &userID, &username, &role, &isActive, &twoFactorEnabled, pq.Array(&rolePermissions))
+	if err != nil || subtle.ConstantTimeCompare([]byte(keyHash), []byte(utils.HashToken(apiKey))) != 1 {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidAPIKey)
+		return
+	}
+
+	if !isActive {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeAPIKeyOwnerDisabled)
+		return
+	}
+
+	permissions := make([]string, 0, len(keyPermissions))
+	for _, p := range keyPermissions {
+		for _, granted := range rolePermissions {
+			if p == granted {
+				permissions = append(permissions, p)
+				break
+			}
+		}
+	}
+
+	// Время использования обновляем не чаще раза в минуту, чтобы не писать в БД на каждый запрос
+	_, err = db.Exec(`
+		UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP, last_used_ip = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')`,
+		keyID, c.ClientIP())
+	if err != nil {
+		log.Printf("Ошибка обновления времени использования API ключа %d: %v", keyID, err)
+	}
+
+	c.Set("user_id", userID)
+	c.Set("username", username)
+	c.Set("role", role)
+	c.Set("must_change_password", false)
+	c.Set("two_factor_enabled", twoFactorEnabled)
+	c.Set("permissions", permissions)
+	c.Set("api_key_id", keyID)
+
+	c.Next()
+}
+
+// RejectAPIKey запрещает доступ по API ключу: учетной записью управляет только сам пользователь
+func RejectAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isAPIKey := c.Get("api_key_id"); isAPIKey {
+			apierror.Respond(c, http.StatusForbidden, apierror.CodeAPIKeyNotAllowed)
+			return
+		}
 
 		c.Next()
 	}
 }
 
-// AdminMiddleware проверяет, что пользователь имеет роль администратора
-func AdminMiddleware() gin.HandlerFunc {
+// RequirePermission проверяет, что роль пользователя имеет указанное разрешение
+func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Информация о пользователе не найдена"})
-			c.Abort()
+		if !HasPermission(c, permission) {
+			apierror.Respond(c, http.StatusForbidden, apierror.CodePermissionRequired, permission)
 			return
 		}
 
-		if role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен. Требуются права администратора"})
-			c.Abort()
+		c.Next()
+	}
+}
+
+// HasPermission сообщает, есть ли у аутентифицированного пользователя разрешение
+func HasPermission(c *gin.Context, permission string) bool {
+	value, exists := c.Get("permissions")
+	if !exists {
+		return false
+	}
+
+	permissions, _ := value.([]string)
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// PasswordChangeGuard запрещает доступ, пока пользователь не сменит временный пароль
+func PasswordChangeGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if mustChange, _ := c.Get("must_change_password"); mustChange == true {
+			apierror.Respond(c, http.StatusForbidden, apierror.CodePasswordChangeRequired)
 			return
 		}
 
 		c.Next()
 	}
 }
+
+// TwoFactorGuard запрещает доступ без включенной 2FA, если это требуется конфигурацией,
+// пользователям с привилегиями: разрешениями сверх разрешений роли покупателя (user),
+// в какой бы роли они ни были
+func TwoFactorGuard(cfg *config.Config, db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.TwoFactor.RequireForAdmin {
+			c.Next()
+			return
+		}
+
+		if enabled, _ := c.Get("two_factor_enabled"); enabled == true {
+			c.Next()
+			return
+		}
+
+		value, _ := c.Get("permissions")
+		permissions, _ := value.([]string)
+		if len(permissions) == 0 {
+			c.Next()
+			return
+		}
+
+		var basePermissions []string
+		err := db.QueryRow(`
+			SELECT ARRAY(
+				SELECT p.name FROM roles r
+				JOIN role_permissions rp ON rp.role_id = r.id
+				JOIN permissions p ON p.id = rp.permission_id
+				WHERE r.name = $1
+			)`, models.RoleUser,
+		).Scan(pq.Array(&basePermissions))
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+
+		for _, permission := range permissions {
+			if !containsPermission(basePermissions, permission) {
+				apierror.Respond(c, http.StatusForbidden, apierror.CodeTwoFactorRequiredForAdmins)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// containsPermission сообщает, есть ли разрешение в списке
+func containsPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -1,22 +1,91 @@
 package middleware
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"api-go/config"
+
 	"github.com/gin-gonic/gin"
 )
 
-// CORS добавляет заголовки CORS для кросс-доменных запросов
-func CORS() gin.HandlerFunc {
-	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+// CORS добавляет заголовки CORS по политике из конфигурации.
+// Origin запроса возвращается в Access-Control-Allow-Origin, только если он разрешен.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	allowAnyOrigin := false
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			allowAnyOrigin = true
+		}
+	}
+
+	allowMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(cfg.MaxAge)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if origin != "" {
+			// Ответ зависит от Origin, иначе кэш отдаст его другому сайту
+			c.Writer.Header().Add("Vary", "Origin")
+			if preflight {
+				c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+				c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			}
+		}
+
+		if origin != "" && (allowAnyOrigin || originAllowed(cfg.AllowedOrigins, origin)) {
+			if allowAnyOrigin && !cfg.AllowCredentials {
+				c.Header("Access-Control-Allow-Origin", "*")
+			} else {
+				c.Header("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+			if preflight {
+				c.Header("Access-Control-Allow-Methods", allowMethods)
+				c.Header("Access-Control-Allow-Headers", allowHeaders)
+				c.Header("Access-Control-Max-Age", maxAge)
+			} else if exposeHeaders != "" {
+				c.Header("Access-Control-Expose-Headers", exposeHeaders)
+			}
+		}
+
+		// Preflight от неразрешенного Origin тоже получает пустой ответ:
+		// без заголовков CORS браузер сам заблокирует запрос
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
 		c.Next()
-	})
+	}
+}
+
+// originAllowed проверяет Origin по списку точных адресов и шаблонов вида https://*.example.com
+func originAllowed(allowed []string, origin string) bool {
+	for _, pattern := range allowed {
+		if strings.EqualFold(pattern, origin) {
+			return true
+		}
+
+		prefix, suffix, ok := strings.Cut(pattern, "*.")
+		if !ok {
+			continue
+		}
+		// Шаблон покрывает только поддомены: https://*.example.com не разрешает https://example.com
+		// и https://evil-example.com
+		if len(origin) > len(prefix)+len(suffix)+1 &&
+			strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+			strings.HasSuffix(strings.ToLower(origin), "."+strings.ToLower(suffix)) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,40 @@
+package middleware
+
+import "testing"
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := []string{
+		"https://shop.example.org",
+		"http://localhost:3000",
+		"https://*.example.com",
+	}
+
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{name: "точное совпадение", origin: "https://shop.example.org", want: true},
+		{name: "регистр не учитывается", origin: "HTTPS://Shop.Example.org", want: true},
+		{name: "другой порт", origin: "http://localhost:3001", want: false},
+		{name: "другая схема", origin: "http://shop.example.org", want: false},
+		{name: "поддомен по шаблону", origin: "https://api.example.com", want: true},
+		{name: "вложенный поддомен по шаблону", origin: "https://a.b.example.com", want: true},
+		{name: "поддомен по шаблону в другом регистре", origin: "https://API.Example.COM", want: true},
+		{name: "шаблон не покрывает сам домен", origin: "https://example.com", want: false},
+		{name: "пустой поддомен", origin: "https://.example.com", want: false},
+		{name: "домен с тем же окончанием", origin: "https://evil-example.com", want: false},
+		{name: "шаблон в середине чужого домена", origin: "https://api.example.com.evil.net", want: false},
+		{name: "поддомен по шаблону с другой схемой", origin: "http://api.example.com", want: false},
+		{name: "поддомен по шаблону с портом", origin: "https://api.example.com:8443", want: false},
+		{name: "неизвестный origin", origin: "https://evil.net", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := originAllowed(allowed, tt.origin); got != tt.want {
+				t.Errorf("originAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
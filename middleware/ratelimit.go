@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"api-go/apierror"
+	"api-go/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRateLimitBodySize максимальный размер тела, читаемого для ключа по email
+const maxRateLimitBodySize = 1 << 20
+
+// RateLimitKeyFunc возвращает ключ, по которому считаются запросы.
+// Пустой ключ означает, что запрос не ограничивается этой политикой.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitByIP считает запросы по IP адресу клиента
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByUserID считает запросы по ID пользователя (после AuthMiddleware),
+// для анонимных запросов - по IP адресу
+func RateLimitByUserID(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitByEmail считает запросы по полю email в JSON теле запроса.
+// Тело восстанавливается для обработчика.
+func RateLimitByEmail(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRateLimitBodySize))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+
+	email := strings.ToLower(strings.TrimSpace(payload.Email))
+	if email == "" {
+		return ""
+	}
+	return "email:" + email
+}
+
+// RateLimit ограничивает частоту запросов по политике и добавляет заголовки
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset и Retry-After (при отказе).
+// При ошибке хранилища запрос пропускается.
+func RateLimit(store ratelimit.Store, policy ratelimit.Policy, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		result, err := store.Allow(c.Request.Context(), policy, key)
+		if err != nil {
+			log.Printf("Ошибка проверки лимита запросов %s: %v", policy.Name, err)
+			c.Next()
+			return
+		}
+
+		resetSeconds := int(math.Ceil(result.Reset.Seconds()))
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(resetSeconds))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds())))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(resetSeconds))
+			apierror.Respond(c, http.StatusTooManyRequests, apierror.CodeRateLimited)
+			return
+		}
+
+		c.Next()
+	}
+}
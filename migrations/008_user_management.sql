@@ -0,0 +1,42 @@
+-- Миграция 008: Управление пользователями
+-- Дата: 2026-10-18
+-- Описание: Активность пользователя, принудительная смена пароля и отзыв сессий
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+-- Флаг активности (есть в 001, но мог отсутствовать в старых базах)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN DEFAULT true;
+
+-- Версия токенов: увеличение делает недействительными все выданные JWT пользователя
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
+
+-- Требование сменить пароль при следующем входе
+ALTER TABLE users ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT false;
+
+-- Время последнего входа
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP;
+
+UPDATE users SET is_active = true WHERE is_active IS NULL;
+
+COMMENT ON COLUMN users.token_version IS 'Версия JWT токенов пользователя, увеличивается при отзыве сессий';
+COMMENT ON COLUMN users.must_change_password IS 'Пользователь должен сменить пароль';
+
+-- Отключаем администраторов с публично известным паролем из начальных данных (001).
+-- Для восстановления доступа используйте: api-go create-admin --email <email> --password <пароль>
+UPDATE users SET is_active = false, token_version = token_version + 1
+WHERE role = 'admin' AND password = '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi';
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+CREATE INDEX IF NOT EXISTS idx_users_is_active ON users(is_active);
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DROP INDEX IF EXISTS idx_users_is_active;
+-- DROP INDEX IF EXISTS idx_users_role;
+-- ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
+-- ALTER TABLE users DROP COLUMN IF EXISTS must_change_password;
+-- ALTER TABLE users DROP COLUMN IF EXISTS token_version;
@@ -0,0 +1,41 @@
+-- Миграция 009: Профиль пользователя и одноразовые токены
+-- Дата: 2026-10-18
+-- Описание: Поля профиля, ожидающая подтверждения смена email, таблица одноразовых токенов
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+-- Поля профиля (есть в 001, но last_name не добавлялся миграцией 002)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS first_name VARCHAR(50);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_name VARCHAR(50);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(20);
+
+-- Новый email, ожидающий подтверждения
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email VARCHAR(100);
+
+-- Одноразовые токены (смена email, сброс пароля, подтверждение email).
+-- Хранится только SHA-256 хеш токена.
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    data TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE user_tokens IS 'Одноразовые токены пользователей, хранятся в виде хеша';
+COMMENT ON COLUMN user_tokens.data IS 'Дополнительные данные токена (например, новый email)';
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_purpose ON user_tokens(user_id, purpose);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_expires_at ON user_tokens(expires_at);
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DROP TABLE IF EXISTS user_tokens;
+-- ALTER TABLE users DROP COLUMN IF EXISTS pending_email;
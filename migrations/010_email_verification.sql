@@ -0,0 +1,18 @@
+-- Миграция 010: Подтверждение email
+-- Дата: 2026-10-18
+-- Описание: Время подтверждения email пользователем (сброс пароля и подтверждение email используют user_tokens)
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+-- NULL означает, что email еще не подтвержден
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+COMMENT ON COLUMN users.email_verified_at IS 'Время подтверждения email, NULL - не подтвержден';
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
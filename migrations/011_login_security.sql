@@ -0,0 +1,39 @@
+-- Миграция 011: Защита входа
+-- Дата: 2026-10-18
+-- Описание: Учет неудачных попыток входа, временная блокировка и журнал входов
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+
+COMMENT ON COLUMN users.failed_login_attempts IS 'Число неудачных попыток входа подряд';
+COMMENT ON COLUMN users.locked_until IS 'До этого времени вход запрещен';
+
+-- Журнал попыток входа
+CREATE TABLE IF NOT EXISTS login_events (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(100) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    outcome VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE login_events IS 'Попытки входа: IP, user-agent и результат';
+COMMENT ON COLUMN login_events.user_id IS 'NULL, если пользователь с таким email не найден';
+COMMENT ON COLUMN login_events.outcome IS 'success, invalid_password, unknown_email, locked, disabled';
+
+CREATE INDEX IF NOT EXISTS idx_login_events_user_created ON login_events(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_events_ip_created ON login_events(ip_address, created_at DESC);
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DROP TABLE IF EXISTS login_events;
+-- ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
+-- ALTER TABLE users DROP COLUMN IF EXISTS failed_login_attempts;
@@ -0,0 +1,39 @@
+-- Миграция 012: Двухфакторная аутентификация (TOTP)
+-- Дата: 2026-10-18
+-- Описание: Секрет TOTP пользователя и коды восстановления
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+-- Секрет сохраняется при настройке, а totp_enabled включается после проверки первого кода
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_counter BIGINT;
+
+COMMENT ON COLUMN users.totp_secret IS 'Секрет TOTP в base32';
+COMMENT ON COLUMN users.totp_last_counter IS 'Период последнего принятого кода, защищает от повторного использования';
+
+-- Коды восстановления, хранится только SHA-256 хеш
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE user_recovery_codes IS 'Одноразовые коды восстановления доступа при потере устройства 2FA';
+
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+
+COMMENT ON COLUMN login_events.outcome IS 'success, invalid_password, unknown_email, locked, disabled, 2fa_required, invalid_2fa_code';
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DROP TABLE IF EXISTS user_recovery_codes;
+-- ALTER TABLE users DROP COLUMN IF EXISTS totp_last_counter;
+-- ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled;
+-- ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
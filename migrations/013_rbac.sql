@@ -0,0 +1,86 @@
+-- Миграция 013: Роли и разрешения
+-- Дата: 2026-10-18
+-- Описание: Таблицы ролей и разрешений вместо жестко заданной роли admin
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(20) UNIQUE NOT NULL,
+    description TEXT,
+    is_system BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE roles IS 'Роли пользователей; users.role ссылается на roles.name';
+COMMENT ON COLUMN roles.is_system IS 'Системные роли нельзя удалить';
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    description TEXT
+);
+
+COMMENT ON TABLE permissions IS 'Разрешения вида ресурс:действие';
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+-- Встроенные роли
+INSERT INTO roles (name, description, is_system) VALUES
+('user', 'Покупатель', true),
+('admin', 'Администратор, все разрешения', true),
+('warehouse', 'Сотрудник склада: просмотр заказов и изменение их статуса', false)
+ON CONFLICT (name) DO NOTHING;
+
+-- Роли, уже назначенные пользователям
+UPDATE users SET role = 'user' WHERE role IS NULL;
+INSERT INTO roles (name) SELECT DISTINCT role FROM users ON CONFLICT (name) DO NOTHING;
+
+-- Разрешения
+INSERT INTO permissions (name, description) VALUES
+('products:create', 'Создание продуктов'),
+('products:update', 'Изменение продуктов'),
+('products:delete', 'Удаление продуктов'),
+('orders:read_all', 'Просмотр всех заказов'),
+('orders:update', 'Изменение заказов и их статуса'),
+('users:read', 'Просмотр пользователей'),
+('users:manage', 'Управление пользователями: роли, блокировка, сброс пароля и 2FA'),
+('roles:manage', 'Управление ролями и их разрешениями'),
+('cache:manage', 'Сброс кэша')
+ON CONFLICT (name) DO NOTHING;
+
+-- Администратор получает все разрешения
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN ('orders:read_all', 'orders:update')
+WHERE r.name = 'warehouse'
+ON CONFLICT DO NOTHING;
+
+-- Роль пользователя должна существовать
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_users_role') THEN
+        ALTER TABLE users ADD CONSTRAINT fk_users_role
+            FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+    END IF;
+END $$;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+-- DROP TABLE IF EXISTS role_permissions;
+-- DROP TABLE IF EXISTS permissions;
+-- DROP TABLE IF EXISTS roles;
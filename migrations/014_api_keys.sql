@@ -0,0 +1,43 @@
+-- Миграция 014: API ключи
+-- Дата: 2026-10-18
+-- Описание: Ключи для интеграций сервер-сервер (ERP, склад) вместо входа под пользователем
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) UNIQUE NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE api_keys IS 'API ключи; хранится только SHA-256 хеш, prefix открыто идентифицирует ключ';
+COMMENT ON COLUMN api_keys.user_id IS 'Владелец ключа: запросы выполняются от его имени';
+COMMENT ON COLUMN api_keys.permissions IS 'Разрешения ключа; действуют только те, что есть и у роли владельца';
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+
+INSERT INTO permissions (name, description) VALUES
+('api_keys:manage', 'Выпуск и отзыв API ключей')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'api_keys:manage'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DELETE FROM permissions WHERE name = 'api_keys:manage';
+-- DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,41 @@
+-- Миграция 015: Вход через OpenID Connect
+-- Дата: 2026-10-18
+-- Описание: Привязка учетных записей к внешним провайдерам и незавершенные попытки входа
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+COMMENT ON TABLE user_identities IS 'Учетные записи пользователей у провайдеров OIDC (provider + sub из ID токена)';
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+-- Состояние входа между перенаправлением к провайдеру и возвратом от него
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE oidc_login_states IS 'Одноразовые state для OIDC; хранится хеш state, PKCE verifier и nonce';
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DROP TABLE IF EXISTS oidc_login_states;
+-- DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,45 @@
+-- Миграция 016: Журнал действий администраторов
+-- Дата: 2026-10-18
+-- Описание: Кто, когда и что изменил в продуктах и заказах; запись делается в транзакции изменения
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    actor_username VARCHAR(50) NOT NULL DEFAULT '',
+    api_key_id INTEGER REFERENCES api_keys(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    ip_address VARCHAR(45),
+    request_id VARCHAR(128),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE audit_log IS 'Журнал изменений, сделанных администраторами и интеграциями';
+COMMENT ON COLUMN audit_log.actor_username IS 'Имя на момент действия: сохраняется после удаления пользователя';
+COMMENT ON COLUMN audit_log.changes IS 'Измененные поля: {"поле": {"before": ..., "after": ...}}';
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at DESC);
+
+INSERT INTO permissions (name, description) VALUES
+('audit:read', 'Просмотр и выгрузка журнала действий')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'audit:read'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DELETE FROM permissions WHERE name = 'audit:read';
+-- DROP TABLE IF EXISTS audit_log;
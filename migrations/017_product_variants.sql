@@ -0,0 +1,52 @@
+-- Миграция 017: Варианты продуктов
+-- Дата: 2026-10-18
+-- Описание: Варианты (размер, цвет и др.) со своим SKU, ценой и остатком; корзина и заказы ссылаются на вариант
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku VARCHAR(100) UNIQUE NOT NULL,
+    price DECIMAL(10,2),
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    attributes JSONB NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE product_variants IS 'Варианты продукта: отдельные SKU с собственным остатком';
+COMMENT ON COLUMN product_variants.price IS 'Цена варианта; NULL - цена продукта';
+COMMENT ON COLUMN product_variants.attributes IS 'Значения атрибутов варианта: {"size": "M", "color": "Черный"}';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_attributes ON product_variants(product_id, attributes);
+
+DROP TRIGGER IF EXISTS update_product_variants_updated_at ON product_variants;
+CREATE TRIGGER update_product_variants_updated_at BEFORE UPDATE ON product_variants FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Корзина: один и тот же продукт может лежать в корзине в разных вариантах
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS variant_id INTEGER REFERENCES product_variants(id) ON DELETE CASCADE;
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS cart_items_user_id_product_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_items_user_product_variant ON cart_items(user_id, product_id, COALESCE(variant_id, 0));
+
+-- Заказы: SKU и атрибуты варианта сохраняются на момент заказа
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_id INTEGER REFERENCES product_variants(id) ON DELETE SET NULL;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_sku VARCHAR(100);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_attributes JSONB;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- ALTER TABLE order_items DROP COLUMN IF EXISTS variant_attributes;
+-- ALTER TABLE order_items DROP COLUMN IF EXISTS variant_sku;
+-- ALTER TABLE order_items DROP COLUMN IF EXISTS variant_id;
+-- DROP INDEX IF EXISTS idx_cart_items_user_product_variant;
+-- DELETE FROM cart_items WHERE variant_id IS NOT NULL;
+-- ALTER TABLE cart_items DROP COLUMN IF EXISTS variant_id;
+-- ALTER TABLE cart_items ADD CONSTRAINT cart_items_user_id_product_id_key UNIQUE (user_id, product_id);
+-- DROP TABLE IF EXISTS product_variants;
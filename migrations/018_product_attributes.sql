@@ -0,0 +1,65 @@
+-- Миграция 018: Характеристики продуктов
+-- Дата: 2026-10-18
+-- Описание: Типизированные характеристики (бренд, материал, диагональ и др.), их набор для каждой категории
+-- и значения у продуктов; используются для фильтрации и фасетов в списке продуктов
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('string', 'number', 'boolean')),
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    is_filterable BOOLEAN NOT NULL DEFAULT true,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN attribute_definitions.code IS 'Код характеристики в фильтрах: attr.<code>=значение';
+
+CREATE TABLE IF NOT EXISTS category_attributes (
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES attribute_definitions(id) ON DELETE CASCADE,
+    PRIMARY KEY (category_id, attribute_id)
+);
+
+CREATE TABLE IF NOT EXISTS product_attribute_values (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES attribute_definitions(id) ON DELETE CASCADE,
+    value_text VARCHAR(255),
+    value_number DECIMAL(14,4),
+    value_boolean BOOLEAN,
+    PRIMARY KEY (product_id, attribute_id),
+    CHECK (num_nonnulls(value_text, value_number, value_boolean) = 1)
+);
+
+COMMENT ON TABLE product_attribute_values IS 'Значения характеристик: заполнена колонка, соответствующая типу характеристики';
+
+CREATE INDEX IF NOT EXISTS idx_category_attributes_attribute_id ON category_attributes(attribute_id);
+CREATE INDEX IF NOT EXISTS idx_product_attribute_values_text ON product_attribute_values(attribute_id, value_text);
+CREATE INDEX IF NOT EXISTS idx_product_attribute_values_number ON product_attribute_values(attribute_id, value_number);
+
+DROP TRIGGER IF EXISTS update_attribute_definitions_updated_at ON attribute_definitions;
+CREATE TRIGGER update_attribute_definitions_updated_at BEFORE UPDATE ON attribute_definitions FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO permissions (name, description) VALUES
+('attributes:manage', 'Управление характеристиками продуктов и их набором в категориях')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'attributes:manage'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DELETE FROM permissions WHERE name = 'attributes:manage';
+-- DROP TABLE IF EXISTS product_attribute_values;
+-- DROP TABLE IF EXISTS category_attributes;
+-- DROP TABLE IF EXISTS attribute_definitions;
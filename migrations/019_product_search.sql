@@ -0,0 +1,31 @@
+-- Миграция 019: Полнотекстовый поиск продуктов
+-- Дата: 2026-10-18
+-- Описание: Поисковый вектор по названию, описанию и SKU (русская и английская морфология) с GIN индексом;
+-- триграммный индекс по названию для поиска с опечатками
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(sku, '')), 'A') ||
+    setweight(to_tsvector('russian', COALESCE(description, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+
+COMMENT ON COLUMN products.search_vector IS 'Поисковый вектор: название и SKU с весом A, описание с весом B';
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DROP INDEX IF EXISTS idx_products_name_trgm;
+-- DROP INDEX IF EXISTS idx_products_search_vector;
+-- ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
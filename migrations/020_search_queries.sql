@@ -0,0 +1,41 @@
+-- Миграция 020: Статистика поисковых запросов
+-- Дата: 2026-10-18
+-- Описание: Частота поисковых запросов для подсказок и запросы без результатов для мерчандайзеров;
+-- триграммный индекс по названию категорий для подсказок
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS search_queries (
+    query VARCHAR(200) PRIMARY KEY,
+    search_count INTEGER NOT NULL DEFAULT 0,
+    zero_result_count INTEGER NOT NULL DEFAULT 0,
+    last_result_count INTEGER NOT NULL DEFAULT 0,
+    first_searched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_searched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN search_queries.query IS 'Запрос в нижнем регистре с одиночными пробелами';
+COMMENT ON COLUMN search_queries.zero_result_count IS 'Сколько раз запрос не нашел ни одного продукта';
+
+CREATE INDEX IF NOT EXISTS idx_search_queries_prefix ON search_queries(query text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_search_queries_zero_results ON search_queries(zero_result_count DESC) WHERE last_result_count = 0;
+CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING GIN (name gin_trgm_ops);
+
+INSERT INTO permissions (name, description) VALUES
+('search:read', 'Просмотр статистики поисковых запросов')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'search:read'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DELETE FROM permissions WHERE name = 'search:read';
+-- DROP INDEX IF EXISTS idx_categories_name_trgm;
+-- DROP TABLE IF EXISTS search_queries;
@@ -0,0 +1,34 @@
+-- Миграция 021: Галерея изображений продуктов
+-- Дата: 2026-10-18
+-- Описание: Несколько изображений у продукта с порядком, подписью (alt) и миниатюрами;
+-- файлы лежат в хранилище (локальный каталог или S3), в таблице - их ключи
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS product_images (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    storage_key VARCHAR(255) UNIQUE NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    thumbnails JSONB NOT NULL DEFAULT '{}',
+    alt_text VARCHAR(255) NOT NULL DEFAULT '',
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE product_images IS 'Изображения продукта; первое по sort_order - основное, его адрес копируется в products.image_url';
+COMMENT ON COLUMN product_images.storage_key IS 'Ключ оригинала в хранилище: products/{product_id}/{случайное имя}.jpg';
+COMMENT ON COLUMN product_images.thumbnails IS 'Ключи миниатюр по размерам: {"small": "...", "medium": "..."}; размеры больше оригинала не создаются';
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON product_images(product_id, sort_order);
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DROP TABLE IF EXISTS product_images;
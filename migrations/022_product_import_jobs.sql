@@ -0,0 +1,50 @@
+-- Миграция 022: Импорт и выгрузка продуктов
+-- Дата: 2026-10-18
+-- Описание: Задачи импорта продуктов из CSV и JSON Lines с их прогрессом и ошибками по строкам;
+-- разрешения на импорт и выгрузку каталога
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS product_import_jobs (
+    id SERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'jsonl')),
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    created_count INTEGER NOT NULL DEFAULT 0,
+    updated_count INTEGER NOT NULL DEFAULT 0,
+    unchanged_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    errors JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+COMMENT ON TABLE product_import_jobs IS 'Задачи импорта продуктов; большие файлы обрабатываются в фоне';
+COMMENT ON COLUMN product_import_jobs.errors IS 'Первые строки с ошибками: [{"row": 14, "sku": "...", "errors": [...]}]';
+
+CREATE INDEX IF NOT EXISTS idx_product_import_jobs_created_at ON product_import_jobs(created_at DESC);
+
+INSERT INTO permissions (name, description) VALUES
+('products:import', 'Импорт продуктов из файла'),
+('products:export', 'Выгрузка каталога продуктов')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN ('products:import', 'products:export')
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DELETE FROM permissions WHERE name IN ('products:import', 'products:export');
+-- DROP TABLE IF EXISTS product_import_jobs;
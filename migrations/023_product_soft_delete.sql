@@ -0,0 +1,21 @@
+-- Миграция 023: Мягкое удаление продуктов
+-- Дата: 2026-10-18
+-- Описание: Удаленный продукт помечается deleted_at и скрывается из каталога, но остается
+-- в заказах и отзывах и может быть восстановлен; из базы его удаляет команда products purge
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+COMMENT ON COLUMN products.deleted_at IS 'Время удаления в корзину; NULL - продукт не удален';
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DROP INDEX IF EXISTS idx_products_deleted_at;
+-- ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
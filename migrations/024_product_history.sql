@@ -0,0 +1,51 @@
+-- Миграция 024: История изменений и цен продуктов
+-- Дата: 2026-10-18
+-- Описание: Ревизии с полным состоянием продукта до каждого изменения (для просмотра и отката)
+-- и периоды действия цен (какая цена была у продукта в заданный момент)
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS product_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('update', 'import', 'rollback')),
+    data JSONB NOT NULL,
+    changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    changed_by_username VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, revision)
+);
+
+COMMENT ON TABLE product_revisions IS 'Ревизии продукта: состояние до изменения; номер ревизии растет с 1 для каждого продукта';
+COMMENT ON COLUMN product_revisions.data IS 'Все поля продукта до изменения (JSON модели Product)';
+COMMENT ON COLUMN product_revisions.changed_fields IS 'Поля, которые изменились при переходе от этой ревизии к следующему состоянию';
+
+CREATE TABLE IF NOT EXISTS price_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price DECIMAL(10,2) NOT NULL,
+    valid_from TIMESTAMP NOT NULL,
+    valid_to TIMESTAMP
+);
+
+COMMENT ON TABLE price_history IS 'Периоды действия цен продукта [valid_from, valid_to); у текущей цены valid_to IS NULL';
+
+CREATE INDEX IF NOT EXISTS idx_price_history_product_id ON price_history(product_id, valid_from);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_history_current ON price_history(product_id) WHERE valid_to IS NULL;
+
+-- Текущие цены: цена не менялась как минимум с последнего изменения продукта
+INSERT INTO price_history (product_id, price, valid_from)
+SELECT p.id, p.price, COALESCE(p.updated_at, p.created_at, CURRENT_TIMESTAMP)
+FROM products p
+WHERE NOT EXISTS (SELECT 1 FROM price_history ph WHERE ph.product_id = p.id);
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DROP TABLE IF EXISTS price_history;
+-- DROP TABLE IF EXISTS product_revisions;
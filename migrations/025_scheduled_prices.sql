@@ -0,0 +1,69 @@
+-- Миграция 025: Запланированные цены и акции
+-- Дата: 2026-10-18
+-- Описание: Цены продуктов на заданный период и скидки в процентах на категорию;
+-- действующая цена с учетом акций хранится в products.sale_price и пересчитывается планировщиком
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sale_price DECIMAL(10,2);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sale_ends_at TIMESTAMP;
+
+COMMENT ON COLUMN products.sale_price IS 'Действующая цена по акции (ниже price); NULL - акции нет. Пересчитывается планировщиком';
+COMMENT ON COLUMN products.sale_ends_at IS 'Окончание действующей акции; NULL - акции нет или она бессрочная';
+
+CREATE TABLE IF NOT EXISTS product_sale_prices (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sale_price DECIMAL(10,2) NOT NULL CHECK (sale_price > 0),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+COMMENT ON TABLE product_sale_prices IS 'Запланированные цены продукта на период [starts_at, ends_at); при пересечении действует наименьшая';
+
+CREATE INDEX IF NOT EXISTS idx_product_sale_prices_product_id ON product_sale_prices(product_id, starts_at);
+
+CREATE TABLE IF NOT EXISTS sale_campaigns (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    discount_percent DECIMAL(5,2) NOT NULL CHECK (discount_percent > 0 AND discount_percent < 100),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+COMMENT ON TABLE sale_campaigns IS 'Акции: скидка в процентах на все продукты категории в период [starts_at, ends_at)';
+
+CREATE INDEX IF NOT EXISTS idx_sale_campaigns_category_id ON sale_campaigns(category_id);
+
+DROP TRIGGER IF EXISTS update_sale_campaigns_updated_at ON sale_campaigns;
+CREATE TRIGGER update_sale_campaigns_updated_at BEFORE UPDATE ON sale_campaigns FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO permissions (name, description) VALUES
+('campaigns:manage', 'Управление акциями на категории')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'campaigns:manage'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DELETE FROM permissions WHERE name = 'campaigns:manage';
+-- DROP TABLE IF EXISTS sale_campaigns;
+-- DROP TABLE IF EXISTS product_sale_prices;
+-- ALTER TABLE products DROP COLUMN IF EXISTS sale_ends_at;
+-- ALTER TABLE products DROP COLUMN IF EXISTS sale_price;
@@ -0,0 +1,52 @@
+-- Миграция 026: Валюты и курсы
+-- Дата: 2026-10-18
+-- Описание: Курсы валют к базовой валюте (RUB), валюта цены продукта, корзины и истории цен,
+-- валюта заказа и курс, по которому он оформлен
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency CHAR(3) PRIMARY KEY CHECK (currency ~ '^[A-Z]{3}$'),
+    rate DECIMAL(18,8) NOT NULL CHECK (rate > 0),
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE exchange_rates IS 'Курсы валют: rate - стоимость единицы валюты в базовой валюте RUB (у RUB всегда 1)';
+
+INSERT INTO exchange_rates (currency, rate) VALUES ('RUB', 1)
+ON CONFLICT (currency) DO NOTHING;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB' REFERENCES exchange_rates(currency);
+ALTER TABLE price_history ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS exchange_rate DECIMAL(18,8) NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN products.currency IS 'Валюта цены продукта, его вариантов и запланированных цен';
+COMMENT ON COLUMN cart_items.currency IS 'Валюта цены позиции (валюта продукта на момент добавления)';
+COMMENT ON COLUMN orders.currency IS 'Валюта заказа: в ней цены позиций и суммы';
+COMMENT ON COLUMN orders.exchange_rate IS 'Курс валюты заказа к базовой валюте на момент оформления';
+
+INSERT INTO permissions (name, description) VALUES
+('currencies:manage', 'Управление курсами валют')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'currencies:manage'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DELETE FROM permissions WHERE name = 'currencies:manage';
+-- ALTER TABLE orders DROP COLUMN IF EXISTS exchange_rate;
+-- ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+-- ALTER TABLE cart_items DROP COLUMN IF EXISTS currency;
+-- ALTER TABLE price_history DROP COLUMN IF EXISTS currency;
+-- ALTER TABLE products DROP COLUMN IF EXISTS currency;
+-- DROP TABLE IF EXISTS exchange_rates;
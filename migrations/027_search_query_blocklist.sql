@@ -0,0 +1,31 @@
+-- Миграция 027: Блокировка поисковых запросов в подсказках
+-- Дата: 2026-10-18
+-- Описание: Запросы, заблокированные администратором, не показываются в подсказках поиска,
+-- сколько бы раз их ни искали
+
+-- ========================================
+-- UP MIGRATION (применение изменений)
+-- ========================================
+
+ALTER TABLE search_queries ADD COLUMN IF NOT EXISTS is_blocked BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN search_queries.is_blocked IS 'Запрос заблокирован администратором и не показывается в подсказках';
+
+CREATE INDEX IF NOT EXISTS idx_search_queries_blocked ON search_queries(query) WHERE is_blocked = true;
+
+INSERT INTO permissions (name, description) VALUES
+('search:manage', 'Блокировка поисковых запросов в подсказках')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'search:manage'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- ========================================
+-- DOWN MIGRATION (откат изменений)
+-- ========================================
+
+-- DELETE FROM permissions WHERE name = 'search:manage';
+-- DROP INDEX IF EXISTS idx_search_queries_blocked;
+-- ALTER TABLE search_queries DROP COLUMN IF EXISTS is_blocked;
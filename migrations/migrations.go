@@ -0,0 +1,9 @@
+// Package migrations содержит SQL миграции базы данных, встроенные в бинарный файл
+package migrations
+
+import "embed"
+
+// FS содержит все файлы миграций *.sql
+//
+//go:embed *.sql
+var FS embed.FS
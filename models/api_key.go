@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// APIKeyHeader заголовок, в котором передается API ключ
+const APIKeyHeader = "X-API-Key"
+
+// APIKey представляет API ключ для интеграций сервер-сервер (без самого ключа)
+type APIKey struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name" example:"ERP"`
+	Prefix      string     `json:"prefix" example:"ak_1f2e3d4c"`
+	UserID      int        `json:"user_id"`
+	Username    string     `json:"username"`
+	Permissions []string   `json:"permissions" example:"products:create,products:update"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP  *string    `json:"last_used_ip,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// APIKeyCreateRequest запрос на выпуск API ключа
+type APIKeyCreateRequest struct {
+	Name          string   `json:"name" binding:"required,max=100" example:"ERP"`
+	Permissions   []string `json:"permissions" binding:"required,min=1" example:"products:create,products:update"`
+	UserID        *int     `json:"user_id"`                                            // Владелец ключа (без разрешений сверх ваших); по умолчанию администратор, выпускающий ключ
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=3650"` // Без срока, если не указано
+}
+
+// APIKeyCreateResponse ответ на выпуск API ключа. Ключ показывается только один раз.
+type APIKeyCreateResponse struct {
+	APIKey
+	Key string `json:"key" example:"ak_1f2e3d4c_3q2-7wEpZk..."`
+}
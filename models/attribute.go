@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// Типы характеристик (attribute_definitions.type)
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+)
+
+// AttributeDefinition характеристика продуктов (бренд, материал, диагональ и др.)
+type AttributeDefinition struct {
+	ID           int       `json:"id" example:"1"`
+	Code         string    `json:"code" example:"screen_size"`
+	Name         string    `json:"name" example:"Диагональ экрана"`
+	Type         string    `json:"type" example:"number" enums:"string,number,boolean"`
+	Unit         string    `json:"unit,omitempty" example:"дюйм"`
+	IsFilterable bool      `json:"is_filterable" example:"true"`
+	SortOrder    int       `json:"sort_order" example:"0"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// AttributeCreateRequest запрос на создание характеристики
+type AttributeCreateRequest struct {
+	Code         string `json:"code" binding:"required,max=50" example:"screen_size"`
+	Name         string `json:"name" binding:"required,max=100" example:"Диагональ экрана"`
+	Type         string `json:"type" binding:"required,oneof=string number boolean" example:"number"`
+	Unit         string `json:"unit" binding:"max=20" example:"дюйм"`
+	IsFilterable *bool  `json:"is_filterable" example:"true"` // По умолчанию true
+	SortOrder    int    `json:"sort_order" example:"0"`
+}
+
+// AttributeUpdateRequest запрос на изменение характеристики. Код и тип не меняются
+type AttributeUpdateRequest struct {
+	Name         *string `json:"name" binding:"omitempty,min=1,max=100" example:"Диагональ"`
+	Unit         *string `json:"unit" binding:"omitempty,max=20" example:"дюйм"`
+	IsFilterable *bool   `json:"is_filterable" example:"true"`
+	SortOrder    *int    `json:"sort_order" example:"1"`
+}
+
+// CategoryAttributesRequest набор характеристик категории. Заменяет текущий набор целиком
+type CategoryAttributesRequest struct {
+	AttributeIDs []int `json:"attribute_ids" binding:"required" example:"1,2"`
+}
+
+// ProductAttributesRequest значения характеристик продукта по коду. Заменяет текущие значения целиком
+type ProductAttributesRequest struct {
+	Values map[string]interface{} `json:"values" binding:"required" swaggertype:"object"`
+}
+
+// ProductAttributeValue значение характеристики продукта
+type ProductAttributeValue struct {
+	Code  string      `json:"code" example:"screen_size"`
+	Name  string      `json:"name" example:"Диагональ экрана"`
+	Type  string      `json:"type" example:"number"`
+	Unit  string      `json:"unit,omitempty" example:"дюйм"`
+	Value interface{} `json:"value" swaggertype:"string" example:"6.1"`
+}
+
+// ProductFacets фасеты списка продуктов: количество продуктов по значениям характеристик и диапазонам цен.
+// Для каждой характеристики учитываются все фильтры, кроме фильтра по ней самой
+type ProductFacets struct {
+	Attributes []AttributeFacet `json:"attributes"`
+	Price      []PriceBucket    `json:"price"`
+}
+
+// AttributeFacet фасет характеристики: значения с количеством продуктов,
+// для числовых характеристик также минимум и максимум
+type AttributeFacet struct {
+	Code   string       `json:"code" example:"brand"`
+	Name   string       `json:"name" example:"Бренд"`
+	Type   string       `json:"type" example:"string"`
+	Unit   string       `json:"unit,omitempty"`
+	Values []FacetValue `json:"values"`
+	Min    *float64     `json:"min,omitempty"`
+	Max    *float64     `json:"max,omitempty"`
+}
+
+// FacetValue значение характеристики и количество продуктов с ним
+type FacetValue struct {
+	Value string `json:"value" example:"Apple"`
+	Count int    `json:"count" example:"12"`
+}
+
+// PriceBucket диапазон цен [From, To) и количество продуктов в нем
+type PriceBucket struct {
+	From  *float64 `json:"from,omitempty" example:"1000"`
+	To    *float64 `json:"to,omitempty" example:"5000"`
+	Count int      `json:"count" example:"8"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Действия журнала (audit_log.action)
+const (
+	AuditProductCreate   = "product.create"
+	AuditProductUpdate   = "product.update"
+	AuditProductDelete   = "product.delete"
+	AuditProductRestore  = "product.restore"
+	AuditProductPurge    = "product.purge"
+	AuditProductRollback = "product.rollback"
+	AuditSalePriceCreate = "sale_price.create"
+	AuditSalePriceDelete = "sale_price.delete"
+	AuditCampaignCreate  = "campaign.create"
+	AuditCampaignUpdate  = "campaign.update"
+	AuditCampaignDelete  = "campaign.delete"
+	AuditVariantCreate   = "variant.create"
+	AuditVariantUpdate   = "variant.update"
+	AuditVariantDelete   = "variant.delete"
+	AuditImageCreate     = "image.create"
+	AuditImageUpdate     = "image.update"
+	AuditImageDelete     = "image.delete"
+	AuditOrderUpdate     = "order.update"
+)
+
+// Типы объектов журнала (audit_log.entity_type)
+const (
+	AuditEntityProduct   = "product"
+	AuditEntityVariant   = "product_variant"
+	AuditEntityImage     = "product_image"
+	AuditEntitySalePrice = "product_sale_price"
+	AuditEntityCampaign  = "sale_campaign"
+	AuditEntityOrder     = "order"
+)
+
+// AuditChange значение поля до и после изменения; null - поля не было (создание, удаление)
+type AuditChange struct {
+	Before interface{} `json:"before" swaggertype:"object"`
+	After  interface{} `json:"after" swaggertype:"object"`
+}
+
+// AuditEntry запись журнала действий
+type AuditEntry struct {
+	ID            int64                  `json:"id"`
+	ActorID       *int                   `json:"actor_id,omitempty"`
+	ActorUsername string                 `json:"actor_username" example:"admin"`
+	APIKeyID      *int                   `json:"api_key_id,omitempty"`
+	Action        string                 `json:"action" example:"product.update"`
+	EntityType    string                 `json:"entity_type" example:"product"`
+	EntityID      int                    `json:"entity_id" example:"42"`
+	Changes       map[string]AuditChange `json:"changes"`
+	IPAddress     string                 `json:"ip_address"`
+	RequestID     string                 `json:"request_id"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+// AuditLogResponse страница журнала действий
+type AuditLogResponse struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   int          `json:"total"`
+	Page    int          `json:"page"`
+	Limit   int          `json:"limit"`
+}
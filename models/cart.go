@@ -9,16 +9,19 @@ type CartItem struct {
 	ID        int       `json:"id" db:"id"`
 	UserID    int       `json:"user_id" db:"user_id"`
 	ProductID int       `json:"product_id" db:"product_id"`
+	VariantID *int      `json:"variant_id" db:"variant_id"`
 	Quantity  int       `json:"quantity" db:"quantity"`
 	Price     float64   `json:"price" db:"price"`
+	Currency  string    `json:"currency" db:"currency"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // CartItemRequest запрос на добавление товара в корзину
 type CartItemRequest struct {
-	ProductID int `json:"product_id" binding:"required"`
-	Quantity  int `json:"quantity" binding:"required,min=1"`
+	ProductID int  `json:"product_id" binding:"required"`
+	VariantID *int `json:"variant_id"` // Обязателен, если у продукта есть варианты
+	Quantity  int  `json:"quantity" binding:"required,min=1"`
 }
 
 // CartItemUpdateRequest запрос на обновление товара в корзине
@@ -32,9 +35,12 @@ type CartItemResponse struct {
 	UserID    int             `json:"user_id"`
 	ProductID int             `json:"product_id"`
 	Product   ProductResponse `json:"product"`
+	VariantID *int            `json:"variant_id,omitempty"`
+	Variant   *ProductVariant `json:"variant,omitempty"`
 	Quantity  int             `json:"quantity"`
 	Price     float64         `json:"price"`
 	Total     float64         `json:"total"`
+	Currency  string          `json:"currency" example:"RUB"` // В корзине - запрошенная валюта, иначе валюта позиции
 	CreatedAt time.Time       `json:"created_at"`
 	UpdatedAt time.Time       `json:"updated_at"`
 }
@@ -45,4 +51,5 @@ type CartResponse struct {
 	TotalItems int                `json:"total_items"`
 	TotalPrice float64            `json:"total_price"`
 	ItemCount  int                `json:"item_count"`
+	Currency   string             `json:"currency" example:"RUB"` // Валюта цен и суммы (currency, Accept-Currency или базовая)
 }
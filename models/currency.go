@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// BaseCurrency базовая валюта: курсы валют (exchange_rates.rate) задаются в ней, у нее курс всегда 1
+const BaseCurrency = "RUB"
+
+// ExchangeRate курс валюты к базовой валюте
+type ExchangeRate struct {
+	Currency  string    `json:"currency" example:"USD"`
+	Rate      float64   `json:"rate" example:"92.5"` // Стоимость единицы валюты в базовой валюте
+	UpdatedBy *int      `json:"updated_by,omitempty" example:"1"`
+	UpdatedAt time.Time `json:"updated_at" example:"2026-10-18T09:00:00Z"`
+}
+
+// ExchangeRateUpdateRequest запрос на установку курса валюты
+type ExchangeRateUpdateRequest struct {
+	Rate float64 `json:"rate" binding:"required,gt=0" example:"92.5"`
+}
+
+// ExchangeRatesImportRequest курсы нескольких валют по коду ISO 4217; отсутствующие валюты не меняются
+type ExchangeRatesImportRequest struct {
+	Rates map[string]float64 `json:"rates" binding:"required,min=1,dive,gt=0" example:"USD:92.5,EUR:100.1"`
+}
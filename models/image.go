@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ProductImage изображение продукта с миниатюрами
+type ProductImage struct {
+	ID          int               `json:"id" example:"3"`
+	ProductID   int               `json:"product_id" example:"1"`
+	URL         string            `json:"url" example:"http://localhost:8080/media/products/1/5f2b9c0e7a41d3c8.jpg"`
+	Thumbnails  map[string]string `json:"thumbnails"` // Адреса по размерам small, medium, large; для маленьких изображений - адрес оригинала
+	ContentType string            `json:"content_type" example:"image/jpeg"`
+	Width       int               `json:"width" example:"2400"`
+	Height      int               `json:"height" example:"1600"`
+	SizeBytes   int               `json:"size_bytes" example:"734512"`
+	AltText     string            `json:"alt_text" example:"iPhone 15 Pro, вид сзади"`
+	SortOrder   int               `json:"sort_order" example:"0"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ProductImageUpdateRequest запрос на изменение подписи изображения
+type ProductImageUpdateRequest struct {
+	AltText string `json:"alt_text" binding:"max=255" example:"iPhone 15 Pro, вид сбоку"`
+}
+
+// ProductImageOrderRequest новый порядок изображений продукта; первое становится основным
+type ProductImageOrderRequest struct {
+	ImageIDs []int `json:"image_ids" binding:"required,min=1" example:"5,3,4"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// OIDCProviderInfo провайдер OpenID Connect, доступный для входа
+type OIDCProviderInfo struct {
+	Name     string `json:"name" example:"google"`
+	LoginURL string `json:"login_url" example:"/api/v1/auth/oidc/google/login"`
+}
+
+// OIDCProvidersResponse список провайдеров OpenID Connect
+type OIDCProvidersResponse struct {
+	Providers []OIDCProviderInfo `json:"providers"`
+}
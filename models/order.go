@@ -30,6 +30,8 @@ type Order struct {
 	PaymentMethod   string      `json:"payment_method" db:"payment_method"`
 	PaymentStatus   string      `json:"payment_status" db:"payment_status"`
 	Notes           string      `json:"notes" db:"notes"`
+	Currency        string      `json:"currency" db:"currency"`
+	ExchangeRate    float64     `json:"exchange_rate" db:"exchange_rate"`
 	CreatedAt       time.Time   `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time   `json:"updated_at" db:"updated_at"`
 }
@@ -39,6 +41,7 @@ type OrderItem struct {
 	ID        int     `json:"id" db:"id"`
 	OrderID   int     `json:"order_id" db:"order_id"`
 	ProductID int     `json:"product_id" db:"product_id"`
+	VariantID *int    `json:"variant_id" db:"variant_id"`
 	Quantity  int     `json:"quantity" db:"quantity"`
 	Price     float64 `json:"price" db:"price"`
 	Discount  float64 `json:"discount" db:"discount"`
@@ -51,13 +54,15 @@ type OrderCreateRequest struct {
 	ShippingAddress string             `json:"shipping_address" binding:"required"`
 	BillingAddress  string             `json:"billing_address" binding:"required"`
 	PaymentMethod   string             `json:"payment_method" binding:"required"`
+	Currency        string             `json:"currency" binding:"omitempty,len=3" example:"USD"` // Без нее - currency, Accept-Currency или базовая валюта
 	Notes           string             `json:"notes"`
 }
 
 // OrderItemRequest запрос на добавление товара в заказ
 type OrderItemRequest struct {
-	ProductID int `json:"product_id" binding:"required"`
-	Quantity  int `json:"quantity" binding:"required,min=1"`
+	ProductID int  `json:"product_id" binding:"required"`
+	VariantID *int `json:"variant_id"` // Обязателен, если у продукта есть варианты
+	Quantity  int  `json:"quantity" binding:"required,min=1"`
 }
 
 // OrderUpdateRequest запрос на обновление заказа
@@ -83,6 +88,8 @@ type OrderResponse struct {
 	PaymentStatus   string              `json:"payment_status"`
 	Notes           string              `json:"notes"`
 	Items           []OrderItemResponse `json:"items"`
+	Currency        string              `json:"currency" example:"USD"`       // Валюта цен и сумм заказа
+	ExchangeRate    float64             `json:"exchange_rate" example:"92.5"` // Курс валюты заказа к базовой на момент оформления
 	CreatedAt       time.Time           `json:"created_at"`
 	UpdatedAt       time.Time           `json:"updated_at"`
 }
@@ -97,6 +104,11 @@ type OrderItemResponse struct {
 	Price     float64         `json:"price"`
 	Discount  float64         `json:"discount"`
 	Total     float64         `json:"total"`
+
+	// Вариант на момент заказа: SKU и атрибуты сохраняются, даже если вариант изменят или удалят
+	VariantID         *int              `json:"variant_id,omitempty"`
+	VariantSKU        string            `json:"variant_sku,omitempty"`
+	VariantAttributes map[string]string `json:"variant_attributes,omitempty"`
 }
 
 // OrderListResponse ответ со списком заказов
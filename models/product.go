@@ -6,22 +6,36 @@ import (
 
 // Product представляет продукт в системе
 type Product struct {
-	ID          int       `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name" binding:"required"`
-	Description string    `json:"description" db:"description"`
-	Price       float64   `json:"price" db:"price" binding:"required,gt=0"`
-	CategoryID  *int      `json:"category_id" db:"category_id"`
-	Stock       int       `json:"stock" db:"stock" binding:"gte=0"`
-	StockType   string    `json:"stock_type" db:"stock_type"`
-	ImageURL    string    `json:"image_url" db:"image_url"`
-	SKU         string    `json:"sku" db:"sku"`
-	Color       string    `json:"color" db:"color"`
-	Size        string    `json:"size" db:"size"`
-	IsActive    bool      `json:"is_active" db:"is_active"`
-	IsFeatured  bool      `json:"is_featured" db:"is_featured"`
-	SortOrder   int       `json:"sort_order" db:"sort_order"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID          int        `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name" binding:"required"`
+	Description string     `json:"description" db:"description"`
+	Price       float64    `json:"price" db:"price" binding:"required,gt=0"`
+	Currency    string     `json:"currency" db:"currency"`
+	CategoryID  *int       `json:"category_id" db:"category_id"`
+	Stock       int        `json:"stock" db:"stock" binding:"gte=0"`
+	StockType   string     `json:"stock_type" db:"stock_type"`
+	ImageURL    string     `json:"image_url" db:"image_url"`
+	SKU         string     `json:"sku" db:"sku"`
+	Color       string     `json:"color" db:"color"`
+	Size        string     `json:"size" db:"size"`
+	IsActive    bool       `json:"is_active" db:"is_active"`
+	IsFeatured  bool       `json:"is_featured" db:"is_featured"`
+	SortOrder   int        `json:"sort_order" db:"sort_order"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Действующая цена по акции; пересчитывается планировщиком цен и не входит в ревизии и аудит
+	SalePrice  *float64   `json:"-" db:"sale_price"`
+	SaleEndsAt *time.Time `json:"-" db:"sale_ends_at"`
+}
+
+// EffectivePrice цена продукта с учетом действующей акции
+func (p *Product) EffectivePrice() float64 {
+	if p.SalePrice != nil {
+		return *p.SalePrice
+	}
+	return p.Price
 }
 
 // ProductCreateRequest представляет запрос на создание продукта
@@ -29,6 +43,7 @@ type ProductCreateRequest struct {
 	Name        string  `json:"name" binding:"required" example:"iPhone 15 Pro"`
 	Description string  `json:"description" example:"Смартфон Apple с чипом A17 Pro"`
 	Price       float64 `json:"price" binding:"required,gt=0" example:"999.99"`
+	Currency    string  `json:"currency" binding:"omitempty,len=3" example:"RUB"` // По умолчанию базовая валюта
 	CategoryID  *int    `json:"category_id" example:"1"`
 	Stock       int     `json:"stock" binding:"gte=0" example:"50"`
 	StockType   string  `json:"stock_type" example:"piece"`
@@ -46,6 +61,7 @@ type ProductUpdateRequest struct {
 	Name        *string  `json:"name" example:"iPhone 15 Pro Updated"`
 	Description *string  `json:"description" example:"Обновленное описание продукта"`
 	Price       *float64 `json:"price" binding:"omitempty,gt=0" example:"899.99"`
+	Currency    *string  `json:"currency" binding:"omitempty,len=3" example:"RUB"`
 	CategoryID  *int     `json:"category_id" example:"1"`
 	Stock       *int     `json:"stock" binding:"omitempty,gte=0" example:"45"`
 	StockType   *string  `json:"stock_type" example:"piece"`
@@ -60,23 +76,43 @@ type ProductUpdateRequest struct {
 
 // ProductResponse представляет ответ с продуктом
 type ProductResponse struct {
-	ID           int       `json:"id" example:"1"`
-	Name         string    `json:"name" example:"iPhone 15 Pro"`
-	Description  string    `json:"description" example:"Смартфон Apple с чипом A17 Pro"`
-	Price        float64   `json:"price" example:"999.99"`
-	CategoryID   *int      `json:"category_id" example:"1"`
-	CategorySlug string    `json:"category_slug,omitempty" example:"smartphones"`
-	Stock        int       `json:"stock" example:"50"`
-	StockType    string    `json:"stock_type" example:"piece"`
-	ImageURL     string    `json:"image_url" example:"https://example.com/iphone15.jpg"`
-	SKU          string    `json:"sku" example:"IPHONE15-PRO"`
-	Color        string    `json:"color" example:"Titanium"`
-	Size         string    `json:"size" example:"6.1 inch"`
-	IsActive     bool      `json:"is_active" example:"true"`
-	IsFeatured   bool      `json:"is_featured" example:"true"`
-	SortOrder    int       `json:"sort_order" example:"1"`
-	CreatedAt    time.Time `json:"created_at" example:"2025-08-15T10:00:00Z"`
-	UpdatedAt    time.Time `json:"updated_at" example:"2025-08-15T10:00:00Z"`
+	ID             int        `json:"id" example:"1"`
+	Name           string     `json:"name" example:"iPhone 15 Pro"`
+	Description    string     `json:"description" example:"Смартфон Apple с чипом A17 Pro"`
+	Price          float64    `json:"price" example:"999.99"`                                // Базовая цена
+	EffectivePrice float64    `json:"effective_price" example:"899.99"`                      // Цена с учетом действующей акции
+	SaleEndsAt     *time.Time `json:"sale_ends_at,omitempty" example:"2025-09-01T00:00:00Z"` // Окончание акции, если она ограничена по времени
+	Currency       string     `json:"currency" example:"RUB"`                                // Валюта цен; в каталоге - запрошенная (currency, Accept-Currency)
+	CategoryID     *int       `json:"category_id" example:"1"`
+	CategorySlug   string     `json:"category_slug,omitempty" example:"smartphones"`
+	Stock          int        `json:"stock" example:"50"`
+	StockType      string     `json:"stock_type" example:"piece"`
+	ImageURL       string     `json:"image_url" example:"https://example.com/iphone15.jpg"`
+	SKU            string     `json:"sku" example:"IPHONE15-PRO"`
+	Color          string     `json:"color" example:"Titanium"`
+	Size           string     `json:"size" example:"6.1 inch"`
+	IsActive       bool       `json:"is_active" example:"true"`
+	IsFeatured     bool       `json:"is_featured" example:"true"`
+	SortOrder      int        `json:"sort_order" example:"1"`
+	CreatedAt      time.Time  `json:"created_at" example:"2025-08-15T10:00:00Z"`
+	UpdatedAt      time.Time  `json:"updated_at" example:"2025-08-15T10:00:00Z"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" example:"2025-09-01T12:00:00Z"` // Только в корзине удаленных продуктов
+
+	// Матрица вариантов, характеристики и галерея: возвращаются в карточке продукта (GET /products/{id})
+	Options    []VariantOption         `json:"options,omitempty"`
+	Variants   []ProductVariant        `json:"variants,omitempty"`
+	Attributes []ProductAttributeValue `json:"attributes,omitempty"`
+	Images     []ProductImage          `json:"images,omitempty"`
+
+	// Подсветка найденных слов: возвращается в списке продуктов при поиске (search)
+	Highlight *ProductHighlight `json:"highlight,omitempty"`
+}
+
+// ProductHighlight название и фрагменты описания, в которых найденные слова выделены тегом <mark>.
+// Остальной текст экранирован для HTML.
+type ProductHighlight struct {
+	Name        string `json:"name" example:"<mark>iPhone</mark> 15 Pro"`
+	Description string `json:"description" example:"Новейший смартфон Apple … <mark>iPhone</mark> …"`
 }
 
 // ProductListResponse представляет ответ со списком продуктов
@@ -85,4 +121,5 @@ type ProductListResponse struct {
 	Total    int               `json:"total" example:"100"`
 	Page     int               `json:"page" example:"1"`
 	Limit    int               `json:"limit" example:"10"`
+	Facets   *ProductFacets    `json:"facets,omitempty"` // Только при facets=true
 }
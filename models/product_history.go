@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Действия, после которых сохраняется ревизия продукта (product_revisions.action)
+const (
+	RevisionActionUpdate   = "update"
+	RevisionActionImport   = "import"
+	RevisionActionRollback = "rollback"
+)
+
+// ProductRevision состояние продукта до изменения
+type ProductRevision struct {
+	Revision          int       `json:"revision" example:"3"`                                   // Номер ревизии продукта, начиная с 1
+	Action            string    `json:"action" example:"update" enums:"update,import,rollback"` // Чем было вызвано изменение
+	Product           Product   `json:"product"`                                                // Все поля продукта до изменения
+	ChangedFields     []string  `json:"changed_fields" example:"price,stock"`                   // Поля, измененные после этой ревизии
+	ChangedBy         *int      `json:"changed_by,omitempty" example:"1"`                       // Кто изменил продукт
+	ChangedByUsername string    `json:"changed_by_username,omitempty" example:"admin"`          // Имя на момент изменения
+	CreatedAt         time.Time `json:"created_at" example:"2026-10-13T09:30:00Z"`              // Когда продукт изменили
+}
+
+// PricePeriod период действия цены [valid_from, valid_to)
+type PricePeriod struct {
+	Price     float64    `json:"price" example:"999.99"`
+	Currency  string     `json:"currency" example:"RUB"`
+	ValidFrom time.Time  `json:"valid_from" example:"2026-10-01T00:00:00Z"`
+	ValidTo   *time.Time `json:"valid_to,omitempty" example:"2026-10-13T09:30:00Z"` // Нет у текущей цены
+}
+
+// ProductHistoryResponse история изменений и цен продукта
+type ProductHistoryResponse struct {
+	Revisions  []ProductRevision `json:"revisions"`          // Новые ревизии первыми
+	Total      int               `json:"total" example:"12"` // Всего ревизий
+	Page       int               `json:"page" example:"1"`
+	Limit      int               `json:"limit" example:"20"`
+	Prices     []PricePeriod     `json:"prices"`                               // Периоды цен, новые первыми
+	PriceAt    *float64          `json:"price_at,omitempty" example:"1099.99"` // Цена в момент at, если он указан и известен
+	CurrencyAt string            `json:"currency_at,omitempty" example:"RUB"`  // Валюта цены price_at
+}
+
+// ProductRollbackRequest запрос на откат продукта к ревизии
+type ProductRollbackRequest struct {
+	Revision int `json:"revision" binding:"required,gt=0" example:"3"`
+}
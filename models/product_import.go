@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Форматы файлов импорта и выгрузки продуктов
+const (
+	FileFormatCSV   = "csv"
+	FileFormatJSONL = "jsonl"
+)
+
+// Статусы задачи импорта
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// ProductImportRow строка файла импорта и выгрузки продуктов; продукт ищется по SKU.
+// Отсутствующее поле (пустая ячейка CSV, null или нет ключа в JSONL) при обновлении не меняется,
+// при создании принимает значение по умолчанию. Категория задается category_id или category_slug.
+type ProductImportRow struct {
+	SKU          string   `json:"sku" binding:"required,max=100" example:"IPHONE15-PRO"`
+	Name         *string  `json:"name" binding:"omitempty,min=1,max=200" example:"iPhone 15 Pro"`
+	Description  *string  `json:"description" example:"Смартфон Apple с чипом A17 Pro"`
+	Price        *float64 `json:"price" binding:"omitempty,gt=0" example:"999.99"`
+	Currency     *string  `json:"currency" binding:"omitempty,len=3" example:"RUB"`
+	CategoryID   *int     `json:"category_id" example:"1"`
+	CategorySlug *string  `json:"category_slug" binding:"omitempty,min=1" example:"smartphones"`
+	Stock        *int     `json:"stock" binding:"omitempty,gte=0" example:"50"`
+	StockType    *string  `json:"stock_type" binding:"omitempty,min=1,max=50" example:"piece"`
+	ImageURL     *string  `json:"image_url" binding:"omitempty,max=255" example:"https://example.com/iphone15.jpg"`
+	Color        *string  `json:"color" binding:"omitempty,max=50" example:"Titanium"`
+	Size         *string  `json:"size" binding:"omitempty,max=50" example:"6.1 inch"`
+	IsActive     *bool    `json:"is_active" example:"true"`
+	IsFeatured   *bool    `json:"is_featured" example:"false"`
+	SortOrder    *int     `json:"sort_order" example:"1"`
+}
+
+// ProductImportJob задача импорта продуктов и ее результат
+type ProductImportJob struct {
+	ID            int                     `json:"id" example:"12"`
+	Status        string                  `json:"status" example:"completed" enums:"pending,running,completed,failed"`
+	Format        string                  `json:"format" example:"csv" enums:"csv,jsonl"`
+	DryRun        bool                    `json:"dry_run" example:"false"` // Только проверка, без изменений в каталоге
+	FileName      string                  `json:"file_name" example:"catalog.csv"`
+	TotalRows     int                     `json:"total_rows" example:"1500"`
+	ProcessedRows int                     `json:"processed_rows" example:"1500"`
+	Created       int                     `json:"created" example:"120"`  // При dry_run - сколько было бы создано
+	Updated       int                     `json:"updated" example:"1370"` // При dry_run - сколько было бы изменено
+	Unchanged     int                     `json:"unchanged" example:"8"`  // Строки, совпадающие с каталогом
+	Failed        int                     `json:"failed" example:"2"`     // Строки с ошибками, они пропущены
+	Errors        []ProductImportRowError `json:"errors"`                 // Не больше 1000 первых строк с ошибками
+	Error         string                  `json:"error,omitempty"`        // Причина статуса failed
+	CreatedBy     *int                    `json:"created_by,omitempty" example:"1"`
+	CreatedAt     time.Time               `json:"created_at"`
+	StartedAt     *time.Time              `json:"started_at,omitempty"`
+	FinishedAt    *time.Time              `json:"finished_at,omitempty"`
+}
+
+// ProductImportRowError ошибки строки файла импорта
+type ProductImportRowError struct {
+	Row    int                `json:"row" example:"14"` // Номер строки файла, начиная с 1; у CSV первая строка - заголовок
+	SKU    string             `json:"sku,omitempty" example:"IPHONE15-PRO"`
+	Errors []ImportFieldError `json:"errors"`
+}
+
+// ImportFieldError ошибка поля строки импорта
+type ImportFieldError struct {
+	Field   string `json:"field" example:"price"`
+	Rule    string `json:"rule" example:"gt"`
+	Message string `json:"message" example:"Значение больше 0"`
+}
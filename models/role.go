@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Разрешения (permissions.name)
+const (
+	PermProductsCreate   = "products:create"
+	PermProductsUpdate   = "products:update"
+	PermProductsDelete   = "products:delete"
+	PermOrdersReadAll    = "orders:read_all"
+	PermOrdersUpdate     = "orders:update"
+	PermUsersRead        = "users:read"
+	PermUsersManage      = "users:manage"
+	PermRolesManage      = "roles:manage"
+	PermCacheManage      = "cache:manage"
+	PermAPIKeysManage    = "api_keys:manage"
+	PermAuditRead        = "audit:read"
+	PermAttributesManage = "attributes:manage"
+	PermSearchRead       = "search:read"
+	PermSearchManage     = "search:manage"
+	PermProductsImport   = "products:import"
+	PermProductsExport   = "products:export"
+	PermCampaignsManage  = "campaigns:manage"
+	PermCurrenciesManage = "currencies:manage"
+)
+
+// Role представляет роль с набором разрешений
+type Role struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name" example:"warehouse"`
+	Description string    `json:"description"`
+	IsSystem    bool      `json:"is_system"`
+	Permissions []string  `json:"permissions" example:"orders:read_all,orders:update"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Permission представляет разрешение
+type Permission struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name" example:"orders:update"`
+	Description string `json:"description"`
+}
+
+// RoleCreateRequest запрос на создание роли
+type RoleCreateRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=20" example:"warehouse"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" example:"orders:read_all,orders:update"`
+}
+
+// RoleUpdateRequest запрос на изменение роли. Permissions заменяет набор разрешений целиком
+type RoleUpdateRequest struct {
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions" example:"orders:read_all,orders:update"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// ProductSalePrice запланированная цена продукта на период [starts_at, ends_at)
+type ProductSalePrice struct {
+	ID        int        `json:"id" example:"1"`
+	ProductID int        `json:"product_id" example:"1"`
+	SalePrice float64    `json:"sale_price" example:"899.99"`
+	StartsAt  time.Time  `json:"starts_at" example:"2026-11-27T00:00:00Z"`
+	EndsAt    *time.Time `json:"ends_at,omitempty" example:"2026-12-01T00:00:00Z"` // Нет у бессрочной цены
+	CreatedBy *int       `json:"created_by,omitempty" example:"1"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ProductSalePriceCreateRequest запрос на планирование цены продукта
+type ProductSalePriceCreateRequest struct {
+	SalePrice float64    `json:"sale_price" binding:"required,gt=0" example:"899.99"`
+	StartsAt  time.Time  `json:"starts_at" binding:"required" example:"2026-11-27T00:00:00Z"`
+	EndsAt    *time.Time `json:"ends_at" example:"2026-12-01T00:00:00Z"` // Без окончания - до удаления цены
+}
+
+// SaleCampaign акция: скидка в процентах на все продукты категории в период [starts_at, ends_at)
+type SaleCampaign struct {
+	ID              int       `json:"id" example:"1"`
+	Name            string    `json:"name" example:"Черная пятница"`
+	CategoryID      int       `json:"category_id" example:"1"`
+	DiscountPercent float64   `json:"discount_percent" example:"15"`
+	StartsAt        time.Time `json:"starts_at" example:"2026-11-27T00:00:00Z"`
+	EndsAt          time.Time `json:"ends_at" example:"2026-12-01T00:00:00Z"`
+	IsActive        bool      `json:"is_active" example:"true"`
+	CreatedBy       *int      `json:"created_by,omitempty" example:"1"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// SaleCampaignCreateRequest запрос на создание акции
+type SaleCampaignCreateRequest struct {
+	Name            string    `json:"name" binding:"required,max=100" example:"Черная пятница"`
+	CategoryID      int       `json:"category_id" binding:"required,gt=0" example:"1"`
+	DiscountPercent float64   `json:"discount_percent" binding:"required,gt=0,lt=100" example:"15"`
+	StartsAt        time.Time `json:"starts_at" binding:"required" example:"2026-11-27T00:00:00Z"`
+	EndsAt          time.Time `json:"ends_at" binding:"required" example:"2026-12-01T00:00:00Z"`
+	IsActive        *bool     `json:"is_active" example:"true"` // По умолчанию true
+}
+
+// SaleCampaignUpdateRequest запрос на изменение акции; передаются только изменяемые поля
+type SaleCampaignUpdateRequest struct {
+	Name            *string    `json:"name" binding:"omitempty,min=1,max=100" example:"Черная пятница"`
+	CategoryID      *int       `json:"category_id" binding:"omitempty,gt=0" example:"1"`
+	DiscountPercent *float64   `json:"discount_percent" binding:"omitempty,gt=0,lt=100" example:"20"`
+	StartsAt        *time.Time `json:"starts_at" example:"2026-11-27T00:00:00Z"`
+	EndsAt          *time.Time `json:"ends_at" example:"2026-12-02T00:00:00Z"`
+	IsActive        *bool      `json:"is_active" example:"false"` // false - приостановить акцию
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// SearchSuggestions подсказки поиска по введенному началу запроса
+type SearchSuggestions struct {
+	Query      string               `json:"query" example:"айф"`
+	Products   []ProductSuggestion  `json:"products"`
+	Categories []CategorySuggestion `json:"categories"`
+	Queries    []string             `json:"queries" example:"айфон 15,айфон чехол"`
+}
+
+// ProductSuggestion продукт в подсказках поиска
+type ProductSuggestion struct {
+	ID       int     `json:"id" example:"1"`
+	Name     string  `json:"name" example:"iPhone 15 Pro"`
+	Price    float64 `json:"price" example:"99999.99"`
+	Currency string  `json:"currency" example:"RUB"`
+	ImageURL string  `json:"image_url,omitempty" example:"https://example.com/iphone15.jpg"`
+}
+
+// CategorySuggestion категория в подсказках поиска
+type CategorySuggestion struct {
+	ID   int    `json:"id" example:"5"`
+	Name string `json:"name" example:"Смартфоны"`
+	Slug string `json:"slug" example:"smartphones"`
+}
+
+// SearchQueryStat статистика поискового запроса
+type SearchQueryStat struct {
+	Query           string    `json:"query" example:"айфон 16"`
+	SearchCount     int       `json:"search_count" example:"42"`
+	ZeroResultCount int       `json:"zero_result_count" example:"42"`
+	LastResultCount int       `json:"last_result_count" example:"0"`
+	IsBlocked       bool      `json:"is_blocked" example:"false"`
+	FirstSearchedAt time.Time `json:"first_searched_at"`
+	LastSearchedAt  time.Time `json:"last_searched_at"`
+}
+
+// SearchQueryStatListResponse ответ со списком поисковых запросов
+type SearchQueryStatListResponse struct {
+	Queries []SearchQueryStat `json:"queries"`
+	Total   int               `json:"total" example:"100"`
+	Page    int               `json:"page" example:"1"`
+	Limit   int               `json:"limit" example:"50"`
+}
+
+// SearchQueryBlockRequest запрос на блокировку поискового запроса в подсказках
+type SearchQueryBlockRequest struct {
+	Query string `json:"query" binding:"required,max=200" example:"плохой запрос"`
+}
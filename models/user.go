@@ -6,15 +6,41 @@ import (
 
 // User представляет пользователя в системе
 type User struct {
-	ID        int       `json:"id" db:"id"`
-	Username  string    `json:"username" db:"username" binding:"required"`
-	Email     string    `json:"email" db:"email" binding:"required,email"`
-	Password  string    `json:"-" db:"password" binding:"required,min=6"` // "-" скрывает поле в JSON
-	Role      string    `json:"role" db:"role"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID                 int        `json:"id" db:"id"`
+	Username           string     `json:"username" db:"username" binding:"required"`
+	Email              string     `json:"email" db:"email" binding:"required,email"`
+	Password           string     `json:"-" db:"password" binding:"required,min=6"` // "-" скрывает поле в JSON
+	Role               string     `json:"role" db:"role"`
+	FirstName          string     `json:"first_name" db:"first_name"`
+	LastName           string     `json:"last_name" db:"last_name"`
+	Phone              string     `json:"phone" db:"phone"`
+	PendingEmail       string     `json:"pending_email" db:"pending_email"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	IsActive           bool       `json:"is_active" db:"is_active"`
+	TokenVersion       int        `json:"-" db:"token_version"` // Увеличивается при отзыве всех сессий
+	MustChangePassword bool       `json:"must_change_password" db:"must_change_password"`
+	LastLoginAt        *time.Time `json:"last_login_at" db:"last_login_at"`
+	FailedLoginCount   int        `json:"failed_login_attempts" db:"failed_login_attempts"`
+	LockedUntil        *time.Time `json:"locked_until" db:"locked_until"`
+	TOTPEnabled        bool       `json:"two_factor_enabled" db:"totp_enabled"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// Роли пользователей
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Назначение одноразовых токенов пользователя (user_tokens.purpose)
+const (
+	TokenPurposeEmailChange   = "email_change"
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeEmailVerify   = "email_verify"
+	TokenPurposeLogin2FA      = "login_2fa"
+)
+
 // UserCreateRequest представляет запрос на создание пользователя
 type UserCreateRequest struct {
 	Username string `json:"username" binding:"required"`
@@ -30,12 +56,24 @@ type UserLoginRequest struct {
 
 // UserResponse представляет ответ с пользователем (без пароля)
 type UserResponse struct {
-	ID        int       `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                 int        `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	Role               string     `json:"role"`
+	FirstName          string     `json:"first_name"`
+	LastName           string     `json:"last_name"`
+	Phone              string     `json:"phone"`
+	PendingEmail       string     `json:"pending_email,omitempty"`
+	EmailVerified      bool       `json:"email_verified"`
+	IsActive           bool       `json:"is_active"`
+	MustChangePassword bool       `json:"must_change_password"`
+	LastLoginAt        *time.Time `json:"last_login_at,omitempty"`
+	FailedLoginCount   int        `json:"failed_login_attempts"`
+	LockedUntil        *time.Time `json:"locked_until,omitempty"`
+	TwoFactorEnabled   bool       `json:"two_factor_enabled"`
+	Permissions        []string   `json:"permissions,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
 }
 
 // LoginResponse представляет ответ на успешный вход
@@ -43,3 +81,152 @@ type LoginResponse struct {
 	User  UserResponse `json:"user"`
 	Token string       `json:"token"`
 }
+
+// UserListResponse представляет ответ со списком пользователей
+type UserListResponse struct {
+	Users []UserResponse `json:"users"`
+	Total int            `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+}
+
+// UserRoleUpdateRequest запрос на изменение роли пользователя
+type UserRoleUpdateRequest struct {
+	Role string `json:"role" binding:"required,max=20" example:"warehouse"`
+}
+
+// ProfileUpdateRequest запрос на обновление профиля текущего пользователя
+type ProfileUpdateRequest struct {
+	Username  *string `json:"username" binding:"omitempty,min=3,max=50" example:"ivan"`
+	FirstName *string `json:"first_name" binding:"omitempty,max=50" example:"Иван"`
+	LastName  *string `json:"last_name" binding:"omitempty,max=50" example:"Иванов"`
+	Phone     *string `json:"phone" binding:"omitempty,max=20" example:"+79991234567"`
+}
+
+// ChangePasswordRequest запрос на смену пароля
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// ChangePasswordResponse ответ на смену пароля с новым токеном для текущей сессии
+type ChangePasswordResponse struct {
+	Message string `json:"message"`
+	Token   string `json:"token"`
+}
+
+// ChangeEmailRequest запрос на смену email
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email" example:"new@example.com"`
+	Password string `json:"password" binding:"required"`
+}
+
+// TokenRequest запрос с одноразовым токеном из письма
+type TokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Результаты попыток входа (login_events.outcome)
+const (
+	LoginOutcomeSuccess           = "success"
+	LoginOutcomeInvalidPassword   = "invalid_password"
+	LoginOutcomeUnknownEmail      = "unknown_email"
+	LoginOutcomeLocked            = "locked"
+	LoginOutcomeDisabled          = "disabled"
+	LoginOutcomeTwoFactorRequired = "2fa_required"
+	LoginOutcomeInvalidTwoFactor  = "invalid_2fa_code"
+)
+
+// LoginEvent запись журнала входов
+type LoginEvent struct {
+	ID        int       `json:"id"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Outcome   string    `json:"outcome" example:"success"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginEventListResponse список последних входов пользователя
+type LoginEventListResponse struct {
+	Events []LoginEvent `json:"events"`
+}
+
+// TwoFactorChallengeResponse ответ на вход с включенной 2FA: JWT выдается после проверки кода
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required" example:"true"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in" example:"300"` // Секунды
+}
+
+// TwoFactorLoginRequest второй шаг входа: токен из первого шага и код 2FA
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required" example:"123456"` // Код TOTP или код восстановления
+}
+
+// TwoFactorSetupRequest запрос на настройку 2FA
+type TwoFactorSetupRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// TwoFactorSetupResponse секрет и URI для QR-кода приложения-аутентификатора
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/Products%20API:user@example.com?secret=..."`
+}
+
+// TwoFactorCodeRequest запрос с кодом 2FA
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// TwoFactorDisableRequest запрос на отключение 2FA
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required" example:"123456"` // Код TOTP или код восстановления
+}
+
+// RecoveryCodesResponse коды восстановления, показываются один раз
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// ForgotPasswordRequest запрос на сброс забытого пароля
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// ResetPasswordRequest запрос на установку нового пароля по токену из письма
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// PasswordResetForcedResponse ответ на принудительный сброс пароля
+type PasswordResetForcedResponse struct {
+	Message           string `json:"message"`
+	TemporaryPassword string `json:"temporary_password"`
+}
+
+// ToResponse формирует ответ с пользователем без пароля
+func (u *User) ToResponse() UserResponse {
+	return UserResponse{
+		ID:                 u.ID,
+		Username:           u.Username,
+		Email:              u.Email,
+		Role:               u.Role,
+		FirstName:          u.FirstName,
+		LastName:           u.LastName,
+		Phone:              u.Phone,
+		PendingEmail:       u.PendingEmail,
+		EmailVerified:      u.EmailVerifiedAt != nil,
+		IsActive:           u.IsActive,
+		MustChangePassword: u.MustChangePassword,
+		LastLoginAt:        u.LastLoginAt,
+		FailedLoginCount:   u.FailedLoginCount,
+		LockedUntil:        u.LockedUntil,
+		TwoFactorEnabled:   u.TOTPEnabled,
+		CreatedAt:          u.CreatedAt,
+		UpdatedAt:          u.UpdatedAt,
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ProductVariant вариант продукта (размер, цвет и др.) со своим SKU и остатком
+type ProductVariant struct {
+	ID             int               `json:"id" example:"7"`
+	ProductID      int               `json:"product_id" example:"1"`
+	SKU            string            `json:"sku" example:"TSHIRT-BLACK-M"`
+	Price          float64           `json:"price" example:"19.99"`                    // Цена с учетом PriceOverride
+	PriceOverride  *float64          `json:"price_override,omitempty" example:"19.99"` // Собственная цена варианта
+	EffectivePrice float64           `json:"effective_price" example:"17.99"`          // Цена с учетом действующей акции продукта
+	Currency       string            `json:"currency" example:"RUB"`                   // Валюта цен варианта
+	Stock          int               `json:"stock" example:"12"`
+	Attributes     map[string]string `json:"attributes"`
+	IsActive       bool              `json:"is_active" example:"true"`
+	SortOrder      int               `json:"sort_order" example:"0"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// VariantOption атрибут, по которому различаются варианты, и его значения
+type VariantOption struct {
+	Name   string   `json:"name" example:"size"`
+	Values []string `json:"values" example:"S,M,L"`
+}
+
+// ProductVariantCreateRequest запрос на создание варианта
+type ProductVariantCreateRequest struct {
+	SKU        string            `json:"sku" binding:"required,max=100" example:"TSHIRT-BLACK-M"`
+	Price      *float64          `json:"price" binding:"omitempty,gt=0" example:"19.99"` // Без цены - цена продукта
+	Stock      int               `json:"stock" binding:"gte=0" example:"12"`
+	Attributes map[string]string `json:"attributes" binding:"required,min=1,dive,keys,min=1,max=50,endkeys,required,max=100"`
+	IsActive   *bool             `json:"is_active" example:"true"` // По умолчанию true
+	SortOrder  int               `json:"sort_order" example:"0"`
+}
+
+// ProductVariantUpdateRequest запрос на изменение варианта. Attributes заменяет набор атрибутов целиком
+type ProductVariantUpdateRequest struct {
+	SKU        *string           `json:"sku" binding:"omitempty,min=1,max=100" example:"TSHIRT-BLACK-M"`
+	Price      *float64          `json:"price" binding:"omitempty,gt=0" example:"17.99"`
+	ResetPrice bool              `json:"reset_price"` // Вернуть цену продукта
+	Stock      *int              `json:"stock" binding:"omitempty,gte=0" example:"10"`
+	Attributes map[string]string `json:"attributes" binding:"omitempty,min=1,dive,keys,min=1,max=50,endkeys,required,max=100"`
+	IsActive   *bool             `json:"is_active" example:"true"`
+	SortOrder  *int              `json:"sort_order" example:"1"`
+}
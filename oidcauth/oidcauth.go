@@ -0,0 +1,192 @@
+// Package oidcauth реализует вход через внешних провайдеров OpenID Connect
+// (authorization code + PKCE) и извлечение данных пользователя из ID токена
+package oidcauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"api-go/config"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// discoveryTimeout ограничивает запрос /.well-known/openid-configuration
+const discoveryTimeout = 10 * time.Second
+
+// ErrNonceMismatch возвращается, если nonce в ID токене не совпадает с выданным
+var ErrNonceMismatch = errors.New("nonce ID токена не совпадает")
+
+// Identity данные пользователя, подтвержденные провайдером
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// Registry набор настроенных провайдеров
+type Registry struct {
+	providers map[string]*Provider
+	names     []string
+}
+
+// NewRegistry создает провайдеров из конфигурации. Обнаружение их настроек
+// откладывается до первого входа, чтобы недоступный провайдер не мешал запуску.
+func NewRegistry(cfg *config.Config) *Registry {
+	r := &Registry{providers: make(map[string]*Provider, len(cfg.OIDC.Providers))}
+	for _, p := range cfg.OIDC.Providers {
+		redirectURL := p.RedirectURL
+		if redirectURL == "" {
+			redirectURL = strings.TrimRight(cfg.Server.PublicURL, "/") + "/api/v1/auth/oidc/" + p.Name + "/callback"
+		}
+
+		scopes := p.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{"email", "profile"}
+		}
+
+		r.providers[p.Name] = &Provider{
+			cfg:         p,
+			redirectURL: redirectURL,
+			scopes:      append([]string{oidc.ScopeOpenID}, scopes...),
+		}
+		r.names = append(r.names, p.Name)
+	}
+	return r
+}
+
+// Get возвращает провайдера по имени
+func (r *Registry) Get(name string) (*Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names возвращает имена провайдеров в порядке конфигурации
+func (r *Registry) Names() []string {
+	return r.names
+}
+
+// Provider провайдер OpenID Connect
+type Provider struct {
+	cfg         config.OIDCProvider
+	redirectURL string
+	scopes      []string
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// Name возвращает имя провайдера из конфигурации
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL возвращает адрес страницы входа провайдера.
+// verifier - PKCE code verifier, который нужно сохранить до обмена кода.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	oauthConfig, err := p.oauthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange обменивает код авторизации на токены, проверяет ID токен
+// и возвращает данные пользователя
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	oauthConfig, err := p.oauthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обмена кода авторизации: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("провайдер не вернул ID токен")
+	}
+
+	idToken, err := p.provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("недействительный ID токен: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	var claims identityClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("ошибка чтения ID токена: %w", err)
+	}
+
+	// Некоторые провайдеры передают email только через userinfo
+	if claims.Email == "" {
+		userInfo, err := p.provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err == nil && userInfo.Subject == idToken.Subject {
+			claims.Email = userInfo.Email
+			claims.EmailVerified = flexibleBool(userInfo.EmailVerified)
+		}
+	}
+
+	return &Identity{
+		Subject:           idToken.Subject,
+		Email:             strings.TrimSpace(claims.Email),
+		EmailVerified:     bool(claims.EmailVerified),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+// oauthConfig возвращает настройки OAuth2, при первом вызове загружая настройки провайдера
+func (p *Provider) oauthConfig(ctx context.Context) (*oauth2.Config, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider == nil {
+		ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+		defer cancel()
+
+		provider, err := oidc.NewProvider(ctx, p.cfg.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("провайдер %s недоступен: %w", p.cfg.Name, err)
+		}
+		p.provider = provider
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		Endpoint:     p.provider.Endpoint(),
+		RedirectURL:  p.redirectURL,
+		Scopes:       p.scopes,
+	}, nil
+}
+
+// identityClaims поля ID токена, используемые при входе
+type identityClaims struct {
+	Email             string       `json:"email"`
+	EmailVerified     flexibleBool `json:"email_verified"`
+	Name              string       `json:"name"`
+	PreferredUsername string       `json:"preferred_username"`
+}
+
+// flexibleBool принимает true и "true": часть провайдеров передает email_verified строкой
+type flexibleBool bool
+
+// UnmarshalJSON разбирает логическое значение или его строковую запись
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	*b = flexibleBool(value == "true")
+	return nil
+}
@@ -0,0 +1,299 @@
+// Package oidcmock реализует минимальный провайдер OpenID Connect для разработки и тестов.
+// Пользователь не проверяется: на странице входа вводится любой email,
+// и провайдер выдает для него ID токен. Не используйте в production.
+package oidcmock
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// codeTTL время жизни кода авторизации
+const codeTTL = time.Minute
+
+// keyID идентификатор ключа подписи ID токенов
+const keyID = "mock"
+
+// Server провайдер OpenID Connect в памяти процесса
+type Server struct {
+	issuer string
+	key    *rsa.PrivateKey
+	mux    *http.ServeMux
+
+	mu     sync.Mutex
+	codes  map[string]*authorization
+	tokens map[string]*authorization
+}
+
+// authorization выданный код авторизации и данные пользователя
+type authorization struct {
+	ClientID      string
+	RedirectURI   string
+	Challenge     string
+	Nonce         string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	ExpiresAt     time.Time
+}
+
+// New создает провайдер с адресом issuer (например, http://localhost:9000)
+func New(issuer string) (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		issuer: strings.TrimRight(issuer, "/"),
+		key:    key,
+		mux:    http.NewServeMux(),
+		codes:  make(map[string]*authorization),
+		tokens: make(map[string]*authorization),
+	}
+	s.mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	s.mux.HandleFunc("/jwks", s.jwks)
+	s.mux.HandleFunc("/authorize", s.authorize)
+	s.mux.HandleFunc("/token", s.token)
+	s.mux.HandleFunc("/userinfo", s.userinfo)
+	return s, nil
+}
+
+// ServeHTTP обрабатывает запросы к провайдеру
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// discovery отдает настройки провайдера
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.issuer,
+		"authorization_endpoint":                s.issuer + "/authorize",
+		"token_endpoint":                        s.issuer + "/token",
+		"userinfo_endpoint":                     s.issuer + "/userinfo",
+		"jwks_uri":                              s.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+	})
+}
+
+// jwks отдает открытый ключ подписи ID токенов
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	enc := base64.RawURLEncoding
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   enc.EncodeToString(s.key.N.Bytes()),
+			"e":   enc.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+}
+
+// loginPage форма входа: значения можно передать и в параметрах запроса
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Mock OIDC</title></head>
+<body>
+<h1>Mock OIDC: вход</h1>
+<form method="post">
+{{range $name, $value := .Query}}<input type="hidden" name="{{$name}}" value="{{index $value 0}}">
+{{end}}<p><label>Email <input name="email" type="email" required></label></p>
+<p><label>Имя <input name="name"></label></p>
+<p><label><input name="email_verified" type="checkbox" value="true" checked> Email подтвержден</label></p>
+<p><button type="submit">Войти</button></p>
+</form>
+</body></html>`))
+
+// authorize показывает форму входа (GET) или выдает код авторизации (POST, либо GET с login_hint)
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	email := r.Form.Get("email")
+	if email == "" {
+		email = r.Form.Get("login_hint")
+	}
+	if email == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		loginPage.Execute(w, map[string]url.Values{"Query": r.URL.Query()})
+		return
+	}
+
+	redirectURI := r.Form.Get("redirect_uri")
+	if r.Form.Get("response_type") != "code" || redirectURI == "" || r.Form.Get("client_id") == "" {
+		http.Error(w, "ожидаются response_type=code, client_id и redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("code_challenge_method") != "S256" || r.Form.Get("code_challenge") == "" {
+		http.Error(w, "требуется PKCE (code_challenge_method=S256)", http.StatusBadRequest)
+		return
+	}
+
+	// Без формы (login_hint) email считается подтвержденным, если не указано иное
+	emailVerified := r.Form.Get("email_verified") == "true" ||
+		(r.Method == http.MethodGet && r.Form.Get("email_verified") == "")
+
+	subject := r.Form.Get("sub")
+	if subject == "" {
+		sum := sha256.Sum256([]byte(strings.ToLower(email)))
+		subject = base64.RawURLEncoding.EncodeToString(sum[:12])
+	}
+
+	code := randomString()
+	s.mu.Lock()
+	s.codes[code] = &authorization{
+		ClientID:      r.Form.Get("client_id"),
+		RedirectURI:   redirectURI,
+		Challenge:     r.Form.Get("code_challenge"),
+		Nonce:         r.Form.Get("nonce"),
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: emailVerified,
+		Name:          r.Form.Get("name"),
+		ExpiresAt:     time.Now().Add(codeTTL),
+	}
+	s.mu.Unlock()
+
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "неверный redirect_uri", http.StatusBadRequest)
+		return
+	}
+	query := target.Query()
+	query.Set("code", code)
+	query.Set("state", r.Form.Get("state"))
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// token обменивает код авторизации на ID токен и access токен
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "ожидается POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	clientID := r.Form.Get("client_id")
+	if user, _, ok := r.BasicAuth(); ok {
+		clientID = user
+	}
+
+	s.mu.Lock()
+	auth, ok := s.codes[r.Form.Get("code")]
+	delete(s.codes, r.Form.Get("code"))
+	s.mu.Unlock()
+
+	if !ok || time.Now().After(auth.ExpiresAt) ||
+		auth.ClientID != clientID || auth.RedirectURI != r.Form.Get("redirect_uri") {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	// PKCE: SHA-256 от code_verifier должен совпасть с code_challenge
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.Challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.issuer,
+		"sub":            auth.Subject,
+		"aud":            auth.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          auth.Email,
+		"email_verified": auth.EmailVerified,
+	}
+	if auth.Nonce != "" {
+		claims["nonce"] = auth.Nonce
+	}
+	if auth.Name != "" {
+		claims["name"] = auth.Name
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = keyID
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	accessToken := randomString()
+	s.mu.Lock()
+	s.tokens[accessToken] = auth
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+// userinfo возвращает данные пользователя по access токену
+func (s *Server) userinfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	auth, ok := s.tokens[accessToken]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "неверный access токен", http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":            auth.Subject,
+		"email":          auth.Email,
+		"email_verified": auth.EmailVerified,
+		"name":           auth.Name,
+	})
+}
+
+// tokenError отправляет ошибку token endpoint в формате RFC 6749
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+// writeJSON отправляет JSON ответ
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// randomString возвращает случайную строку для кодов и токенов
+func randomString() string {
+	buf := make([]byte, 24)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,109 @@
+// Package pricing рассчитывает действующие цены продуктов по запланированным ценам и акциям
+// и переключает их в момент начала и окончания акций
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Queryer выполняет запросы; ему соответствуют *sql.DB и *sql.Tx
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// offersQuery предложения, действующие сейчас: запланированные цены и скидки акций на категорию.
+// Из предложений продукта выбирается наименьшая цена, а при равных - то, что закончится позже.
+const offersQuery = `
+	WITH offers AS (
+		SELECT sp.product_id, sp.sale_price AS price, sp.ends_at
+		FROM product_sale_prices sp
+		WHERE sp.starts_at <= CURRENT_TIMESTAMP AND (sp.ends_at IS NULL OR sp.ends_at > CURRENT_TIMESTAMP)
+		UNION ALL
+		SELECT p.id, ROUND(p.price * (100 - sc.discount_percent) / 100, 2), sc.ends_at
+		FROM sale_campaigns sc
+		JOIN products p ON p.category_id = sc.category_id
+		WHERE sc.is_active = true AND sc.starts_at <= CURRENT_TIMESTAMP AND sc.ends_at > CURRENT_TIMESTAMP
+	),
+	best AS (
+		SELECT DISTINCT ON (product_id) product_id, price, ends_at
+		FROM offers
+		ORDER BY product_id, price, ends_at DESC NULLS FIRST
+	)`
+
+// Refresh пересчитывает действующие цены (products.sale_price и sale_ends_at) продуктов productIDs,
+// а без них - всех продуктов. Предложение, не дешевле базовой цены, не применяется.
+// Возвращает ID продуктов, у которых изменилась действующая цена или срок акции.
+func Refresh(ctx context.Context, db Queryer, productIDs ...int) ([]int, error) {
+	if len(productIDs) == 0 {
+		return refresh(ctx, db, "")
+	}
+	return refresh(ctx, db, "WHERE p.id = ANY($1)", pq.Array(productIDs))
+}
+
+// RefreshCategories пересчитывает действующие цены продуктов категорий categoryIDs
+// (после изменения акции на категорию). Возвращает ID продуктов, у которых изменилась цена.
+func RefreshCategories(ctx context.Context, db Queryer, categoryIDs ...int) ([]int, error) {
+	return refresh(ctx, db, "WHERE p.category_id = ANY($1)", pq.Array(categoryIDs))
+}
+
+// refresh пересчитывает действующие цены продуктов, отобранных условием filter
+func refresh(ctx context.Context, db Queryer, filter string, args ...interface{}) ([]int, error) {
+	rows, err := db.QueryContext(ctx, offersQuery+`
+		UPDATE products p
+		SET sale_price = target.price, sale_ends_at = target.ends_at
+		FROM (
+			SELECT p.id, b.price, b.ends_at
+			FROM products p
+			LEFT JOIN best b ON b.product_id = p.id AND b.price < p.price
+			`+filter+`
+		) target
+		WHERE p.id = target.id
+			AND (p.sale_price IS DISTINCT FROM target.price OR p.sale_ends_at IS DISTINCT FROM target.ends_at)
+		RETURNING p.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка пересчета цен: %w", err)
+	}
+	defer rows.Close()
+
+	changed := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		changed = append(changed, id)
+	}
+	return changed, rows.Err()
+}
+
+// NextBoundary возвращает ближайший будущий момент начала или окончания запланированной цены или акции;
+// ok = false, если таких нет
+func NextBoundary(ctx context.Context, db Queryer) (next time.Time, ok bool, err error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT MIN(t) FROM (
+			SELECT starts_at AS t FROM product_sale_prices WHERE starts_at > CURRENT_TIMESTAMP
+			UNION ALL
+			SELECT ends_at FROM product_sale_prices WHERE ends_at > CURRENT_TIMESTAMP
+			UNION ALL
+			SELECT starts_at FROM sale_campaigns WHERE is_active = true AND starts_at > CURRENT_TIMESTAMP
+			UNION ALL
+			SELECT ends_at FROM sale_campaigns WHERE is_active = true AND ends_at > CURRENT_TIMESTAMP
+		) boundaries`)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer rows.Close()
+
+	var boundary sql.NullTime
+	if rows.Next() {
+		if err := rows.Scan(&boundary); err != nil {
+			return time.Time{}, false, err
+		}
+	}
+	return boundary.Time, boundary.Valid, rows.Err()
+}
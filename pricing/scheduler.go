@@ -0,0 +1,77 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"api-go/cache"
+)
+
+const (
+	pollInterval = time.Minute // Как часто проверять новые акции, созданные на других экземплярах API
+	minWait      = time.Second // Защита от частых повторов, если часы API и базы немного расходятся
+	boundaryLag  = 100 * time.Millisecond
+)
+
+// Scheduler пересчитывает действующие цены в моменты начала и окончания акций
+// и сбрасывает кэш продуктов, если цены изменились
+type Scheduler struct {
+	db    *sql.DB
+	cache *cache.ProductCache
+}
+
+// NewScheduler создает планировщик цен; productCache может быть nil
+func NewScheduler(db *sql.DB, productCache *cache.ProductCache) *Scheduler {
+	return &Scheduler{
+		db:    db,
+		cache: productCache,
+	}
+}
+
+// Run пересчитывает цены сразу, затем в ближайшей границе акций, но не реже раза в минуту.
+// Возвращается, когда ctx отменен.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		s.refresh(ctx)
+
+		wait := pollInterval
+		next, ok, err := NextBoundary(ctx, s.db)
+		if err != nil {
+			log.Printf("Ошибка поиска ближайшей акции: %v", err)
+		} else if ok {
+			if until := time.Until(next) + boundaryLag; until < wait {
+				wait = until
+			}
+		}
+		if wait < minWait {
+			wait = minWait
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// refresh пересчитывает цены всех продуктов
+func (s *Scheduler) refresh(ctx context.Context) {
+	changed, err := Refresh(ctx, s.db)
+	if err != nil {
+		log.Printf("Предупреждение: %v", err)
+		return
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	log.Printf("Действующие цены изменились у %d продуктов", len(changed))
+	if s.cache != nil {
+		s.cache.InvalidateAllProductCache(ctx)
+	}
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval период очистки устаревших ключей
+const memorySweepInterval = time.Minute
+
+// MemoryStore хранит счетчики в памяти процесса.
+// Используется, когда Redis недоступен; лимиты не разделяются между экземплярами.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+// memoryEntry время учтенных запросов и окно, в котором они хранятся
+type memoryEntry struct {
+	hits   []time.Time
+	window time.Duration
+}
+
+// NewMemoryStore создает новый MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   make(map[string]*memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow учитывает запрос в скользящем окне
+func (s *MemoryStore) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	k := storeKey(policy, key)
+	entry, ok := s.entries[k]
+	if !ok {
+		entry = &memoryEntry{window: policy.Window}
+		s.entries[k] = entry
+	}
+	entry.prune(now)
+
+	allowed := len(entry.hits) < policy.Limit
+	if allowed {
+		entry.hits = append(entry.hits, now)
+	}
+
+	var reset time.Duration
+	if len(entry.hits) > 0 {
+		reset = entry.hits[0].Add(policy.Window).Sub(now)
+	}
+
+	return newResult(policy, allowed, len(entry.hits), reset), nil
+}
+
+// sweep удаляет ключи без запросов в текущем окне
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for k, entry := range s.entries {
+		entry.prune(now)
+		if len(entry.hits) == 0 {
+			delete(s.entries, k)
+		}
+	}
+}
+
+// prune отбрасывает запросы, вышедшие за окно
+func (e *memoryEntry) prune(now time.Time) {
+	cutoff := now.Add(-e.window)
+	i := 0
+	for i < len(e.hits) && !e.hits[i].After(cutoff) {
+		i++
+	}
+	e.hits = e.hits[i:]
+}
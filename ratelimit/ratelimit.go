@@ -0,0 +1,52 @@
+// Package ratelimit реализует ограничение частоты запросов по скользящему окну
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Policy описывает ограничение: не более Limit запросов за Window
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Result результат проверки лимита
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset время до освобождения следующего слота в окне
+	Reset time.Duration
+}
+
+// Store хранит счетчики запросов
+type Store interface {
+	// Allow учитывает запрос по ключу и сообщает, укладывается ли он в лимит политики
+	Allow(ctx context.Context, policy Policy, key string) (Result, error)
+}
+
+// storeKey формирует ключ счетчика для политики
+func storeKey(policy Policy, key string) string {
+	return "ratelimit:" + policy.Name + ":" + key
+}
+
+// newResult формирует результат по числу учтенных запросов в окне
+func newResult(policy Policy, allowed bool, count int, reset time.Duration) Result {
+	remaining := policy.Limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	if reset < 0 {
+		reset = 0
+	}
+
+	return Result{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: remaining,
+		Reset:     reset,
+	}
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript атомарно учитывает запрос в скользящем окне на sorted set.
+// Возвращает {allowed, count, reset_ms}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = 0
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, count, reset}
+`)
+
+// RedisStore хранит счетчики в Redis, лимиты общие для всех экземпляров API
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore создает новый RedisStore
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{
+		client: client,
+	}
+}
+
+// Allow учитывает запрос в скользящем окне
+func (s *RedisStore) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	member, err := randomMember()
+	if err != nil {
+		return Result{}, err
+	}
+
+	now := time.Now().UnixMilli()
+	values, err := slidingWindowScript.Run(ctx, s.client,
+		[]string{storeKey(policy, key)},
+		now, policy.Window.Milliseconds(), policy.Limit, member,
+	).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("ошибка проверки лимита в Redis: %w", err)
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("неожиданный ответ Redis: %v", values)
+	}
+
+	return newResult(policy, values[0] == 1, int(values[1]), time.Duration(values[2])*time.Millisecond), nil
+}
+
+// randomMember уникальный элемент sorted set для одного запроса
+func randomMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%s", time.Now().UnixNano(), hex.EncodeToString(b)), nil
+}
+
+// FallbackStore использует основное хранилище, а при его ошибках - резервное
+type FallbackStore struct {
+	primary  Store
+	fallback Store
+}
+
+// NewFallbackStore создает новый FallbackStore
+func NewFallbackStore(primary, fallback Store) *FallbackStore {
+	return &FallbackStore{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+// Allow проверяет лимит в основном хранилище, при ошибке - в резервном
+func (s *FallbackStore) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	result, err := s.primary.Allow(ctx, policy, key)
+	if err == nil {
+		return result, nil
+	}
+
+	log.Printf("Предупреждение: %v, используется лимит в памяти", err)
+	return s.fallback.Allow(ctx, policy, key)
+}
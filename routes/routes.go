@@ -1,12 +1,21 @@
 package routes
 
 import (
+	"api-go/apierror"
 	"api-go/cache"
 	"api-go/config"
 	"api-go/database"
 	"api-go/handlers"
+	"api-go/jwtkeys"
+	"api-go/mailer"
 	"api-go/middleware"
+	"api-go/models"
+	"api-go/oidcauth"
+	"api-go/ratelimit"
+	"api-go/storage"
 	"database/sql"
+	"log"
+	"net/http"
 
 	_ "api-go/docs"
 
@@ -16,16 +25,59 @@ import (
 )
 
 // SetupRoutes настраивает все маршруты приложения
-func SetupRoutes(cfg *config.Config, db *sql.DB, redisClient *database.RedisClient) *gin.Engine {
+func SetupRoutes(cfg *config.Config, db *sql.DB, redisClient *database.RedisClient, m mailer.Mailer, store storage.Storage, limiter ratelimit.Store, keys *jwtkeys.KeySet) *gin.Engine {
 	r := gin.Default()
 
+	// Ошибки валидации называют поля так же, как в JSON
+	apierror.RegisterTagNames()
+
+	// IP клиента берется из X-Forwarded-For только за доверенными прокси
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Printf("Предупреждение: некорректный список доверенных прокси: %v", err)
+	}
+
+	profileHandler := handlers.NewProfileHandler(db, cfg, m, keys)
+
+	// Ограничение частоты запросов по политикам из конфигурации
+	limit := func(name string, policy config.RateLimitPolicy, key middleware.RateLimitKeyFunc) gin.HandlerFunc {
+		if !cfg.RateLimit.Enabled {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return middleware.RateLimit(limiter, ratelimit.Policy{
+			Name:   name,
+			Limit:  policy.Requests,
+			Window: policy.WindowDuration(),
+		}, key)
+	}
+	loginByIP := limit("login", cfg.RateLimit.Login, middleware.RateLimitByIP)
+	loginByEmail := limit("login", cfg.RateLimit.Login, middleware.RateLimitByEmail)
+	registerByIP := limit("register", cfg.RateLimit.Register, middleware.RateLimitByIP)
+	resetByIP := limit("password_reset", cfg.RateLimit.PasswordReset, middleware.RateLimitByIP)
+	resetByEmail := limit("password_reset", cfg.RateLimit.PasswordReset, middleware.RateLimitByEmail)
+	publicByIP := limit("public", cfg.RateLimit.Public, middleware.RateLimitByIP)
+	apiByUser := limit("api", cfg.RateLimit.API, middleware.RateLimitByUserID)
+
 	// Middleware
-	r.Use(middleware.CORS())
+	r.Use(middleware.RequestID())
+	r.Use(middleware.CORS(cfg.CORS))
 	r.Use(middleware.Logger())
 
+	// Неизвестные маршруты отвечают в том же формате, что и остальные ошибки
+	r.NoRoute(func(c *gin.Context) {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeRouteNotFound)
+	})
+
 	// Swagger документация
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Загруженные файлы при локальном хранилище
+	if local, ok := store.(*storage.LocalStorage); ok {
+		r.Static(storage.LocalURLPrefix, local.Dir())
+	}
+
+	// Открытые ключи проверки JWT для других сервисов
+	r.GET("/.well-known/jwks.json", publicByIP, handlers.NewJWKSHandler(keys).GetJWKS)
+
 	// Корневой маршрут
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -49,6 +101,9 @@ func SetupRoutes(cfg *config.Config, db *sql.DB, redisClient *database.RedisClie
 				"cart":       "/api/v1/cart/*",
 				"reviews":    "/api/v1/reviews/*",
 				"cache":      "/api/v1/cache/*",
+				"admin":      "/api/v1/admin/*",
+				"me":         "/api/v1/me",
+				"jwks":       "/.well-known/jwks.json",
 			},
 			"swagger": "/swagger/index.html",
 		})
@@ -59,23 +114,69 @@ func SetupRoutes(cfg *config.Config, db *sql.DB, redisClient *database.RedisClie
 		// Аутентификация
 		auth := r.Group("/api/v1/auth")
 		{
-			authHandler := handlers.NewAuthHandler(db, cfg)
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
+			authHandler := handlers.NewAuthHandler(db, cfg, m, keys)
+			auth.POST("/register", registerByIP, authHandler.Register)
+			auth.POST("/login", loginByIP, loginByEmail, authHandler.Login)
+			auth.POST("/login/2fa", loginByIP, authHandler.LoginTwoFactor)
+			auth.POST("/verify-email", resetByIP, authHandler.VerifyEmail)
+			auth.POST("/forgot-password", resetByIP, resetByEmail, authHandler.ForgotPassword)
+			auth.POST("/reset-password", resetByIP, authHandler.ResetPassword)
+			auth.POST("/confirm-email-change", resetByIP, profileHandler.ConfirmEmailChange)
+
+			// Вход через провайдеров OpenID Connect
+			oidcHandler := handlers.NewOIDCHandler(authHandler, oidcauth.NewRegistry(cfg))
+			auth.GET("/oidc/providers", publicByIP, oidcHandler.GetProviders)
+			auth.GET("/oidc/:provider/login", loginByIP, oidcHandler.Login)
+			auth.GET("/oidc/:provider/callback", loginByIP, oidcHandler.Callback)
 		}
 
 		// Продукты (чтение) - публичные
-		productHandler := handlers.NewProductHandler(db, cache.NewProductCache(redisClient))
-		r.GET("/api/v1/products", productHandler.GetProducts)
-		r.GET("/api/v1/products/:id", productHandler.GetProduct)
+		productHandler := handlers.NewProductHandler(db, cache.NewProductCache(redisClient), store, cfg.Storage.MaxUploadBytes())
+		r.GET("/api/v1/products", publicByIP, productHandler.GetProducts)
+		r.GET("/api/v1/products/:id", publicByIP, productHandler.GetProduct)
+
+		// Характеристики продуктов (чтение) - публичные
+		attributeHandler := handlers.NewAttributeHandler(db, cache.NewProductCache(redisClient))
+		r.GET("/api/v1/attributes", publicByIP, attributeHandler.GetAttributes)
+		r.GET("/api/v1/categories/:id/attributes", publicByIP, attributeHandler.GetCategoryAttributes)
+
+		// Подсказки поиска
+		searchHandler := handlers.NewSearchHandler(db)
+		r.GET("/api/v1/search/suggest", publicByIP, searchHandler.Suggest)
+
+		// Курсы валют (чтение) - публичные
+		exchangeRateHandler := handlers.NewExchangeRateHandler(db)
+		r.GET("/api/v1/exchange-rates", publicByIP, exchangeRateHandler.GetExchangeRates)
 	}
 
 	// API v1 - защищенные маршруты (требуют аутентификации)
 	v1 := r.Group("/api/v1")
 
+	// Учетная запись текущего пользователя (доступна и при необходимости сменить пароль)
+	account := v1.Group("/me")
+	account.Use(middleware.AuthMiddleware(keys, db))
+	account.Use(middleware.RejectAPIKey())
+	account.Use(apiByUser)
+	{
+		account.GET("", profileHandler.GetMe)
+		account.PUT("", profileHandler.UpdateMe)
+		account.POST("/change-password", profileHandler.ChangePassword)
+		account.POST("/change-email", profileHandler.ChangeEmail)
+		account.POST("/resend-verification", profileHandler.ResendVerification)
+		account.GET("/sessions", profileHandler.GetSessions)
+
+		twoFactorHandler := handlers.NewTwoFactorHandler(db, cfg)
+		account.POST("/2fa/setup", twoFactorHandler.Setup)
+		account.POST("/2fa/enable", twoFactorHandler.Enable)
+		account.POST("/2fa/disable", twoFactorHandler.Disable)
+		account.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+	}
+
 	// Защищенные маршруты (требуют аутентификации)
 	protected := v1.Group("")
-	protected.Use(middleware.AuthMiddleware(cfg))
+	protected.Use(middleware.AuthMiddleware(keys, db))
+	protected.Use(middleware.PasswordChangeGuard())
+	protected.Use(apiByUser)
 	{
 		// Корзина
 		cartHandler := handlers.NewCartHandler(db)
@@ -90,25 +191,83 @@ func SetupRoutes(cfg *config.Config, db *sql.DB, redisClient *database.RedisClie
 		protected.GET("/orders", orderHandler.GetOrders)
 		protected.GET("/orders/:id", orderHandler.GetOrder)
 		protected.POST("/orders", orderHandler.CreateOrder)
-		protected.PUT("/orders/:id", orderHandler.UpdateOrder)
 		protected.POST("/orders/:id/cancel", orderHandler.CancelOrder)
 
 		// Кэш
 		cacheHandler := handlers.NewCacheHandler(cache.NewProductCache(redisClient))
 		protected.GET("/cache/stats", cacheHandler.GetCacheStats)
-		protected.POST("/cache/invalidate", cacheHandler.InvalidateCache)
 	}
 
-	// Админские маршруты (требуют роль admin)
+	// Служебные маршруты (каждый требует своего разрешения)
 	admin := v1.Group("")
-	admin.Use(middleware.AuthMiddleware(cfg))
-	admin.Use(middleware.AdminMiddleware())
+	admin.Use(middleware.AuthMiddleware(keys, db))
+	admin.Use(middleware.PasswordChangeGuard())
+	admin.Use(middleware.TwoFactorGuard(cfg, db))
+	admin.Use(apiByUser)
 	{
 		// Продукты (создание, обновление, удаление)
-		productHandler := handlers.NewProductHandler(db, cache.NewProductCache(redisClient))
-		admin.POST("/products", productHandler.CreateProduct)
-		admin.PUT("/products/:id", productHandler.UpdateProduct)
-		admin.DELETE("/products/:id", productHandler.DeleteProduct)
+		productHandler := handlers.NewProductHandler(db, cache.NewProductCache(redisClient), store, cfg.Storage.MaxUploadBytes())
+		admin.POST("/products", middleware.RequirePermission(models.PermProductsCreate), productHandler.CreateProduct)
+		admin.PUT("/products/:id", middleware.RequirePermission(models.PermProductsUpdate), productHandler.UpdateProduct)
+		admin.DELETE("/products/:id", middleware.RequirePermission(models.PermProductsDelete), productHandler.DeleteProduct)
+		admin.GET("/admin/products/trash", middleware.RequirePermission(models.PermProductsDelete), productHandler.GetDeletedProducts)
+		admin.POST("/admin/products/:id/restore", middleware.RequirePermission(models.PermProductsDelete), productHandler.RestoreProduct)
+		admin.GET("/admin/products/:id/history", middleware.RequirePermission(models.PermProductsUpdate), productHandler.GetProductHistory)
+		admin.POST("/admin/products/:id/rollback", middleware.RequirePermission(models.PermProductsUpdate), productHandler.RollbackProduct)
+
+		// Запланированные цены продуктов
+		admin.GET("/admin/products/:id/prices", middleware.RequirePermission(models.PermProductsUpdate), productHandler.GetProductSalePrices)
+		admin.POST("/admin/products/:id/prices", middleware.RequirePermission(models.PermProductsUpdate), productHandler.CreateProductSalePrice)
+		admin.DELETE("/admin/products/:id/prices/:price_id", middleware.RequirePermission(models.PermProductsUpdate), productHandler.DeleteProductSalePrice)
+
+		// Варианты продуктов (размер, цвет и т.п.)
+		admin.GET("/products/:id/variants", middleware.RequirePermission(models.PermProductsUpdate), productHandler.GetProductVariants)
+		admin.POST("/products/:id/variants", middleware.RequirePermission(models.PermProductsUpdate), productHandler.CreateProductVariant)
+		admin.PUT("/products/:id/variants/:variant_id", middleware.RequirePermission(models.PermProductsUpdate), productHandler.UpdateProductVariant)
+		admin.DELETE("/products/:id/variants/:variant_id", middleware.RequirePermission(models.PermProductsUpdate), productHandler.DeleteProductVariant)
+		admin.PUT("/products/:id/attributes", middleware.RequirePermission(models.PermProductsUpdate), productHandler.SetProductAttributes)
+
+		// Галерея изображений продуктов
+		admin.POST("/products/:id/images", middleware.RequirePermission(models.PermProductsUpdate), productHandler.UploadProductImage)
+		admin.PUT("/products/:id/images", middleware.RequirePermission(models.PermProductsUpdate), productHandler.ReorderProductImages)
+		admin.PUT("/products/:id/images/:image_id", middleware.RequirePermission(models.PermProductsUpdate), productHandler.UpdateProductImage)
+		admin.DELETE("/products/:id/images/:image_id", middleware.RequirePermission(models.PermProductsUpdate), productHandler.DeleteProductImage)
+
+		// Импорт и выгрузка каталога
+		admin.POST("/admin/products/import", middleware.RequirePermission(models.PermProductsImport), productHandler.ImportProducts)
+		admin.GET("/admin/products/import/:id", middleware.RequirePermission(models.PermProductsImport), productHandler.GetImportJob)
+		admin.GET("/admin/products/export", middleware.RequirePermission(models.PermProductsExport), productHandler.ExportProducts)
+
+		// Характеристики продуктов и их набор в категориях
+		attributeHandler := handlers.NewAttributeHandler(db, cache.NewProductCache(redisClient))
+		canManageAttributes := middleware.RequirePermission(models.PermAttributesManage)
+		admin.POST("/admin/attributes", canManageAttributes, attributeHandler.CreateAttribute)
+		admin.PUT("/admin/attributes/:id", canManageAttributes, attributeHandler.UpdateAttribute)
+		admin.DELETE("/admin/attributes/:id", canManageAttributes, attributeHandler.DeleteAttribute)
+		admin.PUT("/admin/categories/:id/attributes", canManageAttributes, attributeHandler.SetCategoryAttributes)
+
+		// Акции на категории
+		campaignHandler := handlers.NewCampaignHandler(db, cache.NewProductCache(redisClient))
+		canManageCampaigns := middleware.RequirePermission(models.PermCampaignsManage)
+		admin.GET("/admin/campaigns", canManageCampaigns, campaignHandler.GetCampaigns)
+		admin.POST("/admin/campaigns", canManageCampaigns, campaignHandler.CreateCampaign)
+		admin.PUT("/admin/campaigns/:id", canManageCampaigns, campaignHandler.UpdateCampaign)
+		admin.DELETE("/admin/campaigns/:id", canManageCampaigns, campaignHandler.DeleteCampaign)
+
+		// Курсы валют
+		exchangeRateHandler := handlers.NewExchangeRateHandler(db)
+		canManageCurrencies := middleware.RequirePermission(models.PermCurrenciesManage)
+		admin.PUT("/admin/exchange-rates/:currency", canManageCurrencies, exchangeRateHandler.SetExchangeRate)
+		admin.POST("/admin/exchange-rates/import", canManageCurrencies, exchangeRateHandler.ImportExchangeRates)
+		admin.DELETE("/admin/exchange-rates/:currency", canManageCurrencies, exchangeRateHandler.DeleteExchangeRate)
+
+		// Статистика поиска
+		searchHandler := handlers.NewSearchHandler(db)
+		admin.GET("/admin/search/zero-results", middleware.RequirePermission(models.PermSearchRead), searchHandler.GetZeroResultQueries)
+		canManageSearch := middleware.RequirePermission(models.PermSearchManage)
+		admin.GET("/admin/search/blocked-queries", canManageSearch, searchHandler.GetBlockedQueries)
+		admin.POST("/admin/search/blocked-queries", canManageSearch, searchHandler.BlockQuery)
+		admin.DELETE("/admin/search/blocked-queries", canManageSearch, searchHandler.UnblockQuery)
 
 		// Категории
 		// TODO: Добавить CategoryHandler
@@ -128,8 +287,47 @@ func SetupRoutes(cfg *config.Config, db *sql.DB, redisClient *database.RedisClie
 
 		// Заказы (управление)
 		orderHandler := handlers.NewOrderHandler(db)
-		admin.GET("/admin/orders", orderHandler.GetOrders)       // Все заказы для админа
-		admin.PUT("/admin/orders/:id", orderHandler.UpdateOrder) // Обновление статуса заказа
+		admin.GET("/admin/orders", middleware.RequirePermission(models.PermOrdersReadAll), orderHandler.GetAllOrders)
+		admin.PUT("/admin/orders/:id", middleware.RequirePermission(models.PermOrdersUpdate), orderHandler.UpdateOrder)
+
+		// Сброс кэша
+		cacheHandler := handlers.NewCacheHandler(cache.NewProductCache(redisClient))
+		admin.POST("/cache/invalidate", middleware.RequirePermission(models.PermCacheManage), cacheHandler.InvalidateCache)
+
+		// Пользователи (управление)
+		userHandler := handlers.NewUserHandler(db)
+		canReadUsers := middleware.RequirePermission(models.PermUsersRead)
+		canManageUsers := middleware.RequirePermission(models.PermUsersManage)
+		admin.GET("/admin/users", canReadUsers, userHandler.GetUsers)
+		admin.GET("/admin/users/:id", canReadUsers, userHandler.GetUser)
+		admin.PUT("/admin/users/:id/role", canManageUsers, userHandler.UpdateUserRole)
+		admin.POST("/admin/users/:id/deactivate", canManageUsers, userHandler.DeactivateUser)
+		admin.POST("/admin/users/:id/activate", canManageUsers, userHandler.ActivateUser)
+		admin.POST("/admin/users/:id/unlock", canManageUsers, userHandler.UnlockUser)
+		admin.POST("/admin/users/:id/2fa/reset", canManageUsers, userHandler.ResetTwoFactor)
+		admin.POST("/admin/users/:id/force-password-reset", canManageUsers, userHandler.ForcePasswordReset)
+
+		// Роли и разрешения
+		roleHandler := handlers.NewRoleHandler(db)
+		canManageRoles := middleware.RequirePermission(models.PermRolesManage)
+		admin.GET("/admin/roles", canManageRoles, roleHandler.GetRoles)
+		admin.POST("/admin/roles", canManageRoles, roleHandler.CreateRole)
+		admin.PUT("/admin/roles/:name", canManageRoles, roleHandler.UpdateRole)
+		admin.DELETE("/admin/roles/:name", canManageRoles, roleHandler.DeleteRole)
+		admin.GET("/admin/permissions", canManageRoles, roleHandler.GetPermissions)
+
+		// API ключи интеграций; выпустить новый ключ по API ключу нельзя
+		apiKeyHandler := handlers.NewAPIKeyHandler(db)
+		canManageAPIKeys := middleware.RequirePermission(models.PermAPIKeysManage)
+		admin.GET("/admin/api-keys", canManageAPIKeys, apiKeyHandler.GetAPIKeys)
+		admin.POST("/admin/api-keys", canManageAPIKeys, middleware.RejectAPIKey(), apiKeyHandler.CreateAPIKey)
+		admin.DELETE("/admin/api-keys/:id", canManageAPIKeys, apiKeyHandler.RevokeAPIKey)
+
+		// Журнал действий администраторов
+		auditHandler := handlers.NewAuditHandler(db)
+		canReadAudit := middleware.RequirePermission(models.PermAuditRead)
+		admin.GET("/admin/audit-log", canReadAudit, auditHandler.GetAuditLog)
+		admin.GET("/admin/audit-log/export", canReadAudit, auditHandler.ExportAuditLog)
 	}
 
 	return r
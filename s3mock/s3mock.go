@@ -0,0 +1,159 @@
+// Package s3mock реализует минимальное S3-совместимое хранилище для разработки и тестов.
+// Поддерживаются только адреса вида /{bucket}/{key} (path style) и методы PUT, GET, HEAD, DELETE.
+// Запись и удаление требуют подписанного запроса с указанным ключом доступа, чтение открыто всем.
+// Подпись не пересчитывается: проверяются ключ доступа и хеш содержимого. Не используйте в production.
+package s3mock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxObjectSize ограничивает размер загружаемого объекта
+const maxObjectSize = 64 << 20
+
+// Server хранилище объектов в каталоге на диске
+type Server struct {
+	dir       string
+	accessKey string
+}
+
+// New создает хранилище в каталоге dir, принимающее запись с ключом доступа accessKey
+func New(dir, accessKey string) *Server {
+	return &Server{
+		dir:       dir,
+		accessKey: accessKey,
+	}
+}
+
+// s3Error тело ответа с ошибкой в формате S3
+type s3Error struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+// ServeHTTP обрабатывает запросы к хранилищу
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, ok := s.objectPath(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "InvalidURI", "ожидается адрес вида /{bucket}/{key}")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.get(w, r, path)
+	case http.MethodPut:
+		if !s.authorize(w, r) {
+			return
+		}
+		s.put(w, r, path)
+	case http.MethodDelete:
+		if !s.authorize(w, r) {
+			return
+		}
+		s.delete(w, path)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "метод не поддерживается")
+	}
+}
+
+// objectPath возвращает путь к файлу объекта; сегменты . и .. не допускаются
+func (s *Server) objectPath(urlPath string) (string, bool) {
+	segments := strings.Split(strings.TrimPrefix(urlPath, "/"), "/")
+	if len(segments) < 2 {
+		return "", false
+	}
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", false
+		}
+	}
+	return filepath.Join(append([]string{s.dir}, segments...)...), true
+}
+
+// authorize проверяет ключ доступа в заголовке Authorization
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/") {
+		writeError(w, http.StatusForbidden, "InvalidAccessKeyId", "неизвестный ключ доступа")
+		return false
+	}
+	return true
+}
+
+// get отдает объект
+func (s *Server) get(w http.ResponseWriter, r *http.Request, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "NoSuchKey", "объект не найден")
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		writeError(w, http.StatusNotFound, "NoSuchKey", "объект не найден")
+		return
+	}
+
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	http.ServeContent(w, r, "", info.ModTime(), file)
+}
+
+// put сохраняет объект, сверяя его с хешем из X-Amz-Content-Sha256
+func (s *Server) put(w http.ResponseWriter, r *http.Request, path string) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxObjectSize+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	if len(data) > maxObjectSize {
+		writeError(w, http.StatusBadRequest, "EntityTooLarge", "объект слишком большой")
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		writeError(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch", "хеш содержимого не совпадает")
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+// delete удаляет объект; как и S3, отвечает 204 и для отсутствующего объекта
+func (s *Server) delete(w http.ResponseWriter, path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError отправляет ошибку в формате S3
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(s3Error{Code: code, Message: message})
+}
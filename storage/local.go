@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LocalURLPrefix путь, по которому сервер раздает файлы LocalStorage
+const LocalURLPrefix = "/media"
+
+// LocalStorage хранит файлы в каталоге на диске сервера
+type LocalStorage struct {
+	dir       string
+	publicURL string
+}
+
+// NewLocalStorage создает новый LocalStorage
+func NewLocalStorage(dir, publicURL string) *LocalStorage {
+	return &LocalStorage{
+		dir:       dir,
+		publicURL: publicURL,
+	}
+}
+
+// Dir возвращает каталог с файлами
+func (s *LocalStorage) Dir() string {
+	return s.dir
+}
+
+// Put сохраняет файл. Запись идет во временный файл, который затем переименовывается,
+// чтобы по адресу файла никогда не отдавалось частично записанное содержимое.
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("ошибка создания каталога файлов: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("ошибка сохранения файла: %w", err)
+	}
+	return nil
+}
+
+// Delete удаляет файл; отсутствующий файл не считается ошибкой
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("ошибка удаления файла: %w", err)
+	}
+	return nil
+}
+
+// URL возвращает публичный адрес файла
+func (s *LocalStorage) URL(key string) string {
+	return s.publicURL + "/" + key
+}
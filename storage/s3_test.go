@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api-go/config"
+	"api-go/s3mock"
+)
+
+const (
+	testBucket    = "products"
+	testAccessKey = "test-access-key"
+)
+
+// newTestS3 запускает s3mock и возвращает S3Storage с ключом доступа accessKey
+func newTestS3(t *testing.T, accessKey string) *S3Storage {
+	t.Helper()
+
+	server := httptest.NewServer(s3mock.New(t.TempDir(), testAccessKey))
+	t.Cleanup(server.Close)
+
+	return NewS3Storage(config.StorageConfig{
+		Driver:      config.StorageDriverS3,
+		S3Endpoint:  server.URL,
+		S3Region:    "us-east-1",
+		S3Bucket:    testBucket,
+		S3AccessKey: accessKey,
+		S3SecretKey: "test-secret-key",
+		S3PathStyle: true,
+	}, "")
+}
+
+// fetch читает объект по публичному адресу
+func fetch(t *testing.T, url string) (status int, body []byte, contentType string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	return resp.StatusCode, body, resp.Header.Get("Content-Type")
+}
+
+func TestS3StorageRoundTrip(t *testing.T) {
+	s := newTestS3(t, testAccessKey)
+
+	tests := []struct {
+		name        string
+		key         string
+		data        []byte
+		contentType string
+	}{
+		{name: "jpeg", key: "products/42/abc.jpg", data: []byte("\xff\xd8\xff\xe0 jpeg data"), contentType: "image/jpeg"},
+		{name: "png миниатюра", key: "products/42/abc_small.png", data: []byte("\x89PNG\r\n\x1a\n png data"), contentType: "image/png"},
+		{name: "пустой объект", key: "products/7/empty.gif", data: []byte{}, contentType: "image/gif"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			if err := s.Put(ctx, tt.key, tt.data, tt.contentType); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+
+			status, body, contentType := fetch(t, s.URL(tt.key))
+			if status != http.StatusOK {
+				t.Fatalf("GET после Put: статус %d", status)
+			}
+			if !bytes.Equal(body, tt.data) {
+				t.Errorf("GET после Put: тело %q, want %q", body, tt.data)
+			}
+			if contentType != tt.contentType {
+				t.Errorf("GET после Put: Content-Type %q, want %q", contentType, tt.contentType)
+			}
+
+			if err := s.Delete(ctx, tt.key); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if status, _, _ := fetch(t, s.URL(tt.key)); status != http.StatusNotFound {
+				t.Errorf("GET после Delete: статус %d, want %d", status, http.StatusNotFound)
+			}
+
+			// Повторное удаление отсутствующего объекта не считается ошибкой
+			if err := s.Delete(ctx, tt.key); err != nil {
+				t.Errorf("повторный Delete() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestS3StorageErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		accessKey string
+		key       string
+		wantErr   error
+		wantAny   bool
+	}{
+		{name: "пустой ключ", accessKey: testAccessKey, key: "", wantErr: ErrInvalidKey},
+		{name: "выход из каталога", accessKey: testAccessKey, key: "products/../secret.jpg", wantErr: ErrInvalidKey},
+		{name: "пустой сегмент", accessKey: testAccessKey, key: "products//abc.jpg", wantErr: ErrInvalidKey},
+		{name: "недопустимые символы", accessKey: testAccessKey, key: "products/42/a b.jpg", wantErr: ErrInvalidKey},
+		{name: "чужой ключ доступа", accessKey: "wrong-key", key: "products/42/abc.jpg", wantAny: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestS3(t, tt.accessKey)
+
+			err := s.Put(context.Background(), tt.key, []byte("data"), "image/jpeg")
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("Put() error = %v, want %v", err, tt.wantErr)
+			case tt.wantAny && err == nil:
+				t.Error("Put() error = nil, want ошибку хранилища")
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package storage отвечает за хранение загружаемых файлов (изображений продуктов)
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"api-go/config"
+)
+
+// ErrInvalidKey возвращается для ключа, который нельзя безопасно использовать как путь
+var ErrInvalidKey = errors.New("недопустимый ключ файла")
+
+// Storage сохраняет файлы по ключу вида products/42/abc.jpg и выдает их публичные адреса
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// New создает Storage по настройкам конфигурации
+func New(cfg *config.Config) (Storage, error) {
+	publicURL := strings.TrimRight(cfg.Storage.PublicURL, "/")
+
+	switch cfg.Storage.Driver {
+	case config.StorageDriverLocal, "":
+		if publicURL == "" {
+			publicURL = strings.TrimRight(cfg.Server.PublicURL, "/") + LocalURLPrefix
+		}
+		return NewLocalStorage(cfg.Storage.Dir, publicURL), nil
+	case config.StorageDriverS3:
+		return NewS3Storage(cfg.Storage, publicURL), nil
+	default:
+		return nil, fmt.Errorf("неизвестное хранилище файлов: %s", cfg.Storage.Driver)
+	}
+}
+
+// validateKey проверяет, что ключ состоит из непустых сегментов без . и ..
+// и содержит только латинские буквы, цифры и символы - _ .
+func validateKey(key string) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+		for _, r := range segment {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+				return ErrInvalidKey
+			}
+		}
+	}
+	return nil
+}
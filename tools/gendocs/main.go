@@ -0,0 +1,19 @@
+// Команда gendocs генерирует Swagger документацию при сборке: go run ./tools/gendocs.
+// В отличие от api-go gen-docs, она не зависит от пакета docs и работает, когда его еще нет.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"api-go/docsgen"
+)
+
+func main() {
+	output := flag.String("o", "./docs", "каталог для сгенерированных файлов")
+	flag.Parse()
+
+	if err := docsgen.Generate(*output); err != nil {
+		log.Fatalf("Ошибка генерации Swagger документации: %v", err)
+	}
+}
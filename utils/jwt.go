@@ -4,24 +4,28 @@ import (
 	"errors"
 	"time"
 
+	"api-go/jwtkeys"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
 // Claims представляет данные, хранящиеся в JWT токене
 type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID       int    `json:"user_id"`
+	Username     string `json:"username"`
+	Role         string `json:"role"`
+	TokenVersion int    `json:"tv"` // Должна совпадать с users.token_version, иначе токен отозван
 	jwt.RegisteredClaims
 }
 
-// GenerateToken создает новый JWT токен для пользователя
-func GenerateToken(userID int, username, role, secret string, expiryHours int) (string, error) {
+// GenerateToken создает новый JWT токен для пользователя, подписанный текущим ключом набора
+func GenerateToken(keys *jwtkeys.KeySet, userID int, username, role string, tokenVersion int, expiryHours int) (string, error) {
 	// Создаем claims для токена
 	claims := Claims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:       userID,
+		Username:     username,
+		Role:         role,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expiryHours) * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -31,11 +35,14 @@ func GenerateToken(userID int, username, role, secret string, expiryHours int) (
 		},
 	}
 
-	// Создаем токен с claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	key := keys.Signing()
+
+	// Создаем токен с claims; kid указывает, каким ключом его проверять
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
 
-	// Подписываем токен секретным ключом
-	tokenString, err := token.SignedString([]byte(secret))
+	// Подписываем токен
+	tokenString, err := token.SignedString(key.SigningKey())
 	if err != nil {
 		return "", err
 	}
@@ -44,14 +51,20 @@ func GenerateToken(userID int, username, role, secret string, expiryHours int) (
 }
 
 // ValidateToken проверяет и декодирует JWT токен
-func ValidateToken(tokenString, secret string) (*Claims, error) {
+func ValidateToken(tokenString string, keys *jwtkeys.KeySet) (*Claims, error) {
 	// Парсим токен
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Проверяем алгоритм подписи
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys.Lookup(kid)
+		if !ok {
+			return nil, errors.New("неизвестный ключ подписи")
+		}
+
+		// Алгоритм токена должен совпадать с алгоритмом ключа
+		if token.Method.Alg() != key.Algorithm {
 			return nil, errors.New("неожиданный метод подписи")
 		}
-		return []byte(secret), nil
+		return key.VerificationKey(), nil
 	})
 
 	if err != nil {
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// apiKeyPrefix начало каждого API ключа, по нему ключ легко найти в логах и секретах
+const apiKeyPrefix = "ak_"
+
+// GenerateSecureToken создает случайный одноразовый токен.
+// Возвращает сам токен (для передачи пользователю) и его хеш (для хранения в БД).
+func GenerateSecureToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken возвращает SHA-256 хеш токена в шестнадцатеричном виде
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey создает API ключ вида ak_<идентификатор>_<секрет>.
+// Возвращает ключ (показывается один раз), его открытый префикс ak_<идентификатор>
+// для поиска в БД и хеш ключа для хранения.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	secret, _, err := GenerateSecureToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	prefix = apiKeyPrefix + hex.EncodeToString(id)
+	key = prefix + "_" + secret
+	return key, prefix, HashToken(key), nil
+}
+
+// APIKeyPrefix возвращает открытый префикс API ключа или false, если формат неверен
+func APIKeyPrefix(key string) (string, bool) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(key[len(apiKeyPrefix):], "_")
+	if !ok || len(prefix) != 8 || secret == "" {
+		return "", false
+	}
+	return apiKeyPrefix + prefix, true
+}
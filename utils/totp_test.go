@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret секрет из тестовых векторов RFC 6238 (SHA1) в base32
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestValidateTOTP(t *testing.T) {
+	// Коды из приложения B RFC 6238 для SHA1: 8 цифр в RFC, здесь последние 6
+	tests := []struct {
+		name       string
+		secret     string
+		code       string
+		unix       int64
+		wantOK     bool
+		wantPeriod int64
+	}{
+		{name: "rfc t=59", secret: rfc6238Secret, code: "287082", unix: 59, wantOK: true, wantPeriod: 1},
+		{name: "rfc t=1111111109", secret: rfc6238Secret, code: "081804", unix: 1111111109, wantOK: true, wantPeriod: 37037036},
+		{name: "rfc t=1111111111", secret: rfc6238Secret, code: "050471", unix: 1111111111, wantOK: true, wantPeriod: 37037037},
+		{name: "rfc t=1234567890", secret: rfc6238Secret, code: "005924", unix: 1234567890, wantOK: true, wantPeriod: 41152263},
+		{name: "rfc t=2000000000", secret: rfc6238Secret, code: "279037", unix: 2000000000, wantOK: true, wantPeriod: 66666666},
+		{name: "rfc t=20000000000", secret: rfc6238Secret, code: "353130", unix: 20000000000, wantOK: true, wantPeriod: 666666666},
+		{name: "секрет в нижнем регистре", secret: "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code: "287082", unix: 59, wantOK: true, wantPeriod: 1},
+		{name: "пробелы вокруг кода", secret: rfc6238Secret, code: " 287082 ", unix: 59, wantOK: true, wantPeriod: 1},
+		{name: "код предыдущего периода", secret: rfc6238Secret, code: "287082", unix: 89, wantOK: true, wantPeriod: 1},
+		{name: "код следующего периода", secret: rfc6238Secret, code: "287082", unix: 29, wantOK: true, wantPeriod: 1},
+		{name: "код вне допустимого расхождения", secret: rfc6238Secret, code: "287082", unix: 120, wantOK: false},
+		{name: "неверный код", secret: rfc6238Secret, code: "287083", unix: 59, wantOK: false},
+		{name: "8 цифр не принимаются", secret: rfc6238Secret, code: "94287082", unix: 59, wantOK: false},
+		{name: "короткий код", secret: rfc6238Secret, code: "28708", unix: 59, wantOK: false},
+		{name: "секрет не в base32", secret: "не-base32", code: "287082", unix: 59, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period, ok := ValidateTOTP(tt.secret, tt.code, time.Unix(tt.unix, 0))
+			if ok != tt.wantOK {
+				t.Fatalf("ValidateTOTP() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && period != tt.wantPeriod {
+				t.Errorf("ValidateTOTP() period = %d, want %d", period, tt.wantPeriod)
+			}
+		})
+	}
+}